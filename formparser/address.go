@@ -0,0 +1,136 @@
+package formparser
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Address holds the parts of one address group keyed by part name, e.g.
+// "street", "city", "postal_code", "country".
+type Address map[string]string
+
+// NormalizedAddress is an AddressNormalizer's result. Address parts are
+// written back to their fields; Extra (e.g. coordinates) is attached to
+// ParseResult.Extras under the group name.
+type NormalizedAddress struct {
+	Address Address
+	Extra   any
+}
+
+// AddressNormalizer normalizes and optionally geocodes address groups,
+// typically through a postal or geo service. Returning FieldErrors reports
+// them as field errors; any other error fails the request with 500.
+type AddressNormalizer interface {
+	NormalizeAddress(ctx context.Context, group string, address Address) (NormalizedAddress, error)
+}
+
+// AddressNormalizerFunc adapts an ordinary function to the AddressNormalizer interface.
+type AddressNormalizerFunc func(ctx context.Context, group string, address Address) (NormalizedAddress, error)
+
+// NormalizeAddress calls f(ctx, group, address).
+func (f AddressNormalizerFunc) NormalizeAddress(ctx context.Context, group string, address Address) (NormalizedAddress, error) {
+	return f(ctx, group, address)
+}
+
+// addressPart is a top-level string field tagged `address:"<group>,<part>"`.
+type addressPart struct {
+	index int
+	group string
+	part  string
+}
+
+// addressPartsCache maps reflect.Type to []addressPart.
+var addressPartsCache sync.Map
+
+// addressParts returns the address-tagged string fields of dst.
+func addressParts(dst interface{}) []addressPart {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := addressPartsCache.Load(t); ok {
+		return cached.([]addressPart)
+	}
+
+	var parts []addressPart
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		group, part, ok := strings.Cut(f.Tag.Get("address"), ",")
+		if !ok || group == "" || part == "" || !f.IsExported() || f.Type.Kind() != reflect.String {
+			continue
+		}
+		parts = append(parts, addressPart{index: i, group: group, part: part})
+	}
+
+	addressPartsCache.Store(t, parts)
+	return parts
+}
+
+// normalizeAddresses passes each address group of dst with at least one
+// non-empty part to the AddressNormalizer, in group name order.
+func (cfg *Config) normalizeAddresses(ctx context.Context, dst interface{}, res *ParseResult) (FieldErrors, error) {
+	if cfg.AddressNormalizer == nil {
+		return nil, nil
+	}
+	parts := addressParts(dst)
+	if len(parts) == 0 {
+		return nil, nil
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	groups := make(map[string]Address)
+	for _, p := range parts {
+		if value := v.Field(p.index).String(); value != "" {
+			if groups[p.group] == nil {
+				groups[p.group] = make(Address)
+			}
+			groups[p.group][p.part] = value
+		}
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fieldErrors := make(FieldErrors)
+	for _, group := range names {
+		var normalized NormalizedAddress
+		err := cfg.callHook(ctx, "AddressNormalizer", func(ctx context.Context) (err error) {
+			normalized, err = cfg.AddressNormalizer.NormalizeAddress(ctx, group, groups[group])
+			return err
+		})
+		if errors.Is(err, errHookSkipped) {
+			continue // left as submitted
+		}
+		var errs FieldErrors
+		if errors.As(err, &errs) {
+			for field, msg := range errs {
+				fieldErrors[field] = msg
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range parts {
+			if value, ok := normalized.Address[p.part]; ok && p.group == group {
+				v.Field(p.index).SetString(value)
+			}
+		}
+		if normalized.Extra != nil {
+			if res.Extras == nil {
+				res.Extras = make(map[string]any)
+			}
+			res.Extras[group] = normalized.Extra
+		}
+	}
+	return fieldErrors, nil
+}
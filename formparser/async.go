@@ -0,0 +1,183 @@
+package formparser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ErrParseQueueFull is returned by ParseAsync when no worker slot frees up in time.
+var ErrParseQueueFull = errors.New("parse queue full")
+
+// ErrClosed is returned by ParseAsync once Close has been called.
+var ErrClosed = errors.New("formparser: config closed")
+
+// ParseJob is the pending result of ParseAsync.
+type ParseJob struct {
+	done   chan struct{}
+	result *ParseResult
+	err    error
+}
+
+// Done is closed once the parse has finished.
+func (j *ParseJob) Done() <-chan struct{} {
+	return j.done
+}
+
+// Wait blocks until the parse has finished and returns its outcome.
+func (j *ParseJob) Wait() (*ParseResult, error) {
+	<-j.done
+	return j.result, j.err
+}
+
+// asyncTask is a parse queued for the worker pool.
+type asyncTask struct {
+	w   http.ResponseWriter
+	r   *http.Request
+	dst interface{}
+	job *ParseJob
+}
+
+// asyncPool runs queued parses on a fixed set of worker goroutines.
+type asyncPool struct {
+	tasks chan asyncTask
+	quit  chan struct{} // closed by Close to turn away waiting senders
+	done  chan struct{} // closed once every worker has exited
+
+	mu        sync.RWMutex // held for reading while sending on tasks
+	closed    bool
+	closeOnce sync.Once
+	workers   sync.WaitGroup
+}
+
+// ParseAsync queues the parse on the Config's worker pool (AsyncWorkers
+// goroutines, AsyncQueueSize pending jobs) and returns immediately. The
+// caller must keep w and r alive, typically by waiting on the job, until it
+// is done. Results come back through the job; Config.Files and
+// Config.Result are not touched, so concurrent jobs never share state.
+//
+// When the queue is full ParseAsync waits up to AsyncQueueTimeout for a slot,
+// then responds 503 with Retry-After: AsyncRetryAfter and returns
+// ErrParseQueueFull.
+func (cfg *Config) ParseAsync(w http.ResponseWriter, r *http.Request, dst interface{}) (*ParseJob, error) {
+	pool := cfg.getAsyncPool()
+	job := &ParseJob{done: make(chan struct{})}
+	task := asyncTask{w: w, r: r, dst: dst, job: job}
+
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	if pool.closed {
+		return nil, cfg.rejectClosed(w)
+	}
+
+	select {
+	case pool.tasks <- task:
+		return job, nil
+	default:
+	}
+
+	if cfg.AsyncQueueTimeout > 0 {
+		timer := time.NewTimer(cfg.AsyncQueueTimeout)
+		defer timer.Stop()
+		select {
+		case pool.tasks <- task:
+			return job, nil
+		case <-timer.C:
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		case <-pool.quit:
+			return nil, cfg.rejectClosed(w)
+		}
+	}
+
+	cfg.stats.recordFailure(ErrParseQueueFull, http.StatusServiceUnavailable)
+	RateLimit{RetryAfter: cfg.asyncRetryAfter()}.SetHeaders(w.Header())
+	http.Error(w, "Server busy", http.StatusServiceUnavailable)
+	return nil, ErrParseQueueFull
+}
+
+// rejectClosed answers a ParseAsync call made after Close.
+func (cfg *Config) rejectClosed(w http.ResponseWriter) error {
+	cfg.stats.recordFailure(ErrClosed, http.StatusServiceUnavailable)
+	http.Error(w, "Server shutting down", http.StatusServiceUnavailable)
+	return ErrClosed
+}
+
+// getAsyncPool starts the worker pool on first use.
+func (cfg *Config) getAsyncPool() *asyncPool {
+	cfg.asyncOnce.Do(func() {
+		workers := cfg.AsyncWorkers
+		if workers <= 0 {
+			workers = runtime.GOMAXPROCS(0)
+		}
+		queue := cfg.AsyncQueueSize
+		if queue <= 0 {
+			queue = workers
+		}
+		pool := &asyncPool{
+			tasks: make(chan asyncTask, queue),
+			quit:  make(chan struct{}),
+			done:  make(chan struct{}),
+		}
+		pool.workers.Add(workers)
+		for i := 0; i < workers; i++ {
+			go cfg.asyncWorker(pool)
+		}
+		go func() {
+			pool.workers.Wait()
+			close(pool.done)
+		}()
+		cfg.asyncPool = pool
+	})
+	return cfg.asyncPool
+}
+
+// asyncWorker runs queued parses until the task channel is closed.
+func (cfg *Config) asyncWorker(pool *asyncPool) {
+	defer pool.workers.Done()
+	for task := range pool.tasks {
+		cfg.runAsyncTask(task)
+	}
+}
+
+// Close stops ParseAsync from accepting jobs and waits for the jobs already
+// queued or running to finish, so servers can drain the pool during
+// graceful shutdown:
+//
+//	srv.Shutdown(ctx)
+//	cfg.Close(ctx)
+//
+// It returns ctx's error if the deadline passes first; the remaining jobs
+// still run to completion in the background. Synchronous parses are not
+// affected. Close may be called more than once.
+func (cfg *Config) Close(ctx context.Context) error {
+	pool := cfg.getAsyncPool()
+	pool.closeOnce.Do(func() {
+		close(pool.quit)
+		pool.mu.Lock()
+		pool.closed = true
+		close(pool.tasks)
+		pool.mu.Unlock()
+	})
+	select {
+	case <-pool.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runAsyncTask parses one task, turning a panic into the job's error.
+func (cfg *Config) runAsyncTask(task asyncTask) {
+	defer close(task.job.done)
+	defer func() {
+		if p := recover(); p != nil {
+			task.job.err = fmt.Errorf("formparser: parse panicked: %v", p)
+		}
+	}()
+	task.job.result, task.job.err = cfg.parse(task.w, task.r, task.dst)
+}
@@ -0,0 +1,107 @@
+package formparser
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// binaryField is a []byte or [N]byte field carrying an `encoding` tag.
+type binaryField struct {
+	index    int
+	key      string // form key
+	name     string // field error key
+	encoding string
+}
+
+// binaryFieldsCache maps reflect.Type to []binaryField.
+var binaryFieldsCache sync.Map
+
+// binaryFields returns the top-level byte slice and array fields of dst
+// tagged with `encoding:"base64"`, `encoding:"base64url"` or `encoding:"hex"`.
+func binaryFields(dst interface{}) []binaryField {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := binaryFieldsCache.Load(t); ok {
+		return cached.([]binaryField)
+	}
+
+	var fields []binaryField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		encoding := f.Tag.Get("encoding")
+		if encoding == "" || !f.IsExported() {
+			continue
+		}
+		if (f.Type.Kind() != reflect.Slice && f.Type.Kind() != reflect.Array) || f.Type.Elem().Kind() != reflect.Uint8 {
+			continue
+		}
+		fields = append(fields, binaryField{index: i, key: formKey(f), name: strings.ToLower(f.Name), encoding: encoding})
+	}
+
+	binaryFieldsCache.Store(t, fields)
+	return fields
+}
+
+// decodeBinaryValues decodes `encoding`-tagged values straight into dst and
+// removes them from values, since the form decoder would treat each byte as
+// a separate number. Byte arrays must decode to exactly their length; the
+// length of byte slices can be checked with validate:"len=32" and a
+// unit:"bytes" tag. JSON bodies are left to encoding/json, which decodes
+// []byte from standard base64.
+func decodeBinaryValues(dst interface{}, values url.Values, fieldErrors FieldErrors) {
+	fields := binaryFields(dst)
+	if len(fields) == 0 {
+		return
+	}
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, f := range fields {
+		raw, ok := values[f.key]
+		if !ok {
+			continue
+		}
+		delete(values, f.key)
+		if len(raw) == 0 || raw[0] == "" {
+			continue
+		}
+
+		data, err := decodeBinary(f.encoding, strings.TrimSpace(raw[0]))
+		if err != nil {
+			fieldErrors[f.name] = fmt.Sprintf("%s must be %s-encoded", f.name, f.encoding)
+			continue
+		}
+		field := v.Field(f.index)
+		if field.Kind() == reflect.Array {
+			if len(data) != field.Len() {
+				fieldErrors[f.name] = fmt.Sprintf("%s must be %d bytes", f.name, field.Len())
+				continue
+			}
+			reflect.Copy(field, reflect.ValueOf(data))
+			continue
+		}
+		field.SetBytes(data)
+	}
+}
+
+// decodeBinary decodes s in the named encoding. Base64 padding is optional.
+func decodeBinary(encoding, s string) ([]byte, error) {
+	switch encoding {
+	case "base64":
+		return base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+	case "base64url":
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	case "hex":
+		return hex.DecodeString(s)
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", encoding)
+	}
+}
@@ -0,0 +1,144 @@
+package formparser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for calls to a hook whose circuit breaker is
+// open and fails closed.
+var ErrCircuitOpen = errors.New("formparser: circuit open")
+
+// errHookSkipped tells a hook call site that its breaker failed open, so it
+// should carry on as if the hook were not configured.
+var errHookSkipped = errors.New("formparser: hook skipped")
+
+// BreakerPolicy decides what a failing or open hook means for the request.
+type BreakerPolicy int
+
+const (
+	// FailClosed rejects the request, as an unguarded hook error would.
+	FailClosed BreakerPolicy = iota
+	// FailOpen carries on without the hook: files stay in memory only,
+	// passwords count as not breached, addresses and uploads are left as
+	// submitted, and so on.
+	FailOpen
+)
+
+// String returns the policy name used in EffectiveConfig.
+func (p BreakerPolicy) String() string {
+	if p == FailOpen {
+		return "fail_open"
+	}
+	return "fail_closed"
+}
+
+// MarshalText encodes the policy by name.
+func (p BreakerPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// CircuitBreaker guards calls to one external hook. After Threshold
+// consecutive failures the circuit opens and calls are not attempted for
+// Cooldown; the next call is then let through as a trial, closing the
+// circuit on success or reopening it on failure. Hooks returning FieldErrors
+// count as successes. Use one CircuitBreaker per hook.
+type CircuitBreaker struct {
+	Threshold int           `json:"threshold"` // Optional: consecutive failures that open the circuit (default 5)
+	Cooldown  time.Duration `json:"cooldown"`  // Optional: how long the circuit stays open (default 30s)
+	Timeout   time.Duration `json:"timeout"`   // Optional: per-call deadline passed to the hook's ctx (0 = none)
+	Policy    BreakerPolicy `json:"policy"`    // Optional: what failures and open circuits mean (default FailClosed)
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool // a trial call is in flight after the cooldown
+}
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// Open reports whether the circuit has tripped and not yet recovered.
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.openUntil.IsZero()
+}
+
+// allow reports whether a call may go ahead at now.
+func (b *CircuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(b.openUntil) || b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// record updates the breaker with the outcome of a call.
+func (b *CircuitBreaker) record(failed bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasTrial := b.trial
+	b.trial = false
+	if !failed {
+		b.failures, b.openUntil = 0, time.Time{}
+		return
+	}
+	b.failures++
+	threshold := b.Threshold
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+	if wasTrial || b.failures >= threshold {
+		cooldown := b.Cooldown
+		if cooldown <= 0 {
+			cooldown = defaultBreakerCooldown
+		}
+		b.openUntil = now.Add(cooldown)
+	}
+}
+
+// callHook runs call through the CircuitBreaker registered for hook in
+// Breakers, or directly when there is none. When the breaker fails open,
+// failures and open circuits come back as errHookSkipped.
+func (cfg *Config) callHook(ctx context.Context, hook string, call func(ctx context.Context) error) error {
+	b := cfg.Breakers[hook]
+	if b == nil {
+		return call(ctx)
+	}
+	if !b.allow(cfg.now()) {
+		return b.fallback(fmt.Errorf("%s: %w", hook, ErrCircuitOpen))
+	}
+	if b.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.Timeout)
+		defer cancel()
+	}
+
+	err := call(ctx)
+	var fieldErrs FieldErrors
+	failed := err != nil && !errors.As(err, &fieldErrs)
+	b.record(failed, cfg.now())
+	if failed {
+		return b.fallback(err)
+	}
+	return err
+}
+
+// fallback applies the breaker's policy to a failed or rejected call.
+func (b *CircuitBreaker) fallback(err error) error {
+	if b.Policy == FailOpen {
+		return errHookSkipped
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package formparser
+
+import (
+	"io"
+	"sync"
+)
+
+// defaultCopyBufferSize matches the chunk size io.Copy uses internally.
+const defaultCopyBufferSize = 32 << 10
+
+// copyBufferPools holds one *sync.Pool per buffer size so configs with
+// different CopyBufferSize values never hand each other mismatched buffers.
+var copyBufferPools sync.Map
+
+// copyBufferSize returns the configured copy chunk size or the default.
+func (cfg *Config) copyBufferSize() int {
+	if cfg.CopyBufferSize > 0 {
+		return cfg.CopyBufferSize
+	}
+	return defaultCopyBufferSize
+}
+
+// getCopyBuffer borrows a buffer of the configured size from the pool.
+func (cfg *Config) getCopyBuffer() *[]byte {
+	size := cfg.copyBufferSize()
+	p, _ := copyBufferPools.LoadOrStore(size, &sync.Pool{
+		New: func() any {
+			buf := make([]byte, size)
+			return &buf
+		},
+	})
+	return p.(*sync.Pool).Get().(*[]byte)
+}
+
+// putCopyBuffer returns a buffer obtained from getCopyBuffer to its pool.
+func putCopyBuffer(buf *[]byte) {
+	if p, ok := copyBufferPools.Load(len(*buf)); ok {
+		p.(*sync.Pool).Put(buf)
+	}
+}
+
+// copyLimited copies at most limit bytes from src to dst using a pooled
+// buffer, returning the number of bytes written.
+func (cfg *Config) copyLimited(dst io.Writer, src io.Reader, limit int64) (int64, error) {
+	buf := cfg.getCopyBuffer()
+	defer putCopyBuffer(buf)
+	// Hide any ReaderFrom on dst (e.g. *bytes.Buffer) so io.CopyBuffer
+	// actually uses the pooled buffer instead of its own chunking.
+	return io.CopyBuffer(struct{ io.Writer }{dst}, io.LimitReader(src, limit), *buf)
+}
@@ -0,0 +1,79 @@
+package formparser
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// isStructSlice reports whether dst points to a slice of structs or of
+// pointers to structs, the destination of a top-level JSON array body.
+func isStructSlice(dst interface{}) bool {
+	t := reflect.TypeOf(dst)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Slice {
+		return false
+	}
+	elem := t.Elem().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct
+}
+
+// validateItems validates each element of the slice dst points to like a
+// JSON body of its own. Field errors are keyed by the element's index,
+// e.g. "items[3].email", and all elements' errors are answered together;
+// null elements are rejected as "items[i]".
+func (cfg *Config) validateItems(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	items := reflect.ValueOf(dst).Elem()
+	fieldErrors := make(FieldErrors)
+	for i := 0; i < items.Len(); i++ {
+		prefix := fmt.Sprintf("items[%d]", i)
+		item := items.Index(i)
+		if item.Kind() != reflect.Ptr {
+			item = item.Addr()
+		} else if item.IsNil() {
+			fieldErrors[prefix] = "Item is required"
+			continue
+		}
+
+		itemRes := &ParseResult{}
+		errs, err := cfg.validateFields(r, item.Interface(), itemRes, nil)
+		var f *validationFailure
+		if errors.As(err, &f) {
+			cfg.httpError(w, f.msg, f.status, f.err)
+			return f.err
+		}
+		for field, msg := range errs {
+			fieldErrors[prefix+"."+field] = msg
+		}
+		res.addItemErrors(prefix, itemRes)
+	}
+	if len(fieldErrors) > 0 {
+		cfg.respondFieldErrors(w, r, res, fieldErrors)
+		return fieldErrors
+	}
+	return nil
+}
+
+// addItemErrors copies the error codes, rule details and warnings recorded
+// for one element of a JSON array body onto res, under prefix.
+func (res *ParseResult) addItemErrors(prefix string, item *ParseResult) {
+	for field, msg := range item.Warnings {
+		res.addWarning(prefix+"."+field, msg)
+	}
+	for field, codes := range item.ErrorCodes {
+		if res.ErrorCodes == nil {
+			res.ErrorCodes = make(map[string][]string)
+		}
+		res.ErrorCodes[prefix+"."+field] = codes
+	}
+	for field, d := range item.errorDetails {
+		if res.errorDetails == nil {
+			res.errorDetails = make(map[string]errorDetail)
+		}
+		d.path = prefix + "." + d.path
+		res.errorDetails[prefix+"."+field] = d
+	}
+}
@@ -0,0 +1,220 @@
+package formparser
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// cardField is a string field tagged `cc:"pan"`, with the indexes of the
+// sibling fields that receive its brand, BIN and last four digits (-1 when
+// not requested).
+type cardField struct {
+	index int
+	name  string // field error key
+	brand int
+	bin   int
+	last4 int
+}
+
+// cardFieldsCache maps reflect.Type to []cardField.
+var cardFieldsCache sync.Map
+
+// cardFields returns the top-level string fields of dst tagged
+// `cc:"pan"`, optionally followed by sibling string fields to fill in:
+//
+//	Card  string `form:"card" cc:"pan,brand=CardBrand,bin=CardBIN,last4=CardLast4"`
+func cardFields(dst interface{}) []cardField {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := cardFieldsCache.Load(t); ok {
+		return cached.([]cardField)
+	}
+
+	var fields []cardField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		opts := strings.Split(f.Tag.Get("cc"), ",")
+		if opts[0] != "pan" || !f.IsExported() || f.Type.Kind() != reflect.String {
+			continue
+		}
+		field := cardField{index: i, name: strings.ToLower(f.Name), brand: -1, bin: -1, last4: -1}
+		for _, opt := range opts[1:] {
+			key, sibling, _ := strings.Cut(opt, "=")
+			sf, ok := t.FieldByName(sibling)
+			if !ok || len(sf.Index) != 1 || !sf.IsExported() || sf.Type.Kind() != reflect.String {
+				continue
+			}
+			switch key {
+			case "brand":
+				field.brand = sf.Index[0]
+			case "bin":
+				field.bin = sf.Index[0]
+			case "last4":
+				field.last4 = sf.Index[0]
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	cardFieldsCache.Store(t, fields)
+	return fields
+}
+
+// checkCards normalizes `cc`-tagged fields of dst to bare digits, checks
+// their length and Luhn digit, and fills in the requested brand, BIN and
+// last-four fields. Empty fields are left to `required`. Error messages
+// never contain the submitted number.
+func (cfg *Config) checkCards(r *http.Request, dst interface{}) FieldErrors {
+	fields := cardFields(dst)
+	if len(fields) == 0 {
+		return nil
+	}
+	fieldErrors := make(FieldErrors)
+	lang := requestLang(r)
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, f := range fields {
+		field := v.Field(f.index)
+		if strings.TrimSpace(field.String()) == "" {
+			continue
+		}
+		pan, ok := normalizePAN(field.String())
+		if !ok {
+			if msg, exists := cfg.lookupMessage(f.name, "cc", lang); exists {
+				fieldErrors[f.name] = msg
+			} else {
+				fieldErrors[f.name] = fmt.Sprintf("%s is not a valid card number", f.name)
+			}
+			continue
+		}
+		field.SetString(pan)
+		if f.brand >= 0 {
+			v.Field(f.brand).SetString(CardBrand(pan))
+		}
+		if f.bin >= 0 {
+			v.Field(f.bin).SetString(pan[:6])
+		}
+		if f.last4 >= 0 {
+			v.Field(f.last4).SetString(pan[len(pan)-4:])
+		}
+	}
+	return fieldErrors
+}
+
+// normalizePAN strips spaces and dashes from s and reports whether the
+// result is a 12 to 19 digit number with a valid Luhn check digit.
+func normalizePAN(s string) (string, bool) {
+	pan := strings.Map(func(c rune) rune {
+		if c == ' ' || c == '-' {
+			return -1
+		}
+		return c
+	}, s)
+	if len(pan) < 12 || len(pan) > 19 {
+		return "", false
+	}
+	for _, c := range pan {
+		if c < '0' || c > '9' {
+			return "", false
+		}
+	}
+	return pan, luhnValid(pan)
+}
+
+// luhnValid reports whether the last digit of digits is its Luhn check digit.
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// cardBrands maps issuer number ranges to brand names. Ranges are checked in
+// order, so narrower ranges come before the wider ones they overlap.
+var cardBrands = []struct {
+	brand   string
+	low     int // inclusive, compared against the PAN's first digits
+	high    int
+	digits  int // prefix length low and high are written in
+	lengths []int
+}{
+	{"amex", 34, 34, 2, []int{15}},
+	{"amex", 37, 37, 2, []int{15}},
+	{"diners", 300, 305, 3, []int{14, 16, 19}},
+	{"diners", 36, 36, 2, []int{14, 16, 19}},
+	{"diners", 38, 39, 2, []int{16, 19}},
+	{"jcb", 3528, 3589, 4, []int{16, 17, 18, 19}},
+	{"discover", 6011, 6011, 4, []int{16, 17, 18, 19}},
+	{"discover", 644, 649, 3, []int{16, 17, 18, 19}},
+	{"discover", 65, 65, 2, []int{16, 17, 18, 19}},
+	{"unionpay", 62, 62, 2, []int{16, 17, 18, 19}},
+	{"mastercard", 2221, 2720, 4, []int{16}},
+	{"mastercard", 51, 55, 2, []int{16}},
+	{"maestro", 50, 50, 2, []int{12, 13, 14, 15, 16, 17, 18, 19}},
+	{"maestro", 56, 69, 2, []int{12, 13, 14, 15, 16, 17, 18, 19}},
+	{"visa", 4, 4, 1, []int{13, 16, 19}},
+}
+
+// CardBrand returns the brand of a card number given as bare digits:
+// "visa", "mastercard", "amex", "discover", "jcb", "diners", "unionpay" or
+// "maestro", or "" when the number matches none of them.
+func CardBrand(pan string) string {
+	for _, b := range cardBrands {
+		if len(pan) < b.digits {
+			continue
+		}
+		prefix := 0
+		for _, c := range pan[:b.digits] {
+			prefix = prefix*10 + int(c-'0')
+		}
+		if prefix < b.low || prefix > b.high {
+			continue
+		}
+		for _, n := range b.lengths {
+			if len(pan) == n {
+				return b.brand
+			}
+		}
+	}
+	return ""
+}
+
+// MaskPAN replaces all but the last four digits of a card number with '*'.
+func MaskPAN(pan string) string {
+	if len(pan) <= 4 {
+		return strings.Repeat("*", len(pan))
+	}
+	return strings.Repeat("*", len(pan)-4) + pan[len(pan)-4:]
+}
+
+// panPattern matches digit runs that may be grouped with spaces or dashes.
+var panPattern = regexp.MustCompile(`\d(?:[ -]?\d)*`)
+
+// redactPANs masks every card number in s, so that values echoed back by
+// custom messages, hooks or log lines never reveal a full PAN.
+func redactPANs(s string) string {
+	return panPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if pan, ok := normalizePAN(match); ok {
+			return MaskPAN(pan)
+		}
+		return match
+	})
+}
@@ -0,0 +1,65 @@
+package formparser
+
+import (
+	"fmt"
+	"net/url"
+
+	"golang.org/x/text/encoding"
+)
+
+// charsetField is the field browsers fill in with the form's charset when
+// a form has a hidden input of that name.
+const charsetField = "_charset_"
+
+// charsetDecoder returns a decoder from charset to UTF-8, or nil when no
+// conversion is needed.
+func charsetDecoder(charset string) (*encoding.Decoder, error) {
+	if charset == "" {
+		return nil, nil
+	}
+	enc, err := textEncoding(charset)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %q: %w", charset, err)
+	}
+	if enc == encoding.Nop {
+		return nil, nil
+	}
+	return enc.NewDecoder(), nil
+}
+
+// formCharset returns the charset of a form body: the charset parameter of
+// its Content-Type, else the "_charset_" field legacy pages submit.
+func formCharset(contentType string, values url.Values) string {
+	if charset := declaredCharset(contentType); charset != "" {
+		return charset
+	}
+	return values.Get(charsetField)
+}
+
+// transcodeValues converts the keys and values of values accepted by keep
+// to UTF-8 with dec, in place, so forms submitted from pages served as
+// ISO-8859-1, Windows-1252 or Shift_JIS decode correctly.
+func transcodeValues(values url.Values, dec *encoding.Decoder, keep func(key string) bool) error {
+	decoded := make(url.Values, len(values))
+	for key, list := range values {
+		if keep != nil && !keep(key) {
+			decoded[key] = append(decoded[key], list...)
+			continue
+		}
+		name, err := dec.String(key)
+		if err != nil {
+			return err
+		}
+		for _, v := range list {
+			if v, err = dec.String(v); err != nil {
+				return err
+			}
+			decoded[name] = append(decoded[name], v)
+		}
+	}
+	clear(values)
+	for key, list := range decoded {
+		values[key] = list
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package formparser
+
+import (
+	"crypto/rand"
+	"io"
+	"time"
+)
+
+// now returns the current time from Config.Clock, defaulting to time.Now.
+func (cfg *Config) now() time.Time {
+	if cfg.Clock != nil {
+		return cfg.Clock()
+	}
+	return time.Now()
+}
+
+// random returns Config.Random, defaulting to crypto/rand.
+func (cfg *Config) random() io.Reader {
+	if cfg.Random != nil {
+		return cfg.Random
+	}
+	return rand.Reader
+}
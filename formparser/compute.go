@@ -0,0 +1,33 @@
+package formparser
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Computer fills derived fields of a decoded destination, e.g. Slug from
+// Title. Returning FieldErrors reports them like validation failures; any
+// other error aborts the parse with 500.
+type Computer func(dst interface{}) error
+
+// RegisterComputer registers compute for *T destinations. It runs after
+// decoding and before validation, so rules can apply to computed values.
+func RegisterComputer[T any](cfg *Config, compute func(dst *T) error) {
+	cfg.computers.Store(reflect.TypeFor[*T](), Computer(func(dst interface{}) error {
+		return compute(dst.(*T))
+	}))
+}
+
+// compute runs the Computer registered for dst's type, if any.
+func (cfg *Config) compute(dst interface{}) (FieldErrors, error) {
+	c, ok := cfg.computers.Load(reflect.TypeOf(dst))
+	if !ok {
+		return nil, nil
+	}
+	err := c.(Computer)(dst)
+	var fieldErrors FieldErrors
+	if errors.As(err, &fieldErrors) {
+		return fieldErrors, nil
+	}
+	return nil, err
+}
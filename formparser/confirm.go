@@ -0,0 +1,59 @@
+package formparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// confirmFields returns index pairs (confirmation, target) for top-level
+// fields of t tagged `confirm:"<field>"`. The target is matched by its Go
+// name (case-insensitively), form key or json name.
+func confirmFields(t reflect.Type) [][2]int {
+	var pairs [][2]int
+	for i := 0; i < t.NumField(); i++ {
+		target, ok := t.Field(i).Tag.Lookup("confirm")
+		if !ok {
+			continue
+		}
+		for j := 0; j < t.NumField(); j++ {
+			f := t.Field(j)
+			if j != i && (strings.EqualFold(f.Name, target) || formKey(f) == target || tagName(f.Tag.Get("json")) == target) {
+				pairs = append(pairs, [2]int{i, j})
+				break
+			}
+		}
+	}
+	return pairs
+}
+
+// checkConfirmations reports confirmation fields that differ from their target.
+func checkConfirmations(dst interface{}) FieldErrors {
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	fieldErrors := make(FieldErrors)
+	for _, pair := range confirmFields(v.Type()) {
+		if !reflect.DeepEqual(v.Field(pair[0]).Interface(), v.Field(pair[1]).Interface()) {
+			name := strings.ToLower(v.Type().Field(pair[0]).Name)
+			target := strings.ToLower(v.Type().Field(pair[1]).Name)
+			fieldErrors[name] = fmt.Sprintf("%s does not match %s", name, target)
+		}
+	}
+	return fieldErrors
+}
+
+// stripConfirmations zeroes confirmation fields once validation has passed so
+// they are never propagated past the parser.
+func stripConfirmations(dst interface{}) {
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for _, pair := range confirmFields(v.Type()) {
+		if f := v.Field(pair[0]); f.CanSet() {
+			f.SetZero()
+		}
+	}
+}
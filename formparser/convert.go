@@ -0,0 +1,49 @@
+package formparser
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// Converter transcodes an uploaded file into another format, e.g. HEIC to JPEG.
+// The returned file replaces the upload in Config.Files; Filename, ContentType
+// and Content must be set, Hash is computed when left empty.
+type Converter interface {
+	Convert(ctx context.Context, file *UploadedFile) (*UploadedFile, error)
+}
+
+// ConverterFunc adapts an ordinary function to the Converter interface.
+type ConverterFunc func(ctx context.Context, file *UploadedFile) (*UploadedFile, error)
+
+// Convert calls f(ctx, file).
+func (f ConverterFunc) Convert(ctx context.Context, file *UploadedFile) (*UploadedFile, error) {
+	return f(ctx, file)
+}
+
+// convertFile runs the Converter registered for the file's MIME type. The
+// converted file keeps a reference to the upload in its Original field.
+func (cfg *Config) convertFile(ctx context.Context, field string, file *UploadedFile) (*UploadedFile, string) {
+	converter, ok := cfg.Converters[file.ContentType]
+	if !ok {
+		return file, ""
+	}
+
+	var converted *UploadedFile
+	err := cfg.callHook(ctx, "Converters", func(ctx context.Context) (err error) {
+		converted, err = converter.Convert(ctx, file)
+		return err
+	})
+	if errors.Is(err, errHookSkipped) {
+		return file, ""
+	}
+	if err != nil || converted == nil {
+		return file, fmt.Sprintf("%s could not be converted", field)
+	}
+	if converted.Hash == "" {
+		converted.Hash = fmt.Sprintf("%x", sha256.Sum256(converted.Content))
+	}
+	converted.Original = file
+	return converted, ""
+}
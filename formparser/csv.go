@@ -0,0 +1,131 @@
+package formparser
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// parseCSV handles text/csv payload decoded into a pointer to a slice of
+// structs. The header row maps columns to fields by their `csv` tag, else
+// case-insensitively by name; unknown columns are ignored. Each row is
+// decoded like a url-encoded form, so the same type conversions apply, and
+// validated on its own. dst receives every row; rejected rows are reported
+// together as RecordErrors keyed by line number.
+func (cfg *Config) parseCSV(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	slice := reflect.ValueOf(dst)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
+		return errors.New("formparser: CSV body requires a pointer to a slice destination")
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
+		return errors.New("formparser: CSV body requires a slice of structs destination")
+	}
+
+	reader := csv.NewReader(r.Body)
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+		return nil
+	}
+	if err != nil {
+		http.Error(w, "Invalid CSV body", http.StatusBadRequest)
+		return err
+	}
+	keys := csvColumnKeys(structType, header)
+
+	rows := reflect.MakeSlice(slice.Type(), 0, 0)
+	var recordErrors RecordErrors
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			msg := "Invalid CSV row"
+			if errors.Is(err, csv.ErrFieldCount) {
+				msg = "CSV row has the wrong number of fields"
+			}
+			recordErrors = append(recordErrors, RecordError{Line: parseErr.StartLine, Message: msg})
+			continue
+		}
+		if err != nil {
+			return readFailed(w, r.Body, err, "Error reading body", http.StatusBadRequest)
+		}
+		line, _ := reader.FieldPos(0)
+
+		values := make(url.Values)
+		for i, key := range keys {
+			if key != "" {
+				values.Add(key, record[i])
+			}
+		}
+		row := reflect.New(structType)
+		fieldErrors := cfg.prepareValues(r, row.Interface(), values)
+		rowRes := &ParseResult{}
+		if err := cfg.decodeValues(row.Interface(), values, rowRes, fieldErrors); err != nil {
+			http.Error(w, "CSV header nested too deeply", http.StatusBadRequest)
+			return err
+		}
+		fieldErrors, err = cfg.validateFields(r, row.Interface(), rowRes, fieldErrors)
+		var f *validationFailure
+		if errors.As(err, &f) {
+			cfg.httpError(w, f.msg, f.status, f.err)
+			return f.err
+		}
+		if len(fieldErrors) > 0 {
+			recordErrors = append(recordErrors, RecordError{Line: line, Fields: fieldErrors})
+		}
+
+		if elemType.Kind() == reflect.Ptr {
+			rows = reflect.Append(rows, row)
+		} else {
+			rows = reflect.Append(rows, row.Elem())
+		}
+	}
+	slice.Set(rows)
+	res.ValidationSkipped = cfg.DecodeOnly
+
+	if len(recordErrors) > 0 {
+		cfg.respondRecordErrors(w, r, recordErrors)
+		return recordErrors
+	}
+	return nil
+}
+
+// csvColumnKeys returns, for each header column, the form key of the field
+// it fills, or "" when no field matches.
+func csvColumnKeys(t reflect.Type, header []string) []string {
+	keys := make([]string, len(header))
+	for i, column := range header {
+		column = strings.TrimSpace(strings.TrimPrefix(column, "\uFEFF"))
+		for j := 0; j < t.NumField(); j++ {
+			f := t.Field(j)
+			if !f.IsExported() {
+				continue
+			}
+			name := tagName(f.Tag.Get("csv"))
+			if name == "-" {
+				continue
+			}
+			if name == column || (name == "" && strings.EqualFold(f.Name, column)) {
+				keys[i] = formKey(f)
+				break
+			}
+		}
+	}
+	return keys
+}
@@ -0,0 +1,241 @@
+package formparser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// uploadedFileType is the type of *UploadedFile fields.
+var uploadedFileType = reflect.TypeOf((*UploadedFile)(nil))
+
+// dataURIFieldsCache maps reflect.Type to the []mergeField of its
+// *UploadedFile fields.
+var dataURIFieldsCache sync.Map
+
+// dataURIFields returns the top-level *UploadedFile fields of dst.
+func dataURIFields(dst interface{}) []mergeField {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := dataURIFieldsCache.Load(t); ok {
+		return cached.([]mergeField)
+	}
+
+	var fields []mergeField
+	for _, f := range structMergeFields(t) {
+		if t.Field(f.index).Type == uploadedFileType && t.Field(f.index).Tag.Get("json") != "-" {
+			fields = append(fields, f)
+		}
+	}
+
+	dataURIFieldsCache.Store(t, fields)
+	return fields
+}
+
+// dataURIFile is a file decoded from a JSON body, for the field at index.
+type dataURIFile struct {
+	index int
+	file  *UploadedFile
+}
+
+// extractDataURIs removes the members of a JSON body that belong to dst's
+// *UploadedFile fields and decodes them as RFC 2397 data URIs
+// ("data:image/png;base64,..."), so single-page apps can upload small files
+// inline. The files must have an allowed content type and fit in
+// MaxFileSize like multipart uploads, and their fields' `file` tags apply
+// as they do to multipart files. They then pass the same admission, checks
+// and storage as multipart files, with upload tokens read from the body's
+// UploadTokenFields members; they are stored in res.Files under the
+// field's json name and returned for setFiles once the rest of the body is
+// decoded. Members that are not data URIs become field errors. Bodies
+// for structs without such fields are passed through unread.
+func (cfg *Config) extractDataURIs(w http.ResponseWriter, r *http.Request, dst interface{}, body io.Reader, res *ParseResult) (io.Reader, []dataURIFile, FieldErrors, error) {
+	fields := dataURIFields(dst)
+	if len(fields) == 0 {
+		return body, nil, nil, nil
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, nil, cfg.jsonFailed(w, err)
+	}
+	var members map[string]json.RawMessage
+	if json.Unmarshal(data, &members) != nil {
+		return bytes.NewReader(data), nil, nil, nil // let the decoder report it
+	}
+
+	var files []dataURIFile
+	fieldErrors := make(FieldErrors)
+	rules := fileRules(dst)
+	for _, f := range fields {
+		name, raw, ok := jsonMember(members, f.json)
+		if !ok {
+			continue
+		}
+		delete(members, name)
+		if isJSONNull(raw) {
+			continue
+		}
+		var uri string
+		if json.Unmarshal(raw, &uri) != nil {
+			fieldErrors[f.key] = fmt.Sprintf("%s must be a data URI", f.key)
+			continue
+		}
+		rule := rules[f.form]
+		file, err := cfg.decodeDataURI(uri, rule)
+		var ruleErr *fileRuleError
+		if errors.As(err, &ruleErr) {
+			fieldErrors[rule.name] = cfg.fileRuleMessage(r, rule, ruleErr.constraint)
+			continue
+		}
+		var e *responseError
+		if errors.As(err, &e) {
+			http.Error(w, e.msg, e.status)
+			return nil, nil, nil, err
+		}
+		if err != nil {
+			fieldErrors[f.key] = fmt.Sprintf("%s must be a data URI", f.key)
+			continue
+		}
+		header := FileHeader{Field: f.json, Filename: file.Filename, ContentType: file.ContentType}
+		grant, msg := cfg.admitFile(header, jsonString(members, cfg.UploadTokenFields[f.json]))
+		if msg == "" && grant != nil && file.Size != grant.Size {
+			msg = fmt.Sprintf("%s does not match its upload token", f.json)
+		}
+		if msg != "" {
+			fieldErrors[f.key] = msg
+			continue
+		}
+		file, msg, err = cfg.processFile(w, r, res, f.json, file)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if msg != "" {
+			fieldErrors[f.key] = msg
+			continue
+		}
+		res.addFile(f.json, file)
+		files = append(files, dataURIFile{index: f.index, file: file})
+	}
+
+	data, err = json.Marshal(members)
+	if err != nil {
+		return nil, nil, nil, cfg.jsonFailed(w, err)
+	}
+	return bytes.NewReader(data), files, fieldErrors, nil
+}
+
+// jsonString returns the string member name of members, or "" when it is
+// missing or not a string.
+func jsonString(members map[string]json.RawMessage, name string) string {
+	var s string
+	if _, raw, ok := jsonMember(members, name); ok && name != "" {
+		_ = json.Unmarshal(raw, &s)
+	}
+	return s
+}
+
+// setFiles stores files decoded by extractDataURIs in their fields of dst.
+func setFiles(dst interface{}, files []dataURIFile) {
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, f := range files {
+		v.Field(f.index).Set(reflect.ValueOf(f.file))
+	}
+}
+
+// jsonMember finds the member of an object named name, preferring an exact
+// match over a case-insensitive one as encoding/json does.
+func jsonMember(members map[string]json.RawMessage, name string) (string, json.RawMessage, bool) {
+	if raw, ok := members[name]; ok {
+		return name, raw, true
+	}
+	for key, raw := range members {
+		if strings.EqualFold(key, name) {
+			return key, raw, true
+		}
+	}
+	return "", nil, false
+}
+
+// decodeDataURI decodes a data URI into a file for a field with the given
+// `file` rule (the zero rule for untagged fields). A "name" or "filename"
+// parameter, as some clients add, becomes the file name. Files breaking the
+// rule are reported as a *fileRuleError; other disallowed types and
+// oversized files as a *responseError.
+func (cfg *Config) decodeDataURI(uri string, rule fileRule) (*UploadedFile, error) {
+	rest, ok := strings.CutPrefix(uri, "data:")
+	if !ok {
+		return nil, errors.New("not a data URI")
+	}
+	header, payload, ok := strings.Cut(rest, ",")
+	if !ok {
+		return nil, errors.New("data URI has no data")
+	}
+	params := strings.Split(header, ";")
+	isBase64 := params[len(params)-1] == "base64"
+	if isBase64 {
+		params = params[:len(params)-1]
+	}
+	mediaType, mediaParams := parseMediaType(strings.Join(params, ";"))
+	if mediaType == "" {
+		mediaType = "text/plain" // RFC 2397 default
+	}
+	if rule.accept != nil {
+		if !matchesMIMEType(rule.accept, mediaType) {
+			return nil, &fileRuleError{"accept"}
+		}
+	} else if !cfg.isAllowedContentType(mediaType) {
+		return nil, &responseError{http.StatusBadRequest, "Unsupported file type", fmt.Errorf("unsupported file type: %s", mediaType)}
+	}
+
+	// Decode up to the larger of the two limits, so a file over a lower tag
+	// limit is a field error as in multipart forms rather than a 413.
+	maxFileSize := cfg.fileMaxSize(rule)
+	decodeMax := max(maxFileSize, cfg.maxFileSize())
+	var content []byte
+	var err error
+	if isBase64 {
+		if int64(base64.StdEncoding.DecodedLen(len(payload))) > decodeMax+2 {
+			return nil, &responseError{http.StatusRequestEntityTooLarge, "File too large", fmt.Errorf("file too large: ~%d bytes", base64.StdEncoding.DecodedLen(len(payload)))}
+		}
+		content, err = base64.StdEncoding.DecodeString(payload)
+	} else {
+		var s string
+		s, err = url.PathUnescape(payload)
+		content = []byte(s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rule.maxSize > 0 && rule.maxSize <= maxFileSize && int64(len(content)) > rule.maxSize {
+		return nil, &fileRuleError{"maxsize"}
+	}
+	if int64(len(content)) > maxFileSize {
+		return nil, &responseError{http.StatusRequestEntityTooLarge, "File too large", fmt.Errorf("file too large: %d bytes", len(content))}
+	}
+
+	filename := mediaParams["filename"]
+	if filename == "" {
+		filename = mediaParams["name"]
+	}
+	return &UploadedFile{
+		Filename:    filename,
+		ContentType: mediaType,
+		Content:     content,
+		Size:        int64(len(content)),
+		Hash:        fmt.Sprintf("%x", sha256.Sum256(content)),
+	}, nil
+}
@@ -0,0 +1,100 @@
+package formparser
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// bodyContentTypes are the request content types parseBody understands,
+// in the order it matches them.
+var bodyContentTypes = []string{
+	"multipart/form-data",
+	"multipart/mixed", "multipart/related",
+	"message/rfc822",
+	"application/x-www-form-urlencoded",
+	"application/json", "+json",
+	"application/xml", "text/xml", "+xml",
+	"application/yaml", "application/x-yaml", "+yaml",
+	"application/msgpack", "application/x-msgpack",
+	"application/cbor", "+cbor",
+	"application/toml",
+	"application/octet-stream",
+	"text/plain",
+	"text/csv",
+	"application/x-protobuf", "application/protobuf",
+}
+
+// DebugInfo is what DebugHandler reports.
+type DebugInfo struct {
+	Config           EffectiveConfig `json:"config"`
+	ContentTypes     []string        `json:"content_types"`               // request bodies the parser accepts; "+json" and the like are suffix matches
+	Validators       []string        `json:"validators"`                  // tags added through RegisterValidation
+	StructValidators []string        `json:"struct_validators,omitempty"` // types added through RegisterStructValidation
+	Stats            Stats           `json:"stats"`
+}
+
+// RegisterValidation adds a custom validation tag to Validator and records
+// it for DebugHandler. Tags registered on Validator directly work the same
+// but are not listed.
+func (cfg *Config) RegisterValidation(tag string, fn validator.Func, callValidationEvenIfNull ...bool) error {
+	if err := cfg.Validator.RegisterValidation(tag, fn, callValidationEvenIfNull...); err != nil {
+		return err
+	}
+	cfg.validations.Store(tag, struct{}{})
+	return nil
+}
+
+// RegisterStructValidation adds a struct-level validation for types to
+// Validator and records them for DebugHandler.
+func (cfg *Config) RegisterStructValidation(fn validator.StructLevelFunc, types ...interface{}) {
+	cfg.Validator.RegisterStructValidation(fn, types...)
+	for _, t := range types {
+		cfg.structValidations.Store(reflect.TypeOf(t), struct{}{})
+	}
+}
+
+// Debug returns the parser's effective configuration, accepted content
+// types, registered validators and live stats.
+func (cfg *Config) Debug() DebugInfo {
+	info := DebugInfo{
+		Config:           cfg.Effective(),
+		ContentTypes:     append([]string{}, bodyContentTypes...),
+		Validators:       []string{},
+		StructValidators: registeredTypeNames(&cfg.structValidations),
+		Stats:            cfg.Stats(),
+	}
+	if cfg.JSONAPI {
+		info.ContentTypes = append(info.ContentTypes, jsonAPIMediaType)
+	}
+	info.ContentTypes = append(info.ContentTypes, cfg.registeredMediaTypes()...)
+	cfg.validations.Range(func(tag, _ any) bool {
+		info.Validators = append(info.Validators, tag.(string))
+		return true
+	})
+	sort.Strings(info.Validators)
+	return info
+}
+
+// DebugHandler returns a handler that serves Debug as JSON to GET requests.
+// It exposes no secrets but does reveal limits and traffic, so mount it on
+// an internal admin route:
+//
+//	mux.Handle("/debug/formparser", cfg.DebugHandler())
+func (cfg *Config) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(cfg.Debug())
+	})
+}
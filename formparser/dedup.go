@@ -0,0 +1,97 @@
+package formparser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// DedupStore remembers stored files by content hash so clients that declare
+// a file's SHA-256 up front (X-Content-SHA256 part header) can reuse an
+// existing upload instead of storing it again.
+//
+// A declared hash acts as a capability for the stored file: only enable
+// deduplication where sharing a reference between clients is acceptable.
+type DedupStore interface {
+	Lookup(ctx context.Context, hash string) (ref FileRef, found bool, err error)
+	Save(ctx context.Context, ref FileRef) error
+}
+
+// declaredHash returns the lower-cased X-Content-SHA256 header of a file part.
+func declaredHash(h FileHeader) string {
+	return strings.ToLower(strings.TrimSpace(h.Header.Get("X-Content-SHA256")))
+}
+
+// lookupDuplicate returns the existing file for a declared hash, or nil.
+func (cfg *Config) lookupDuplicate(ctx context.Context, h FileHeader, hash string) (*UploadedFile, error) {
+	if cfg.DedupStore == nil || hash == "" {
+		return nil, nil
+	}
+	var ref FileRef
+	var found bool
+	err := cfg.callHook(ctx, "DedupStore", func(ctx context.Context) (err error) {
+		ref, found, err = cfg.DedupStore.Lookup(ctx, hash)
+		return err
+	})
+	if errors.Is(err, errHookSkipped) {
+		return nil, nil
+	}
+	if err != nil || !found {
+		return nil, err
+	}
+	return &UploadedFile{
+		Filename:    h.Filename,
+		ContentType: ref.ContentType,
+		Size:        ref.Size,
+		Hash:        ref.Hash,
+		StorageKey:  ref.StorageKey,
+		Existing:    true,
+	}, nil
+}
+
+// rememberFile records a newly stored file in the DedupStore.
+func (cfg *Config) rememberFile(ctx context.Context, file *UploadedFile) error {
+	if cfg.DedupStore == nil {
+		return nil
+	}
+	err := cfg.callHook(ctx, "DedupStore", func(ctx context.Context) error {
+		return cfg.DedupStore.Save(ctx, file.Ref())
+	})
+	if errors.Is(err, errHookSkipped) {
+		return nil
+	}
+	return err
+}
+
+// NewDedupStore returns a DedupStore that keeps file references in kv for
+// ttl (0 = forever), e.g. a MemoryStore or a redisstore.Store.
+func NewDedupStore(kv KeyValueStore, ttl time.Duration) DedupStore {
+	return kvDedupStore{kv: kv, ttl: ttl}
+}
+
+type kvDedupStore struct {
+	kv  KeyValueStore
+	ttl time.Duration
+}
+
+func (s kvDedupStore) Lookup(ctx context.Context, hash string) (FileRef, bool, error) {
+	data, found, err := s.kv.Get(ctx, "dedup:"+hash)
+	if err != nil || !found {
+		return FileRef{}, false, err
+	}
+	var ref FileRef
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return FileRef{}, false, err
+	}
+	return ref, true, nil
+}
+
+func (s kvDedupStore) Save(ctx context.Context, ref FileRef) error {
+	data, err := json.Marshal(ref)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, "dedup:"+ref.Hash, data, s.ttl)
+}
@@ -0,0 +1,168 @@
+package formparser
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dependency effects, grouping the validator's cross-field rules by what a
+// form should do when the field they depend on changes.
+const (
+	EffectRequire = "require" // required_if, required_unless, required_with*, required_without*
+	EffectExclude = "exclude" // excluded_if, excluded_unless, excluded_with*, excluded_without*
+	EffectCompare = "compare" // eqfield, nefield, gtfield, ltefield, fieldcontains, ...
+)
+
+// FieldDependency is one edge of a DependencyGraph: a rule on Field that
+// reads the value of On. A *_if or *_unless rule testing several fields
+// yields one edge per field, and its condition holds only when all of them
+// match, as in the validator.
+type FieldDependency struct {
+	Field  string `json:"field"`
+	On     string `json:"on"`
+	Rule   string `json:"rule"`            // the validator tag, e.g. "required_if"
+	Value  string `json:"value,omitempty"` // the value of On tested by *_if and *_unless rules
+	Effect string `json:"effect"`
+}
+
+// DependencyGraph describes the cross-field validation rules of a struct,
+// so a frontend can show, hide, enable or disable fields as the user types
+// without duplicating the rules. Fields are named by their json tag, else
+// their form tag, with nested struct fields dotted ("address.zip").
+type DependencyGraph struct {
+	Fields       []string          `json:"fields"`
+	Dependencies []FieldDependency `json:"dependencies"`
+}
+
+// Dependents returns the dependencies whose rules read field, i.e. the
+// rules to re-evaluate when its value changes.
+func (g DependencyGraph) Dependents(field string) []FieldDependency {
+	var deps []FieldDependency
+	for _, d := range g.Dependencies {
+		if d.On == field {
+			deps = append(deps, d)
+		}
+	}
+	return deps
+}
+
+// dependencyRules maps the cross-field validator tags to their effect.
+// Conditional rules take "Field value" pairs, the others field names.
+var dependencyRules = map[string]string{
+	"required_if": EffectRequire, "required_unless": EffectRequire,
+	"required_with": EffectRequire, "required_with_all": EffectRequire,
+	"required_without": EffectRequire, "required_without_all": EffectRequire,
+	"excluded_if": EffectExclude, "excluded_unless": EffectExclude,
+	"excluded_with": EffectExclude, "excluded_with_all": EffectExclude,
+	"excluded_without": EffectExclude, "excluded_without_all": EffectExclude,
+	"eqfield": EffectCompare, "nefield": EffectCompare,
+	"gtfield": EffectCompare, "gtefield": EffectCompare,
+	"ltfield": EffectCompare, "ltefield": EffectCompare,
+	"eqcsfield": EffectCompare, "necsfield": EffectCompare,
+	"gtcsfield": EffectCompare, "gtecsfield": EffectCompare,
+	"ltcsfield": EffectCompare, "ltecsfield": EffectCompare,
+	"fieldcontains": EffectCompare, "fieldexcludes": EffectCompare,
+}
+
+// dependencyGraphCache maps reflect.Type to the DependencyGraph built for it.
+var dependencyGraphCache sync.Map
+
+// Dependencies extracts the dependency graph of dst, a struct or pointer to
+// struct, from its `validate` tags. Rules after "dive" apply to elements
+// and are skipped.
+func Dependencies(dst interface{}) DependencyGraph {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return DependencyGraph{Fields: []string{}, Dependencies: []FieldDependency{}}
+	}
+	if cached, ok := dependencyGraphCache.Load(t); ok {
+		return cached.(DependencyGraph)
+	}
+	g := DependencyGraph{Fields: []string{}, Dependencies: []FieldDependency{}}
+	addDependencies(&g, t, "", t)
+	dependencyGraphCache.Store(t, g)
+	return g
+}
+
+// addDependencies adds the fields of struct t, named under prefix, to g.
+// Cross-struct rules (eqcsfield, ...) name fields from root.
+func addDependencies(g *DependencyGraph, t reflect.Type, prefix string, root reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := echoName(f)
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		name = prefix + name
+		g.Fields = append(g.Fields, name)
+
+		tag, _, _ := strings.Cut(f.Tag.Get("validate"), "dive")
+		for _, alternatives := range strings.Split(tag, ",") {
+			for _, rule := range strings.Split(alternatives, "|") {
+				tagName, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+				effect, ok := dependencyRules[tagName]
+				if !ok || param == "" {
+					continue
+				}
+				g.Dependencies = append(g.Dependencies, ruleDependencies(name, tagName, effect, param, t, prefix, root)...)
+			}
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) && ft != t {
+			addDependencies(g, ft, name+".", root)
+		}
+	}
+}
+
+// ruleDependencies returns the edges of one rule on field.
+func ruleDependencies(field, rule, effect, param string, t reflect.Type, prefix string, root reflect.Type) []FieldDependency {
+	args := strings.Fields(param)
+	var deps []FieldDependency
+	switch {
+	case strings.HasSuffix(rule, "_if") || strings.HasSuffix(rule, "_unless"):
+		for i := 0; i+1 < len(args); i += 2 {
+			on := dependencyField(args[i], t, prefix)
+			deps = append(deps, FieldDependency{Field: field, On: on, Rule: rule, Value: args[i+1], Effect: effect})
+		}
+	case strings.Contains(rule, "csfield"):
+		deps = append(deps, FieldDependency{Field: field, On: dependencyField(param, root, ""), Rule: rule, Effect: effect})
+	default:
+		for _, arg := range args {
+			deps = append(deps, FieldDependency{Field: field, On: dependencyField(arg, t, prefix), Rule: rule, Effect: effect})
+		}
+	}
+	return deps
+}
+
+// dependencyField maps a dotted path of Go field names within t to the
+// graph's field name, keeping unknown segments as they are.
+func dependencyField(path string, t reflect.Type, prefix string) string {
+	var names []string
+	for _, segment := range strings.Split(path, ".") {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		var f reflect.StructField
+		ok := false
+		if t != nil && t.Kind() == reflect.Struct {
+			f, ok = t.FieldByName(segment)
+		}
+		if !ok {
+			names = append(names, segment)
+			t = nil
+			continue
+		}
+		names = append(names, echoName(f))
+		t = f.Type
+	}
+	return prefix + strings.Join(names, ".")
+}
@@ -0,0 +1,123 @@
+package formparser
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// MaxDepthError is returned when a payload nests deeper than Config.MaxDecodeDepth.
+type MaxDepthError struct {
+	Limit int
+	Key   string // offending form key; empty for JSON bodies
+}
+
+func (e *MaxDepthError) Error() string {
+	if e.Key != "" {
+		return fmt.Sprintf("form key %q exceeds max decode depth %d", e.Key, e.Limit)
+	}
+	return fmt.Sprintf("JSON body exceeds max decode depth %d", e.Limit)
+}
+
+// MaxTokensError is returned when a JSON body holds more tokens than
+// Config.MaxJSONTokens.
+type MaxTokensError struct {
+	Limit int
+}
+
+func (e *MaxTokensError) Error() string {
+	return fmt.Sprintf("JSON body exceeds max token count %d", e.Limit)
+}
+
+// jsonLimitReader fails once the JSON streamed through it opens more than
+// maxDepth nested objects/arrays or holds more than maxTokens tokens, before
+// the decoder has to recurse that deep or allocate that many values. Tokens
+// are counted as json.Decoder.Token returns them: delimiters, object keys
+// and scalar values. A zero limit is not enforced.
+type jsonLimitReader struct {
+	r         io.Reader
+	maxDepth  int
+	maxTokens int
+	depth     int
+	tokens    int
+	inString  bool
+	escaped   bool
+	inScalar  bool
+}
+
+func (d *jsonLimitReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	for _, c := range p[:n] {
+		if d.inScalar && !isJSONScalarByte(c) {
+			d.inScalar = false
+		}
+		switch {
+		case d.escaped:
+			d.escaped = false
+		case d.inString:
+			switch c {
+			case '\\':
+				d.escaped = true
+			case '"':
+				d.inString = false
+			}
+		case c == '"':
+			d.inString = true
+			err = d.countToken(err)
+		case c == '{' || c == '[':
+			if d.depth++; d.maxDepth > 0 && d.depth > d.maxDepth {
+				return 0, &MaxDepthError{Limit: d.maxDepth}
+			}
+			err = d.countToken(err)
+		case c == '}' || c == ']':
+			d.depth--
+			err = d.countToken(err)
+		case isJSONScalarByte(c) && !d.inScalar:
+			d.inScalar = true
+			err = d.countToken(err)
+		}
+		if _, ok := err.(*MaxTokensError); ok {
+			return 0, err
+		}
+	}
+	return n, err
+}
+
+// countToken counts one token, returning a MaxTokensError in place of err
+// once the limit is passed.
+func (d *jsonLimitReader) countToken(err error) error {
+	if d.tokens++; d.maxTokens > 0 && d.tokens > d.maxTokens {
+		return &MaxTokensError{Limit: d.maxTokens}
+	}
+	return err
+}
+
+// isJSONScalarByte reports whether c can be part of a number, true, false
+// or null.
+func isJSONScalarByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c == '-' || c == '+' || c == '.' || c == 'E'
+}
+
+// limitJSON wraps r with depth and token checks when MaxDecodeDepth or
+// MaxJSONTokens is set.
+func (cfg *Config) limitJSON(r io.Reader) io.Reader {
+	if cfg.MaxDecodeDepth <= 0 && cfg.MaxJSONTokens <= 0 {
+		return r
+	}
+	return &jsonLimitReader{r: r, maxDepth: cfg.MaxDecodeDepth, maxTokens: cfg.MaxJSONTokens}
+}
+
+// checkValuesDepth rejects form keys such as "a.b[0].c" whose nesting
+// exceeds MaxDecodeDepth.
+func (cfg *Config) checkValuesDepth(values url.Values) error {
+	if cfg.MaxDecodeDepth <= 0 {
+		return nil
+	}
+	for key := range values {
+		if depth := strings.Count(key, ".") + strings.Count(key, "["); depth > cfg.MaxDecodeDepth {
+			return &MaxDepthError{Limit: cfg.MaxDecodeDepth, Key: key}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package formparser
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// EchoField is one field of a client-safe echo of a parsed struct. Nested
+// structs, including those in slices and maps, are echoed as []EchoField.
+type EchoField struct {
+	Name     string `json:"name"`
+	Value    any    `json:"value"`
+	Masked   bool   `json:"masked,omitempty"`
+	ReadOnly bool   `json:"readonly,omitempty"`
+}
+
+// Echo returns the fields of dst that are safe to send back to the client,
+// e.g. for a preview/confirm screen. Fields are named by their json tag,
+// else their form tag, and their tags control what is echoed:
+//
+//	sensitive:"true"   field is left out (passwords, tokens)
+//	sensitive:"last4"  all but the last four characters are masked (card numbers)
+//	sensitive:"mask"   the whole value is masked
+//	readonly:"true"    field is echoed with ReadOnly set, for display only
+//
+// Fields tagged `cc:"pan"` are masked as sensitive:"last4" unless they carry
+// their own sensitive tag.
+func Echo(dst interface{}) []EchoField {
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+
+	fields := []EchoField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := echoName(f)
+		if name == "-" {
+			continue
+		}
+		field := EchoField{Name: name, ReadOnly: f.Tag.Get("readonly") == "true"}
+		switch sensitiveTag(f) {
+		case "":
+			field.Value = echoValue(v.Field(i))
+		case "last4":
+			field.Value, field.Masked = maskValue(v.Field(i), 4), true
+		case "mask":
+			field.Value, field.Masked = maskValue(v.Field(i), 0), true
+		default:
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// sensitiveTag returns the sensitive tag of f, treating `cc:"pan"` fields
+// without one as sensitive:"last4".
+func sensitiveTag(f reflect.StructField) string {
+	sensitive := f.Tag.Get("sensitive")
+	if sensitive == "" && tagName(f.Tag.Get("cc")) == "pan" {
+		sensitive = "last4"
+	}
+	return sensitive
+}
+
+// echoName returns the client-facing name of a struct field.
+func echoName(f reflect.StructField) string {
+	if name := tagName(f.Tag.Get("json")); name != "" {
+		return name
+	}
+	return formKey(f)
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// echoValue echoes nested structs field by field so their tags apply too,
+// including structs held in slices, arrays and maps. Types that marshal
+// themselves, such as time.Time, are echoed as is.
+func echoValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Elem().Kind() == reflect.Struct && !marshalsItself(v.Type()) {
+			return Echo(v.Interface())
+		}
+	case reflect.Struct:
+		if !marshalsItself(v.Type()) && !marshalsItself(reflect.PointerTo(v.Type())) {
+			p := reflect.New(v.Type())
+			p.Elem().Set(v)
+			return Echo(p.Interface())
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() || !holdsStructs(v.Type().Elem()) {
+			break
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = echoValue(v.Index(i))
+		}
+		return items
+	case reflect.Map:
+		if v.IsNil() || marshalsItself(v.Type()) || !holdsStructs(v.Type().Elem()) {
+			break
+		}
+		items := make(map[string]any, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			items[mapKeyString(iter.Key())] = echoValue(iter.Value())
+		}
+		return items
+	}
+	return v.Interface()
+}
+
+// holdsStructs reports whether values of t can contain structs that Echo
+// walks, directly or through pointers, slices, arrays, maps or interfaces.
+func holdsStructs(t reflect.Type) bool {
+	for {
+		if marshalsItself(t) {
+			return false
+		}
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		case reflect.Interface:
+			return true
+		case reflect.Struct:
+			return !marshalsItself(reflect.PointerTo(t))
+		default:
+			return false
+		}
+	}
+}
+
+// marshalsItself reports whether t has its own JSON or text encoding.
+func marshalsItself(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// maskValue formats v and replaces all but its last keep characters with
+// '*'. Zero values stay empty so previews do not suggest a value was sent.
+func maskValue(v reflect.Value, keep int) string {
+	if v.IsZero() {
+		return ""
+	}
+	s := fmt.Sprint(reflect.Indirect(v).Interface())
+	n := utf8.RuneCountInString(s)
+	if keep >= n {
+		keep = 0 // too short to reveal anything safely
+	}
+	runes := []rune(s)
+	return strings.Repeat("*", n-keep) + string(runes[n-keep:])
+}
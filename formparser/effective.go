@@ -0,0 +1,149 @@
+package formparser
+
+import (
+	"reflect"
+	"sort"
+)
+
+// EffectiveConfig is a serializable snapshot of a Config's limits, allowed
+// types and registered hooks. Hooks are listed by name only and secrets are
+// never included, so it is safe to expose on /debug endpoints.
+type EffectiveConfig struct {
+	MaxFileSize             int64                      `json:"max_file_size"`
+	MaxTextBodySize         int64                      `json:"max_text_body_size"`
+	MaxDecompressedSize     int64                      `json:"max_decompressed_size"`
+	MaxImagePixels          int                        `json:"max_image_pixels"`
+	ContentEncodings        []string                   `json:"content_encodings"`
+	MinReadRate             int64                      `json:"min_read_rate,omitempty"`
+	PartIdleTimeout         string                     `json:"part_idle_timeout,omitempty"`
+	MaxUploadBytesPerSecond int64                      `json:"max_upload_bytes_per_second,omitempty"`
+	MultipartJSONField      string                     `json:"multipart_json_field"`
+	CopyBufferSize          int                        `json:"copy_buffer_size"`
+	AllowedMIMETypes        []string                   `json:"allowed_mime_types"`
+	TagMode                 string                     `json:"tag_mode"`
+	URLEncoding             string                     `json:"url_encoding"`
+	MissingContentType      string                     `json:"missing_content_type"`
+	TextLimitPolicy         string                     `json:"text_limit_policy"`
+	NumberLocale            string                     `json:"number_locale,omitempty"`
+	QueryCacheSize          int                        `json:"query_cache_size"`
+	MaxDecodeDepth          int                        `json:"max_decode_depth"`
+	MaxJSONTokens           int                        `json:"max_json_tokens"`
+	ErrorGzipThreshold      int                        `json:"error_gzip_threshold,omitempty"`
+	ErrorFormat             ErrorFormat                `json:"error_format"`
+	Mode                    string                     `json:"mode"`
+	JSONAPI                 bool                       `json:"json_api"`
+	Merge                   bool                       `json:"merge"`
+	DecodeOnly              bool                       `json:"decode_only"`
+	EmptyFileRequired       bool                       `json:"empty_file_required"`
+	AllowFileTagMaxSize     bool                       `json:"allow_file_tag_max_size"`
+	TrailerChecksums        bool                       `json:"trailer_checksums"`
+	MIMEPolicies            map[string]MIMEPolicy      `json:"mime_policies,omitempty"`
+	PasswordPolicies        map[string]PasswordPolicy  `json:"password_policies,omitempty"`
+	PDFRules                map[string]PDFRule         `json:"pdf_rules,omitempty"`
+	MediaRules              map[string]MediaRule       `json:"media_rules,omitempty"`
+	TextRules               map[string]TextRule        `json:"text_rules,omitempty"`
+	Thumbnails              map[string][]ThumbnailSize `json:"thumbnails,omitempty"`
+	Converters              []string                   `json:"converters,omitempty"`
+	Breakers                map[string]*CircuitBreaker `json:"breakers,omitempty"`
+	UploadTokenFields       map[string]string          `json:"upload_token_fields,omitempty"`
+	Hooks                   []string                   `json:"hooks"`
+	Computers               []string                   `json:"computers,omitempty"`
+	DstPools                []string                   `json:"dst_pools,omitempty"`
+}
+
+// Effective returns the configuration as the parser will apply it, with
+// defaults filled in.
+func (cfg *Config) Effective() EffectiveConfig {
+	eff := EffectiveConfig{
+		MaxFileSize:             cfg.maxFileSize(),
+		MaxTextBodySize:         cfg.maxTextBodySize(),
+		MaxDecompressedSize:     cfg.maxDecompressedSize(),
+		MaxImagePixels:          cfg.maxImagePixels(),
+		ContentEncodings:        cfg.contentEncodings(),
+		MinReadRate:             cfg.MinReadRate,
+		MaxUploadBytesPerSecond: cfg.MaxUploadBytesPerSecond,
+		MultipartJSONField:      cfg.multipartJSONField(),
+		CopyBufferSize:          cfg.copyBufferSize(),
+		AllowedMIMETypes:        append([]string{}, cfg.AllowedMIMETypes...),
+		TagMode:                 cfg.TagMode.String(),
+		URLEncoding:             cfg.URLEncoding.String(),
+		MissingContentType:      cfg.MissingContentType.String(),
+		TextLimitPolicy:         cfg.TextLimitPolicy.String(),
+		NumberLocale:            cfg.NumberLocale,
+		QueryCacheSize:          cfg.QueryCacheSize,
+		MaxDecodeDepth:          cfg.MaxDecodeDepth,
+		MaxJSONTokens:           cfg.MaxJSONTokens,
+		ErrorGzipThreshold:      cfg.ErrorGzipThreshold,
+		ErrorFormat:             cfg.ErrorFormat,
+		Mode:                    cfg.Mode.String(),
+		JSONAPI:                 cfg.JSONAPI,
+		Merge:                   cfg.Merge,
+		DecodeOnly:              cfg.DecodeOnly,
+		EmptyFileRequired:       cfg.EmptyFileRequired,
+		AllowFileTagMaxSize:     cfg.AllowFileTagMaxSize,
+		TrailerChecksums:        cfg.VerifyTrailerChecksum,
+		MIMEPolicies:            cfg.MIMEPolicies,
+		PasswordPolicies:        cfg.PasswordPolicies,
+		PDFRules:                cfg.PDFRules,
+		MediaRules:              cfg.MediaRules,
+		TextRules:               cfg.TextRules,
+		Thumbnails:              cfg.Thumbnails,
+		UploadTokenFields:       cfg.UploadTokenFields,
+		Breakers:                cfg.Breakers,
+		Hooks:                   []string{},
+	}
+	if cfg.PartIdleTimeout > 0 {
+		eff.PartIdleTimeout = cfg.PartIdleTimeout.String()
+	}
+	for mimeType := range cfg.Converters {
+		eff.Converters = append(eff.Converters, mimeType)
+	}
+	sort.Strings(eff.Converters)
+
+	hooks := map[string]bool{
+		"Decoder":           cfg.Decoder != nil,
+		"Validator":         cfg.Validator != nil,
+		"ValidatorResolver": cfg.ValidatorResolver != nil,
+		"Messages":          cfg.Messages != nil,
+		"MediaProber":       cfg.MediaProber != nil,
+		"FileStore":         cfg.FileStore != nil,
+		"KeyFunc":           cfg.KeyFunc != nil,
+		"FileURL":           cfg.FileURL != nil,
+		"OnField":           cfg.OnField != nil,
+		"OnFileStart":       cfg.OnFileStart != nil,
+		"DedupStore":        cfg.DedupStore != nil,
+		"Enricher":          cfg.Enricher != nil,
+		"Outbox":            cfg.Outbox != nil,
+		"BreachChecker":     cfg.BreachChecker != nil,
+		"AddressNormalizer": cfg.AddressNormalizer != nil,
+		"Logger":            cfg.Logger != nil,
+		"BeforeBody":        cfg.BeforeBody != nil,
+		"Events":            cfg.Events != nil,
+		"UploadID":          cfg.UploadID != nil,
+		"Clock":             cfg.Clock != nil,
+		"Random":            cfg.Random != nil,
+	}
+	for name, set := range hooks {
+		if set {
+			eff.Hooks = append(eff.Hooks, name)
+		}
+	}
+	sort.Strings(eff.Hooks)
+
+	eff.Computers = registeredTypeNames(&cfg.computers)
+	eff.DstPools = registeredTypeNames(&cfg.dstPools)
+	return eff
+}
+
+// registeredTypeNames lists the reflect.Type keys of m as sorted strings.
+func registeredTypeNames(m interface {
+	Range(func(key, value any) bool)
+}) []string {
+	var names []string
+	m.Range(func(key, _ any) bool {
+		names = append(names, key.(reflect.Type).String())
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
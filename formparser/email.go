@@ -0,0 +1,206 @@
+package formparser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxEmailDepth caps how deeply multipart sections of an email may nest.
+const maxEmailDepth = 10
+
+// Email is a message/rfc822 request body, as posted by inbound mail
+// webhooks.
+type Email struct {
+	Header      mail.Header
+	Text        string          // the first inline text/plain body, in UTF-8
+	HTML        string          // the first inline text/html body, in UTF-8
+	Attachments []*UploadedFile // in message order
+}
+
+// emailFields maps the headers decoded into dst to their field names.
+var emailFields = map[string]string{
+	"From": "from", "To": "to", "Cc": "cc", "Reply-To": "reply_to",
+	"Subject": "subject", "Date": "date", "Message-Id": "message_id",
+}
+
+// responseError is a rejected email part or inline file with the response
+// it deserves.
+type responseError struct {
+	status int
+	msg    string
+	err    error
+}
+
+func (e *responseError) Error() string { return e.err.Error() }
+func (e *responseError) Unwrap() error { return e.err }
+
+// parseEmail handles message/rfc822 payloads. The headers in emailFields
+// (RFC 2047 decoded), the text and HTML bodies and the attachment count
+// ("attachments") are decoded into dst under the field names of the
+// SendGrid and Mailgun inbound webhooks; attachments are stored in
+// ParseResult.Files as "attachment1", "attachment2", ... and must have an
+// allowed content type and fit in MaxFileSize like multipart uploads. The
+// whole message is kept in ParseResult.Email.
+func (cfg *Config) parseEmail(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	r.Body = cfg.guardBody(r.Body)
+	msg, err := mail.ReadMessage(r.Body)
+	if err != nil {
+		return readFailed(w, r.Body, err, "Can't parse email", http.StatusBadRequest)
+	}
+	email := &Email{Header: msg.Header}
+	res.Email = email
+	if err := cfg.readEmailPart(email, textproto.MIMEHeader(msg.Header), msg.Body, 0); err != nil {
+		var e *responseError
+		if errors.As(err, &e) {
+			http.Error(w, e.msg, e.status)
+			return err
+		}
+		return readFailed(w, r.Body, err, "Can't parse email", http.StatusBadRequest)
+	}
+
+	values := make(url.Values)
+	for header, field := range emailFields {
+		if v := msg.Header.Get(header); v != "" {
+			values.Set(field, decodeEmailHeader(v))
+		}
+	}
+	if email.Text != "" {
+		values.Set("text", email.Text)
+	}
+	if email.HTML != "" {
+		values.Set("html", email.HTML)
+	}
+	res.Values = copyValues(values)
+	values.Set("attachments", strconv.Itoa(len(email.Attachments)))
+	res.Files = make(map[string]*UploadedFile)
+	for i, file := range email.Attachments {
+		field := "attachment" + strconv.Itoa(i+1)
+		res.addFile(field, file)
+		values.Set(field, file.Hash)
+	}
+
+	fieldErrors := cfg.prepareValues(r, dst, values)
+	if err := cfg.decodeValues(dst, values, res, fieldErrors); err != nil {
+		cfg.httpError(w, "Form nested too deeply", http.StatusBadRequest, err)
+		return err
+	}
+	return cfg.validateAndRespond(w, r, dst, res, fieldErrors)
+}
+
+// readEmailPart reads one entity of an email into email, descending into
+// multipart sections. Inline text and HTML fill the bodies; every other
+// leaf, and text sent as an attachment, becomes an attachment.
+func (cfg *Config) readEmailPart(email *Email, header textproto.MIMEHeader, body io.Reader, depth int) error {
+	mediaType, params := parseMediaType(header.Get("Content-Type"))
+	if mediaType == "" {
+		mediaType, params = "text/plain", map[string]string{} // RFC 2045 default
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxEmailDepth {
+			return &responseError{http.StatusBadRequest, "Email nested too deeply", errors.New("email nested too deeply")}
+		}
+		if params["boundary"] == "" {
+			return errors.New("missing multipart boundary")
+		}
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			err = cfg.readEmailPart(email, p.Header, p, depth+1)
+			p.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	maxFileSize := cfg.maxFileSize()
+	var buf bytes.Buffer
+	n, err := cfg.copyLimited(&buf, body, maxFileSize+1)
+	if err != nil {
+		return err
+	}
+	if n > maxFileSize {
+		return &responseError{http.StatusRequestEntityTooLarge, "File too large", fmt.Errorf("file too large: %d bytes", n)}
+	}
+
+	disposition, dispParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	filename = decodeEmailHeader(filename)
+	if disposition != "attachment" && filename == "" && (mediaType == "text/plain" || mediaType == "text/html") {
+		text, err := emailText(buf.Bytes(), params["charset"])
+		if err != nil {
+			return &responseError{http.StatusUnsupportedMediaType, "Unsupported charset", err}
+		}
+		if mediaType == "text/plain" && email.Text == "" {
+			email.Text = text
+		} else if mediaType == "text/html" && email.HTML == "" {
+			email.HTML = text
+		}
+		return nil
+	}
+
+	if !cfg.isAllowedContentType(mediaType) {
+		return &responseError{http.StatusBadRequest, "Unsupported file type", fmt.Errorf("unsupported file type: %s", mediaType)}
+	}
+	email.Attachments = append(email.Attachments, &UploadedFile{
+		Filename:    filename,
+		ContentType: mediaType,
+		Content:     buf.Bytes(),
+		Size:        n,
+		Hash:        fmt.Sprintf("%x", sha256.Sum256(buf.Bytes())),
+	})
+	return nil
+}
+
+// emailText converts a text body from charset to UTF-8.
+func emailText(content []byte, charset string) (string, error) {
+	dec, err := charsetDecoder(charset)
+	if err != nil || dec == nil {
+		return string(content), err
+	}
+	return dec.String(string(content))
+}
+
+// decodeEmailHeader decodes RFC 2047 encoded-words in v, in any charset
+// charsetDecoder knows. Malformed words are kept as they are.
+func decodeEmailHeader(v string) string {
+	wd := &mime.WordDecoder{CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		dec, err := charsetDecoder(charset)
+		if err != nil || dec == nil {
+			return input, err
+		}
+		return dec.Reader(input), nil
+	}}
+	if decoded, err := wd.DecodeHeader(v); err == nil {
+		return decoded
+	}
+	return v
+}
@@ -0,0 +1,48 @@
+package formparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+)
+
+// isFilePart reports whether part came from a file input. A file input left
+// empty still sends filename="", which Part.FileName reports as "".
+func isFilePart(part *multipart.Part) bool {
+	if part.FileName() != "" {
+		return true
+	}
+	_, params, err := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+	if err != nil {
+		return false
+	}
+	_, ok := params["filename"]
+	return ok
+}
+
+// peekFilePart reports whether a file part has no content. The returned
+// reader must be used in place of part, as the peeked byte is buffered.
+func peekFilePart(part io.Reader) (io.Reader, bool, error) {
+	br := bufio.NewReaderSize(part, 16)
+	if _, err := br.Peek(1); err == io.EOF {
+		return br, true, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return br, false, nil
+}
+
+// emptyFileError returns the field error for an empty file part: none when
+// empty parts are skipped, else the field's "required" message.
+func (cfg *Config) emptyFileError(r *http.Request, field string) string {
+	if !cfg.EmptyFileRequired {
+		return ""
+	}
+	if msg, ok := cfg.lookupMessage(field, "required", requestLang(r)); ok {
+		return msg
+	}
+	return fmt.Sprintf("%s is required", field)
+}
@@ -0,0 +1,156 @@
+package formparser
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	defaultMaxDecompressedSize = 32 << 20 // 32MB
+	zstdMaxWindow              = 8 << 20  // 8MB
+)
+
+// Decompressor opens a reader over the decoded form of a request body
+// compressed with one Content-Encoding.
+type Decompressor func(body io.Reader) (io.ReadCloser, error)
+
+// builtinDecompressors are the content codings decoded without any
+// registration: gzip and deflate from the standard library, br and zstd
+// through github.com/andybalholm/brotli and github.com/klauspost/compress.
+var builtinDecompressors = map[string]Decompressor{
+	"gzip":   func(body io.Reader) (io.ReadCloser, error) { return gzip.NewReader(body) },
+	"x-gzip": func(body io.Reader) (io.ReadCloser, error) { return gzip.NewReader(body) },
+	"deflate": func(body io.Reader) (io.ReadCloser, error) {
+		// RFC 9110 deflate is zlib-wrapped, but many clients send raw
+		// DEFLATE; accept both.
+		br := bufio.NewReader(body)
+		if header, _ := br.Peek(2); len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	},
+	"br": func(body io.Reader) (io.ReadCloser, error) { return io.NopCloser(brotli.NewReader(body)), nil },
+	"zstd": func(body io.Reader) (io.ReadCloser, error) {
+		// RFC 8878 caps the window for HTTP at 8MB; a larger one is
+		// refused rather than allocated.
+		d, err := zstd.NewReader(body, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(zstdMaxWindow))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	},
+}
+
+// decompressor returns the Decompressor for coding, preferring one
+// registered in Decompressors.
+func (cfg *Config) decompressor(coding string) (Decompressor, bool) {
+	if d, ok := cfg.Decompressors[coding]; ok && d != nil {
+		return d, true
+	}
+	d, ok := builtinDecompressors[coding]
+	return d, ok
+}
+
+// maxDecompressedSize returns MaxDecompressedSize or the default when unset.
+func (cfg *Config) maxDecompressedSize() int64 {
+	if cfg.MaxDecompressedSize > 0 {
+		return cfg.MaxDecompressedSize
+	}
+	return defaultMaxDecompressedSize
+}
+
+// contentEncodings lists the codings supported by cfg, sorted.
+func (cfg *Config) contentEncodings() []string {
+	seen := make(map[string]bool)
+	for coding := range builtinDecompressors {
+		seen[coding] = true
+	}
+	for coding, d := range cfg.Decompressors {
+		if d != nil {
+			seen[coding] = true
+		}
+	}
+	codings := make([]string, 0, len(seen))
+	for coding := range seen {
+		codings = append(codings, coding)
+	}
+	sort.Strings(codings)
+	return codings
+}
+
+// decodeContentEncoding replaces a compressed request body with its decoded
+// form, undoing the codings of Content-Encoding in reverse order. The
+// decoded body is buffered and capped at MaxDecompressedSize, so a small
+// compressed body cannot expand without bound (a decompression bomb).
+// Unknown codings are answered with 415 and an Accept-Encoding header
+// listing the supported ones, as RFC 9110 suggests. The wire body is read
+// through guardBody, so the read limits and upload throttle apply to
+// compressed bodies whatever their content type.
+func (cfg *Config) decodeContentEncoding(w http.ResponseWriter, r *http.Request) error {
+	var codings []string
+	for _, coding := range strings.Split(r.Header.Get("Content-Encoding"), ",") {
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "" && coding != "identity" {
+			codings = append(codings, coding)
+		}
+	}
+	if len(codings) == 0 || r.Body == nil {
+		return nil
+	}
+
+	maxSize := cfg.maxDecompressedSize()
+	r.Body = cfg.guardBody(r.Body)
+	var body io.Reader = r.Body
+	for i := len(codings) - 1; i >= 0; i-- {
+		d, ok := cfg.decompressor(codings[i])
+		if !ok {
+			w.Header().Set("Accept-Encoding", strings.Join(cfg.contentEncodings(), ", "))
+			http.Error(w, "Unsupported Content-Encoding", http.StatusUnsupportedMediaType)
+			return fmt.Errorf("unsupported content encoding: %s", codings[i])
+		}
+		rc, err := d(body)
+		if err != nil {
+			http.Error(w, "Invalid compressed body", http.StatusBadRequest)
+			return err
+		}
+		defer rc.Close()
+		body = rc
+	}
+
+	var buf bytes.Buffer
+	n, err := cfg.copyLimited(&buf, body, maxSize+1)
+	if err != nil {
+		return readFailed(w, r.Body, err, "Invalid compressed body", http.StatusBadRequest)
+	}
+	if n > maxSize {
+		http.Error(w, "Decompressed body too large", http.StatusRequestEntityTooLarge)
+		return fmt.Errorf("decompressed body too large: over %d bytes", maxSize)
+	}
+	// The wire body must end with the compressed stream. Reading to EOF makes
+	// trailers available; anything left over is rejected rather than
+	// drained, so a client cannot stream unbounded data after it.
+	if n, err := io.CopyN(io.Discard, r.Body, 1); n > 0 {
+		http.Error(w, "Invalid compressed body", http.StatusBadRequest)
+		return errors.New("data after compressed body")
+	} else if err != nil && err != io.EOF {
+		return readFailed(w, r.Body, err, "Invalid compressed body", http.StatusBadRequest)
+	}
+
+	r.Body = guardedBody{io.NopCloser(&buf)}
+	r.ContentLength = int64(buf.Len())
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	return nil
+}
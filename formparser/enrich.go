@@ -0,0 +1,98 @@
+package formparser
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// Enricher resolves values for fields tagged `ctx:"<key>"`, typically from
+// values an auth middleware stored in the request context.
+type Enricher interface {
+	Enrich(ctx context.Context, key string) (value any, ok bool)
+}
+
+// EnricherFunc adapts an ordinary function to the Enricher interface.
+type EnricherFunc func(ctx context.Context, key string) (any, bool)
+
+// Enrich calls f(ctx, key).
+func (f EnricherFunc) Enrich(ctx context.Context, key string) (any, bool) {
+	return f(ctx, key)
+}
+
+// enrich sets the top-level `ctx`-tagged fields of dst. Client-submitted
+// values are always overwritten, and fields the Enricher cannot resolve are
+// zeroed, so identity fields can never be spoofed through the body. Values
+// must be assignable to their field, or numbers that convert to the field's
+// numeric type without loss; other values leave the field zero and are
+// reported as field errors.
+func (cfg *Config) enrich(ctx context.Context, dst interface{}) FieldErrors {
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	fieldErrors := make(FieldErrors)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := t.Field(i).Tag.Lookup("ctx")
+		if !ok || !v.Field(i).CanSet() {
+			continue
+		}
+		field := v.Field(i)
+		field.SetZero()
+		if cfg.Enricher == nil {
+			continue
+		}
+
+		value, ok := cfg.Enricher.Enrich(ctx, key)
+		if !ok || value == nil {
+			continue
+		}
+		rv := reflect.ValueOf(value)
+		switch {
+		case rv.Type().AssignableTo(field.Type()):
+			field.Set(rv)
+		case isNumberKind(rv.Kind()) && isNumberKind(field.Kind()) && convertsExactly(rv, field.Type()):
+			field.Set(rv.Convert(field.Type()))
+		default:
+			name := strings.ToLower(t.Field(i).Name)
+			fieldErrors[name] = fmt.Sprintf("%s has an invalid value", name)
+		}
+	}
+	return fieldErrors
+}
+
+// isNumberKind reports whether k is an integer or floating-point kind.
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// convertsExactly reports whether the number v keeps its exact value when
+// converted to t: no overflow, lost fraction or flipped sign.
+func convertsExactly(v reflect.Value, t reflect.Type) bool {
+	if v.CanFloat() && (math.IsNaN(v.Float()) || math.IsInf(v.Float(), 0)) {
+		return false
+	}
+	return exactNumber(v).Cmp(exactNumber(v.Convert(t))) == 0
+}
+
+// exactNumber returns the value of the number v without rounding.
+func exactNumber(v reflect.Value) *big.Float {
+	switch {
+	case v.CanInt():
+		return new(big.Float).SetInt64(v.Int())
+	case v.CanUint():
+		return new(big.Float).SetUint64(v.Uint())
+	default:
+		return new(big.Float).SetFloat64(v.Float())
+	}
+}
@@ -0,0 +1,125 @@
+package formparser
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ErrorFormat selects the shape of validation error responses.
+type ErrorFormat int
+
+const (
+	// ErrorFormatV1 is the original envelope:
+	// {"message": "Validation failed", "fields": {"email": "..."}, "codes": {...}}.
+	ErrorFormatV1 ErrorFormat = iota
+	// ErrorFormatV2 lists one object per failed field with its rule code,
+	// rule parameters and path:
+	// {"message": "Validation failed", "version": 2, "errors": [{"field": "email",
+	// "path": "email", "code": "email", "message": "...", "params": {...}}]}.
+	ErrorFormatV2
+)
+
+// ErrorFormatHeader is the request header clients send to pick an error
+// format ("v1" or "v2") regardless of Config.ErrorFormat. Error responses
+// echo the format they use in the same header.
+const ErrorFormatHeader = "X-Error-Format"
+
+// String returns the format name used in EffectiveConfig and ErrorFormatHeader.
+func (f ErrorFormat) String() string {
+	if f == ErrorFormatV2 {
+		return "v2"
+	}
+	return "v1"
+}
+
+// MarshalText encodes the format by name.
+func (f ErrorFormat) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText decodes a format name as accepted in ErrorFormatHeader.
+func (f *ErrorFormat) UnmarshalText(text []byte) error {
+	format, ok := parseErrorFormat(string(text))
+	if !ok {
+		return fmt.Errorf("formparser: unknown error format %q", text)
+	}
+	*f = format
+	return nil
+}
+
+// parseErrorFormat maps "v1"/"1" and "v2"/"2" to a format; "" is v1.
+func parseErrorFormat(name string) (ErrorFormat, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "v1", "1":
+		return ErrorFormatV1, true
+	case "v2", "2":
+		return ErrorFormatV2, true
+	}
+	return ErrorFormatV1, false
+}
+
+// errorFormat returns the format for the response to r: the request's
+// ErrorFormatHeader when it names a known format, else Config.ErrorFormat.
+func (cfg *Config) errorFormat(r *http.Request) ErrorFormat {
+	if r != nil && r.Header.Get(ErrorFormatHeader) != "" {
+		if f, ok := parseErrorFormat(r.Header.Get(ErrorFormatHeader)); ok {
+			return f
+		}
+	}
+	return cfg.ErrorFormat
+}
+
+// FieldErrorV2 is one entry of the "errors" list in ErrorFormatV2 responses.
+type FieldErrorV2 struct {
+	Field   string            `json:"field"`
+	Path    string            `json:"path"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Params  map[string]string `json:"params,omitempty"`
+}
+
+// errorDetail is what the validator reported about a field, kept for
+// ErrorFormatV2 and ModeDebug.
+type errorDetail struct {
+	code      string
+	param     string
+	path      string
+	namespace string // as the validator reports it, for ModeDebug
+}
+
+// recordErrorDetail keeps the rule, parameter and path of a validator error.
+func (res *ParseResult) recordErrorDetail(field string, ve validator.FieldError) {
+	if res.errorDetails == nil {
+		res.errorDetails = make(map[string]errorDetail)
+	}
+	path := ve.Namespace()
+	if _, rest, ok := strings.Cut(path, "."); ok {
+		path = rest // drop the root struct name
+	}
+	res.errorDetails[field] = errorDetail{code: ve.Tag(), param: ve.Param(), path: strings.ToLower(path), namespace: ve.StructNamespace()}
+}
+
+// fieldErrorsV2 builds the ErrorFormatV2 list for fieldErrors, sorted by
+// field. Errors the validator did not raise take their code from
+// res.ErrorCodes, or "invalid".
+func fieldErrorsV2(res *ParseResult, fieldErrors FieldErrors) []FieldErrorV2 {
+	errs := make([]FieldErrorV2, 0, len(fieldErrors))
+	for field, msg := range fieldErrors {
+		e := FieldErrorV2{Field: field, Path: field, Code: "invalid", Message: msg}
+		if d, ok := res.errorDetails[field]; ok {
+			e.Code, e.Path = d.code, d.path
+			if d.param != "" {
+				e.Params = map[string]string{"param": d.param}
+			}
+		} else if codes := res.ErrorCodes[field]; len(codes) > 0 {
+			e.Code = codes[0]
+		}
+		errs = append(errs, e)
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
@@ -0,0 +1,24 @@
+package formparser
+
+import (
+	"sort"
+	"strings"
+)
+
+// FieldErrors maps field names to human-readable messages. It is returned when
+// checks outside the validator (such as file introspection) reject a field.
+type FieldErrors map[string]string
+
+func (fe FieldErrors) Error() string {
+	fields := make([]string, 0, len(fe))
+	for field := range fe {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	msgs := make([]string, len(fields))
+	for i, field := range fields {
+		msgs[i] = field + ": " + fe[field]
+	}
+	return "field errors: " + strings.Join(msgs, "; ")
+}
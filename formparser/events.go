@@ -0,0 +1,269 @@
+package formparser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// EventType names a stage of the parse lifecycle.
+type EventType string
+
+const (
+	EventStarted          EventType = "started"           // the parse began
+	EventField            EventType = "field"             // a multipart text field was read
+	EventFileProgress     EventType = "file_progress"     // a chunk of a file part was read
+	EventFileDone         EventType = "file_done"         // a file part was fully read
+	EventValidationFailed EventType = "validation_failed" // the submission was rejected with field errors
+	EventFailed           EventType = "failed"            // the parse failed for any other reason
+	EventCompleted        EventType = "completed"         // the submission was accepted
+)
+
+// eventBufferSize is how many events a slow subscriber may fall behind by
+// before further events are dropped for it.
+const eventBufferSize = 64
+
+// ParseEvent is one step of a parse, keyed by the client's upload ID.
+// BytesRead and Total count request body bytes so Percent reflects overall
+// progress; Total and Percent are -1 when the request has no Content-Length.
+type ParseEvent struct {
+	UploadID  string    `json:"upload_id"`
+	Type      EventType `json:"type"`
+	Field     string    `json:"field,omitempty"`
+	Filename  string    `json:"filename,omitempty"`
+	BytesRead int64     `json:"bytes_read"`
+	Total     int64     `json:"total"`
+	Percent   int       `json:"percent"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// EventStream fans parse events out to subscribers by upload ID. Share one
+// stream between the upload handler (via Config.Events) and a progress
+// endpoint; the stream itself serves Server-Sent Events.
+type EventStream struct {
+	// Authorize decides whether r may follow the upload with the given ID,
+	// e.g. by checking that the session owns it. A non-nil error refuses
+	// the request: with a *RejectError its Status and Message are sent,
+	// otherwise 403. ServeHTTP refuses every request while Authorize is nil.
+	Authorize func(r *http.Request, uploadID string) error
+
+	mu   sync.Mutex
+	subs map[string]map[chan ParseEvent]struct{}
+}
+
+// NewEventStream returns an empty EventStream.
+func NewEventStream() *EventStream {
+	return &EventStream{subs: make(map[string]map[chan ParseEvent]struct{})}
+}
+
+// Subscribe returns the events of the upload with the given ID and a
+// function that ends the subscription. Events are delivered without
+// blocking the parse, so a subscriber that falls behind misses events.
+func (s *EventStream) Subscribe(uploadID string) (<-chan ParseEvent, func()) {
+	ch := make(chan ParseEvent, eventBufferSize)
+	s.mu.Lock()
+	if s.subs[uploadID] == nil {
+		s.subs[uploadID] = make(map[chan ParseEvent]struct{})
+	}
+	s.subs[uploadID][ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs[uploadID], ch)
+			if len(s.subs[uploadID]) == 0 {
+				delete(s.subs, uploadID)
+			}
+			s.mu.Unlock()
+		})
+	}
+}
+
+// publish delivers ev to every subscriber of its upload ID.
+func (s *EventStream) publish(ev ParseEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subs[ev.UploadID] {
+		select {
+		case ch <- ev:
+		default: // subscriber is behind; drop rather than stall the upload
+		}
+	}
+}
+
+// ServeHTTP streams the events of the upload named by the upload_id query
+// parameter as Server-Sent Events until the parse finishes or the client
+// goes away. Requests Authorize refuses get no events.
+func (s *EventStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	uploadID := r.URL.Query().Get("upload_id")
+	if uploadID == "" {
+		http.Error(w, "Missing upload_id", http.StatusBadRequest)
+		return
+	}
+	if !s.authorize(w, r, uploadID) {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	events, cancel := s.Subscribe(uploadID)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-events:
+			data, _ := json.Marshal(ev)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+			flusher.Flush()
+			if ev.Type.final() {
+				return
+			}
+		}
+	}
+}
+
+// authorize runs Authorize and writes the refusal when it fails.
+func (s *EventStream) authorize(w http.ResponseWriter, r *http.Request, uploadID string) bool {
+	err := errors.New("no Authorize func")
+	if s.Authorize != nil {
+		err = s.Authorize(r, uploadID)
+	}
+	if err == nil {
+		return true
+	}
+	status, msg := http.StatusForbidden, ""
+	var reject *RejectError
+	if errors.As(err, &reject) {
+		if reject.Status != 0 {
+			status = reject.Status
+		}
+		msg = reject.Message
+	}
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	http.Error(w, msg, status)
+	return false
+}
+
+// final reports whether no events follow t.
+func (t EventType) final() bool {
+	return t == EventCompleted || t == EventFailed || t == EventValidationFailed
+}
+
+// uploadID returns the ID events of r are published under: the UploadID
+// hook's result, else the X-Upload-ID header, else the upload_id query
+// parameter.
+func (cfg *Config) uploadID(r *http.Request) string {
+	if cfg.UploadID != nil {
+		return cfg.UploadID(r)
+	}
+	if id := r.Header.Get("X-Upload-ID"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("upload_id")
+}
+
+// eventEmitter publishes the events of one parse. A nil emitter discards
+// events, so the parse path calls it unconditionally.
+type eventEmitter struct {
+	stream   *EventStream
+	uploadID string
+	total    int64
+	read     int64 // request body bytes consumed so far
+}
+
+// newEventEmitter counts r's body bytes for progress events. It returns nil
+// when events are disabled or the request carries no upload ID.
+func (cfg *Config) newEventEmitter(r *http.Request) *eventEmitter {
+	if cfg.Events == nil {
+		return nil
+	}
+	id := cfg.uploadID(r)
+	if id == "" {
+		return nil
+	}
+	e := &eventEmitter{stream: cfg.Events, uploadID: id, total: r.ContentLength}
+	if r.Body != nil {
+		r.Body = &countingBody{ReadCloser: r.Body, n: &e.read}
+	}
+	return e
+}
+
+// emit stamps ev with the upload ID and body progress and publishes it.
+func (e *eventEmitter) emit(ev ParseEvent) {
+	if e == nil {
+		return
+	}
+	ev.UploadID = e.uploadID
+	ev.BytesRead, ev.Total, ev.Percent = e.read, -1, -1
+	if e.total > 0 {
+		ev.Total = e.total
+		ev.Percent = int(min(e.read*100/e.total, 100))
+	}
+	e.stream.publish(ev)
+}
+
+// finish publishes the terminal event for a parse that returned err. The
+// event carries a fixed message; the details are in the response to the
+// upload itself, not broadcast to subscribers.
+func (e *eventEmitter) finish(err error) {
+	switch {
+	case err == nil:
+		e.emit(ParseEvent{Type: EventCompleted})
+	case isValidationError(err):
+		e.emit(ParseEvent{Type: EventValidationFailed, Message: "Validation failed"})
+	default:
+		e.emit(ParseEvent{Type: EventFailed, Message: "Request failed"})
+	}
+}
+
+// fileReader wraps a file part so each chunk read emits a progress event.
+func (e *eventEmitter) fileReader(part io.Reader, field, filename string) io.Reader {
+	if e == nil {
+		return part
+	}
+	return &progressReader{Reader: part, emit: func() {
+		e.emit(ParseEvent{Type: EventFileProgress, Field: field, Filename: filename})
+	}}
+}
+
+// countingBody tallies the bytes read from a request body.
+type countingBody struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	*b.n += int64(n)
+	return n, err
+}
+
+// progressReader calls emit after every read that returned data.
+type progressReader struct {
+	io.Reader
+	emit func()
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.Reader.Read(b)
+	if n > 0 {
+		p.emit()
+	}
+	return n, err
+}
@@ -0,0 +1,67 @@
+package formparser
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// RejectError lets a BeforeBody hook choose the status and message of its
+// rejection, e.g. 401 for a failed auth check or 413 for an over-quota upload.
+// Throttling hooks set RateLimit, typically with status 429, to send
+// Retry-After and RateLimit-* headers from their limiter's state.
+type RejectError struct {
+	Status    int
+	Message   string
+	RateLimit *RateLimit
+}
+
+func (e *RejectError) Error() string {
+	return e.Message
+}
+
+// checkBeforeBody runs the BeforeBody hook before any byte of the body is
+// read. Go's server only sends "100 Continue" on the first body read, so a
+// client that sent "Expect: 100-continue" gets the rejection without ever
+// transmitting its payload.
+func (cfg *Config) checkBeforeBody(w http.ResponseWriter, r *http.Request) error {
+	if cfg.BeforeBody == nil {
+		return nil
+	}
+	err := cfg.BeforeBody(r)
+	if err == nil {
+		return nil
+	}
+
+	status := http.StatusBadRequest
+	if expectsContinue(r) {
+		status = http.StatusExpectationFailed
+	}
+	// Only a RejectError's Message is meant for the client; other hook
+	// errors get the status text.
+	msg := ""
+	var reject *RejectError
+	if errors.As(err, &reject) {
+		if reject.Status != 0 {
+			status = reject.Status
+		}
+		msg = reject.Message
+		if reject.RateLimit != nil {
+			reject.RateLimit.SetHeaders(w.Header())
+		}
+	}
+	// Do not keep the connection open for a body the client should not send.
+	if expectsContinue(r) {
+		w.Header().Set("Connection", "close")
+	}
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	cfg.httpError(w, msg, status, err)
+	return err
+}
+
+// expectsContinue reports whether the client is waiting for "100 Continue".
+func expectsContinue(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Expect"), "100-continue")
+}
@@ -0,0 +1,97 @@
+package formparser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ContentTypeFallback selects how a request body without a Content-Type
+// header is parsed. curl scripts and embedded clients often omit it.
+type ContentTypeFallback int
+
+const (
+	// FallbackReject answers 415 Unsupported Content-Type, as for any
+	// unknown type.
+	FallbackReject ContentTypeFallback = iota
+	// FallbackSniff inspects the start of the body: '{' or '[' is parsed as
+	// JSON, '<' as XML and key=value pairs as url-encoded; anything else is
+	// rejected.
+	FallbackSniff
+	// FallbackURLEncoded parses the body as application/x-www-form-urlencoded,
+	// what curl -d sends by default.
+	FallbackURLEncoded
+	// FallbackJSON parses the body as application/json.
+	FallbackJSON
+)
+
+// String returns the fallback's name.
+func (f ContentTypeFallback) String() string {
+	switch f {
+	case FallbackReject:
+		return "reject"
+	case FallbackSniff:
+		return "sniff"
+	case FallbackURLEncoded:
+		return "urlencoded"
+	case FallbackJSON:
+		return "json"
+	default:
+		return fmt.Sprintf("ContentTypeFallback(%d)", int(f))
+	}
+}
+
+// sniffLimit is how much of the body FallbackSniff looks at.
+const sniffLimit = 512
+
+// applyContentTypeFallback sets the Content-Type of a request that has none
+// according to MissingContentType, leaving it empty to be rejected.
+func (cfg *Config) applyContentTypeFallback(r *http.Request) {
+	if r.Header.Get("Content-Type") != "" {
+		return
+	}
+	var contentType string
+	switch cfg.MissingContentType {
+	case FallbackURLEncoded:
+		contentType = "application/x-www-form-urlencoded"
+	case FallbackJSON:
+		contentType = "application/json"
+	case FallbackSniff:
+		if r.Body == nil {
+			return
+		}
+		br := bufio.NewReaderSize(r.Body, sniffLimit)
+		head, _ := br.Peek(sniffLimit)
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{br, r.Body}
+		contentType = sniffContentType(head)
+	}
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+}
+
+// sniffContentType guesses the type of a body starting with head, or
+// returns "".
+func sniffContentType(head []byte) string {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), " \t\r\n")
+	switch {
+	case len(trimmed) == 0:
+		return ""
+	case trimmed[0] == '{' || trimmed[0] == '[':
+		return "application/json"
+	case trimmed[0] == '<':
+		return "application/xml"
+	}
+	pair, _, _ := strings.Cut(string(trimmed), "&")
+	key, _, ok := strings.Cut(pair, "=")
+	if ok && key != "" && !strings.ContainsAny(key, " \t\r\n") {
+		return "application/x-www-form-urlencoded"
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package formparser
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// FileAction is what an edit form asks for a file field it already has a
+// file for.
+type FileAction int
+
+const (
+	// FileKeep keeps the current file: the form sent neither a new file
+	// nor a delete flag. This is the zero value.
+	FileKeep FileAction = iota
+	// FileDelete removes the current file: the form sent a truthy
+	// "<field>_delete" flag and no new file.
+	FileDelete
+	// FileReplace replaces the current file with the one in Files. A new
+	// file wins over a delete flag.
+	FileReplace
+)
+
+// fileDeleteSuffix marks the flag field asking for a file field's deletion.
+const fileDeleteSuffix = "_delete"
+
+// String returns the action's name.
+func (a FileAction) String() string {
+	switch a {
+	case FileKeep:
+		return "keep"
+	case FileDelete:
+		return "delete"
+	case FileReplace:
+		return "replace"
+	default:
+		return fmt.Sprintf("FileAction(%d)", int(a))
+	}
+}
+
+// MarshalText lets actions appear by name in JSON.
+func (a FileAction) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// FileAction returns the action requested for the file field with the given
+// form key. Fields the request said nothing about are FileKeep.
+func (res *ParseResult) FileAction(field string) FileAction {
+	return res.FileActions[field]
+}
+
+// setFileActions records FileReplace for every uploaded file and FileDelete
+// for every other field named by a truthy "<field>_delete" value, such as a
+// checked "avatar_delete" checkbox. Empty uploads count as no file, so an
+// untouched file input keeps the current file.
+func (res *ParseResult) setFileActions(values url.Values) {
+	actions := make(map[string]FileAction)
+	for key, vals := range values {
+		field, ok := strings.CutSuffix(key, fileDeleteSuffix)
+		if ok && field != "" && len(vals) > 0 && isTruthy(vals[len(vals)-1]) {
+			actions[field] = FileDelete
+		}
+	}
+	for field := range res.Files {
+		actions[field] = FileReplace
+	}
+	if len(actions) > 0 {
+		res.FileActions = actions
+	}
+}
+
+// isTruthy reports whether a submitted flag is set: anything
+// strconv.ParseBool accepts as true, or a checkbox's "on" or "yes".
+func isTruthy(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	return value == "on" || value == "yes"
+}
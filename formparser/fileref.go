@@ -0,0 +1,37 @@
+package formparser
+
+import "encoding/json"
+
+// FileRef is the serializable description of an uploaded file. It never
+// carries the raw content, so it is safe to log or return to clients.
+type FileRef struct {
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Hash        string    `json:"hash"`
+	StorageKey  string    `json:"storage_key,omitempty"`
+	Variants    []FileRef `json:"variants,omitempty"`
+}
+
+// Ref returns the content-free reference for f.
+func (f *UploadedFile) Ref() FileRef {
+	ref := FileRef{
+		Filename:    f.Filename,
+		ContentType: f.ContentType,
+		Size:        f.Size,
+		Hash:        f.Hash,
+		StorageKey:  f.StorageKey,
+	}
+	if f.Content != nil {
+		ref.Size = int64(len(f.Content))
+	}
+	for _, v := range f.Variants {
+		ref.Variants = append(ref.Variants, v.Ref())
+	}
+	return ref
+}
+
+// MarshalJSON encodes f as its FileRef, leaving out the raw content.
+func (f *UploadedFile) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.Ref())
+}
@@ -0,0 +1,150 @@
+package formparser
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fileRule holds the constraints of a field tagged
+// `file:"required,accept=image/png image/jpeg,maxsize=2MB,maxcount=3"`.
+type fileRule struct {
+	name     string   // field error key
+	key      string   // form key the files are uploaded under
+	json     string   // json name data URI uploads are recorded under
+	required bool     // at least one non-empty file must be uploaded
+	accept   []string // allowed media types, "image/*" families included; nil defers to AllowedMIMETypes
+	maxSize  int64    // max bytes per file, capped by MaxFileSize unless AllowFileTagMaxSize; 0 defers to MaxFileSize
+	size     string   // maxSize as written in the tag, for messages
+	maxCount int      // max files; 0 = no limit
+}
+
+// fileRulesCache maps reflect.Type to the map[string]fileRule built for it.
+var fileRulesCache sync.Map
+
+// fileRules returns the rules of the top-level `file`-tagged fields of dst
+// by form key. Tags that fail to parse are skipped; CheckStruct reports
+// them.
+func fileRules(dst interface{}) map[string]fileRule {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := fileRulesCache.Load(t); ok {
+		return cached.(map[string]fileRule)
+	}
+
+	rules := make(map[string]fileRule)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("file")
+		if !ok || !f.IsExported() {
+			continue
+		}
+		rule, err := parseFileRule(tag)
+		if err != nil {
+			continue
+		}
+		rule.name, rule.key, rule.json = strings.ToLower(f.Name), formKey(f), tagName(f.Tag.Get("json"))
+		if rule.json == "" {
+			rule.json = f.Name
+		}
+		rules[rule.key] = rule
+	}
+
+	fileRulesCache.Store(t, rules)
+	return rules
+}
+
+// parseFileRule parses the options of a `file` tag.
+func parseFileRule(tag string) (fileRule, error) {
+	var rule fileRule
+	for _, opt := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "":
+		case "required":
+			rule.required = true
+		case "accept":
+			rule.accept = strings.Fields(value)
+			if len(rule.accept) == 0 {
+				return rule, fmt.Errorf("accept needs media types")
+			}
+		case "maxsize":
+			n, err := ParseSize(value)
+			if err != nil || n == 0 {
+				return rule, fmt.Errorf("invalid maxsize %q", value)
+			}
+			rule.maxSize, rule.size = n, value
+		case "maxcount":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return rule, fmt.Errorf("invalid maxcount %q", value)
+			}
+			rule.maxCount = n
+		default:
+			return rule, fmt.Errorf("unknown option %q", key)
+		}
+	}
+	return rule, nil
+}
+
+// fileRuleError reports a file breaking the constraint option ("accept",
+// "maxsize" or "maxcount") of its field's `file` tag.
+type fileRuleError struct {
+	constraint string
+}
+
+func (e *fileRuleError) Error() string {
+	return "file breaks its " + e.constraint + " rule"
+}
+
+// fileRuleMessage returns the field error for rule's field breaking its
+// constraint option: a FieldErrorMessages entry when configured, a default
+// message otherwise.
+func (cfg *Config) fileRuleMessage(r *http.Request, rule fileRule, constraint string) string {
+	if msg, ok := cfg.lookupMessage(rule.name, constraint, requestLang(r)); ok {
+		return msg
+	}
+	switch constraint {
+	case "accept":
+		return fmt.Sprintf("%s must be one of %s", rule.name, strings.Join(rule.accept, ", "))
+	case "maxsize":
+		return fmt.Sprintf("%s must be at most %s", rule.name, rule.size)
+	default:
+		return fmt.Sprintf("%s accepts at most %d files", rule.name, rule.maxCount)
+	}
+}
+
+// fileMaxSize returns the hard size limit for a file of rule's field (the
+// zero rule for untagged fields): MaxFileSize, or a tag's maxsize when it is
+// lower or AllowFileTagMaxSize lets it be higher.
+func (cfg *Config) fileMaxSize(rule fileRule) int64 {
+	maxFileSize := cfg.maxFileSize()
+	if rule.maxSize > 0 && (rule.maxSize < maxFileSize || cfg.AllowFileTagMaxSize) {
+		return rule.maxSize
+	}
+	return maxFileSize
+}
+
+// checkRequiredFiles reports `file:"required"` fields without an upload.
+func (cfg *Config) checkRequiredFiles(r *http.Request, dst interface{}, res *ParseResult) FieldErrors {
+	fieldErrors := make(FieldErrors)
+	for _, rule := range fileRules(dst) {
+		if !rule.required || res.Files[rule.key] != nil || res.Files[rule.json] != nil {
+			continue
+		}
+		if msg, ok := cfg.lookupMessage(rule.name, "required", requestLang(r)); ok {
+			fieldErrors[rule.name] = msg
+		} else {
+			fieldErrors[rule.name] = fmt.Sprintf("%s is required", rule.name)
+		}
+	}
+	return fieldErrors
+}
@@ -3,171 +3,893 @@ package formparser
 import (
 	"bytes"
 	"crypto/sha256"
-	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/go-playground/form/v4"
 	"github.com/go-playground/validator/v10"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
 )
 
+// defaultMaxFileSize applies when Config.MaxFileSize is unset.
+const defaultMaxFileSize = 5 << 20 // 5MB
+
+// AllowAllMIMETypes in Config.AllowedMIMETypes accepts uploads of any type.
+const AllowAllMIMETypes = "*/*"
+
 // UploadedFile holds metadata and content of a parsed uploaded file.
 type UploadedFile struct {
 	Filename    string
 	ContentType string
 	Content     []byte
+	Size        int64 // Size in bytes; the only size source when Content is nil
 	Hash        string
+	PDF         *PDFInfo        // Set when a PDFRule applies to the field
+	Media       *MediaInfo      // Set when a MediaRule applies to the field
+	Charset     string          // Set when a TextRule applies to the field; the charset converted from
+	Original    *UploadedFile   // Set on converted files; the file as uploaded
+	Variants    []*UploadedFile // Generated variants such as thumbnails
+	StorageKey  string          // Key under which the FileStore saved the file
+	Existing    bool            // Reused from the DedupStore; Content is nil
 }
 
 // Config defines the shared parser config and context.
 type Config struct {
-	Decoder            *form.Decoder
-	Validator          *validator.Validate
-	FieldErrorMessages map[string]string
-	Files              map[string]*UploadedFile
-	AllowedMIMETypes   []string // Optional: user-defined MIME type whitelist
-	MaxFileSize        int64    // Optional: max size per file in bytes (default 5MB)
+	Decoder                 *form.Decoder
+	Validator               *validator.Validate
+	ValidatorResolver       func(r *http.Request) *validator.Validate // Optional: per-request validator, e.g. with a tenant's rules; nil falls back to Validator
+	FieldErrorMessages      map[string]string
+	Messages                MessageProvider   // Optional: dynamic message catalog, consulted before FieldErrorMessages
+	MessageTemplates        map[string]string // Optional: default message templates by rule tag, e.g. {"lt": "{field} must be below {value}"}
+	Files                   map[string]*UploadedFile
+	AllowedMIMETypes        []string                     // Optional: upload MIME type whitelist; "image/*" allows a family, AllowAllMIMETypes anything (none = no uploads)
+	MaxFileSize             int64                        // Optional: max size per file in bytes (default 5MB), e.g. MustParseSize("10MB")
+	MaxTextBodySize         int64                        // Optional: max text/plain body size in bytes (default 64KB)
+	MaxDecompressedSize     int64                        // Optional: max size of a Content-Encoding decoded body in bytes (default 32MB)
+	Decompressors           map[string]Decompressor      // Optional: extra or replacement Content-Encoding decoders, e.g. "compress"
+	MinReadRate             int64                        // Optional: min average multipart or compressed body bytes/sec after a 1s grace (0 = no limit)
+	PartIdleTimeout         time.Duration                // Optional: max wait for more multipart or compressed body data (0 = no limit)
+	MaxUploadBytesPerSecond int64                        // Optional: throttle multipart body reads to this many bytes/sec per request (0 = unthrottled)
+	MultipartJSONField      string                       // Optional: multipart part whose application/json content is decoded into dst before the other fields (default "data"; "-" disables)
+	EmptyFileRequired       bool                         // Optional: report empty file parts as missing files instead of skipping them
+	AllowFileTagMaxSize     bool                         // Optional: let file:"maxsize=..." tags exceed MaxFileSize, which otherwise caps them
+	CopyBufferSize          int                          // Optional: chunk size used when reading file parts (default 32KB)
+	TagMode                 TagMode                      // Optional: how conflicting json/form tags are reconciled
+	Mode                    Mode                         // Optional: ModeDebug adds internal error details to responses (default ModeRelease)
+	URLEncoding             URLEncodingMode              // Optional: strict or lenient url-encoded parsing (default: net/http behavior)
+	MissingContentType      ContentTypeFallback          // Optional: how bodies without a Content-Type are parsed (default FallbackReject)
+	TextLimitPolicy         TextLimitPolicy              // Optional: what `maxbytes` tags do with oversized text (default TextLimitReject)
+	Logger                  *slog.Logger                 // Optional: receives lenient-mode corrections (default slog.Default)
+	MIMEPolicies            map[string]MIMEPolicy        // Optional: per-field handling of extension/declared/sniffed type mismatches
+	PDFRules                map[string]PDFRule           // Optional: per-field PDF introspection limits
+	MediaProber             MediaProber                  // Optional: extracts audio/video metadata (e.g. FFProbe)
+	MediaRules              map[string]MediaRule         // Optional: per-field audio/video limits, requires MediaProber
+	TextRules               map[string]TextRule          // Optional: per-field UTF-8 normalization of text uploads
+	ErrorFormat             ErrorFormat                  // Optional: validation error envelope (default ErrorFormatV1); clients may override with X-Error-Format
+	JSONAPI                 bool                         // Optional: unwrap application/vnd.api+json documents and answer with JSON:API error objects
+	ErrorGzipThreshold      int                          // Optional: gzip validation error responses of at least this many bytes when accepted (0 = never)
+	Converters              map[string]Converter         // Optional: transcoders keyed by uploaded MIME type
+	Thumbnails              map[string][]ThumbnailSize   // Optional: per-field image variants to generate
+	MaxImagePixels          int                          // Optional: max width×height of an image decoded for Thumbnails (default 50 megapixels)
+	FileStore               FileStore                    // Optional: persists uploads and their variants
+	Breakers                map[string]*CircuitBreaker   // Optional: per-hook circuit breakers, keyed FileStore, DedupStore, MediaProber, BreachChecker, AddressNormalizer, Converters or Outbox
+	KeyFunc                 KeyFunc                      // Optional: storage key strategy (default DefaultKey)
+	FileURL                 func(key string) string      // Optional: maps storage keys to public URLs in RespondCreated
+	QueryCacheSize          int                          // Optional: LRU size for ParseQuery results (0 = no caching)
+	NumberLocale            string                       // Optional: language for float fields ("de", "fr", NumberLocaleAuto)
+	OnField                 func(name, value string)     // Optional: called for each multipart text field as it is read
+	OnFileStart             func(h FileHeader) bool      // Optional: called before a file part is read; false rejects it unread
+	UploadTokenSecret       []byte                       // Optional: HMAC key for SignUploadToken/VerifyUploadToken
+	UploadTokenFields       map[string]string            // Optional: file field -> sibling field carrying its upload token
+	FormStateSecret         []byte                       // Optional: HMAC key for SignFormState and `state`-tagged fields
+	DedupStore              DedupStore                   // Optional: reuse stored files whose X-Content-SHA256 is already known
+	AddressNormalizer       AddressNormalizer            // Optional: normalizes/geocodes `address:"<group>,<part>"` field groups
+	PasswordPolicies        map[string]PasswordPolicy    // Optional: password checks keyed by lower-cased field name
+	BreachChecker           BreachChecker                // Optional: k-anonymity breached-password lookup (e.g. &HIBPClient{})
+	Enricher                Enricher                     // Optional: fills `ctx`-tagged fields from the request context
+	Outbox                  Outbox                       // Optional: persists each accepted submission before the parse returns, for reprocessing
+	VerifyTrailerChecksum   bool                         // Optional: check Content-Digest/Repr-Digest/X-Content-SHA256 trailers
+	MaxDecodeDepth          int                          // Optional: max nesting of JSON bodies and form keys (0 = unlimited)
+	MaxJSONTokens           int                          // Optional: max tokens (delimiters, keys, values) in a JSON body (0 = unlimited)
+	Clock                   func() time.Time             // Optional: time source for upload token expiry, breakers and submission timestamps (default time.Now); body throttling and read timeouts always use the wall clock
+	Random                  io.Reader                    // Optional: entropy source for Outbox submission IDs (default crypto/rand); pass it to NewULIDKey for storage keys
+	AsyncWorkers            int                          // Optional: ParseAsync worker goroutines (default GOMAXPROCS)
+	AsyncQueueSize          int                          // Optional: ParseAsync jobs that may wait for a worker (default AsyncWorkers)
+	AsyncQueueTimeout       time.Duration                // Optional: how long ParseAsync waits for queue space (default: fail fast)
+	AsyncRetryAfter         time.Duration                // Optional: Retry-After sent when the ParseAsync queue is full (default 1s)
+	BeforeBody              func(r *http.Request) error  // Optional: pre-checks (auth, quota, declared size) run before the body is read
+	Events                  *EventStream                 // Optional: publishes parse lifecycle events for progress endpoints
+	UploadID                func(r *http.Request) string // Optional: upload ID for Events (default X-Upload-ID header, then upload_id query)
+	Merge                   bool                         // Optional: decode onto dst's current values (e.g. loaded for an edit form) and record submitted fields
+	DecodeOnly              bool                         // Optional: skip validation; Validator may then be nil
+	Result                  *ParseResult                 // Details of the most recent parse
+
+	queryCacheOnce    sync.Once
+	queryCache        *queryCache
+	dstPools          sync.Map // reflect.Type -> *dstPool
+	computers         sync.Map // reflect.Type -> Computer
+	validations       sync.Map // tag -> struct{}
+	structValidations sync.Map // reflect.Type -> struct{}
+	parsers           sync.Map // media type -> ParserFunc
+	asyncOnce         sync.Once
+	asyncPool         *asyncPool
+	stats             statsCounters
 }
 
-// ParseFormBasedOnContentType routes to JSON, URL-encoded, or multipart parser.
+// ParseFormBasedOnContentType routes to JSON, XML, YAML, MessagePack, TOML, CBOR, protobuf, CSV, raw binary, plain text, URL-encoded, multipart, or email (message/rfc822) parser, or one added with RegisterParser.
 func (cfg *Config) ParseFormBasedOnContentType(w http.ResponseWriter, r *http.Request, dst interface{}) error {
-	contentType := r.Header.Get("Content-Type")
+	res, err := cfg.parse(w, r, dst)
+	cfg.Result, cfg.Files = res, res.Files
+	return err
+}
+
+// parse runs a full parse into a fresh ParseResult. It does not touch the
+// per-request Files and Result fields, so it is safe for concurrent use.
+func (cfg *Config) parse(w http.ResponseWriter, r *http.Request, dst interface{}) (*ParseResult, error) {
+	res := &ParseResult{}
+	sw := &statusWriter{ResponseWriter: w}
+	var read int64
+	if r.Body != nil {
+		r.Body = &countingBody{ReadCloser: r.Body, n: &read}
+	}
+	err := cfg.parseRequest(sw, r, dst, res)
+	cfg.stats.bytesRead.Add(read)
+	cfg.stats.recordFailure(err, sw.status)
+	return res, err
+}
+
+// parseRequest runs the pre-checks and the parse, publishing its events,
+// and records an accepted submission in the Outbox.
+func (cfg *Config) parseRequest(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	if err := cfg.checkBeforeBody(w, r); err != nil {
+		return err
+	}
+	res.events = cfg.newEventEmitter(r)
+	res.events.emit(ParseEvent{Type: EventStarted})
+	err := cfg.parseChecked(w, r, dst, res)
+	if err == nil {
+		err = cfg.saveSubmission(w, r, dst, res)
+	}
+	res.events.finish(err)
+	return err
+}
+
+// parseChecked parses the body and then verifies its trailer checksum.
+// Uploads are only stored once the checksum matched.
+func (cfg *Config) parseChecked(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	checksum := cfg.wrapChecksumBody(r)
+	res.deferFiles = checksum != nil
+	if err := cfg.decodeContentEncoding(w, r); err != nil {
+		return err
+	}
+	cfg.applyContentTypeFallback(r)
+	if err := cfg.parseBody(w, r, dst, res); err != nil {
+		return err
+	}
+	if checksum != nil {
+		if err := verifyTrailerChecksum(r, checksum); err != nil {
+			cfg.httpError(w, "Checksum mismatch", http.StatusBadRequest, err)
+			return err
+		}
+		if err := cfg.flushFiles(r.Context(), res); err != nil {
+			cfg.httpError(w, "Error storing file", http.StatusInternalServerError, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// parseBody dispatches to the parser for the request's Content-Type.
+func (cfg *Config) parseBody(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	mediaType, params := parseMediaType(r.Header.Get("Content-Type"))
+	res.MediaType, res.MediaParams = mediaType, params
+	if fn, ok := cfg.registeredParser(mediaType); ok {
+		cfg.stats.parses[kindCustom].Add(1)
+		return cfg.parseRegistered(w, r, dst, res, fn)
+	}
 	switch {
-	case strings.HasPrefix(contentType, "multipart/form-data"):
-		return cfg.parseMultipart(w, r, dst)
-	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
-		return cfg.parseURLEncoded(w, r, dst)
-	case strings.HasPrefix(contentType, "application/json"):
-		return cfg.parseJSON(w, r, dst)
+	case mediaType == "multipart/form-data":
+		cfg.stats.parses[kindMultipart].Add(1)
+		return cfg.parseMultipart(w, r, dst, res)
+	case mediaType == "multipart/mixed", mediaType == "multipart/related":
+		cfg.stats.parses[kindMultipart].Add(1)
+		return cfg.parseMixed(w, r, dst, res)
+	case mediaType == "message/rfc822":
+		cfg.stats.parses[kindEmail].Add(1)
+		return cfg.parseEmail(w, r, dst, res)
+	case mediaType == "application/x-www-form-urlencoded":
+		cfg.stats.parses[kindURLEncoded].Add(1)
+		return cfg.parseURLEncoded(w, r, dst, res)
+	case mediaType == "application/json", hasStructuredSuffix(mediaType, "json"):
+		cfg.stats.parses[kindJSON].Add(1)
+		if cfg.JSONAPI && mediaType == jsonAPIMediaType {
+			return cfg.parseJSONAPI(w, r, dst, res)
+		}
+		return cfg.parseJSON(w, r, dst, res)
+	case mediaType == "application/xml", mediaType == "text/xml", hasStructuredSuffix(mediaType, "xml"):
+		cfg.stats.parses[kindXML].Add(1)
+		return cfg.parseXML(w, r, dst, res)
+	case mediaType == "application/yaml", mediaType == "application/x-yaml", hasStructuredSuffix(mediaType, "yaml"):
+		cfg.stats.parses[kindYAML].Add(1)
+		return cfg.parseYAML(w, r, dst, res)
+	case mediaType == "application/msgpack", mediaType == "application/x-msgpack":
+		cfg.stats.parses[kindMsgpack].Add(1)
+		return cfg.parseMsgpack(w, r, dst, res)
+	case mediaType == "application/cbor", hasStructuredSuffix(mediaType, "cbor"):
+		cfg.stats.parses[kindCBOR].Add(1)
+		return cfg.parseCBOR(w, r, dst, res)
+	case mediaType == "application/toml":
+		cfg.stats.parses[kindTOML].Add(1)
+		return cfg.parseTOML(w, r, dst, res)
+	case mediaType == "application/octet-stream":
+		cfg.stats.parses[kindOctetStream].Add(1)
+		return cfg.parseOctetStream(w, r, dst, res)
+	case mediaType == "text/plain":
+		cfg.stats.parses[kindText].Add(1)
+		return cfg.parseText(w, r, dst, res)
+	case mediaType == "text/csv":
+		cfg.stats.parses[kindCSV].Add(1)
+		return cfg.parseCSV(w, r, dst, res)
+	case mediaType == "application/x-protobuf", mediaType == "application/protobuf":
+		cfg.stats.parses[kindProtobuf].Add(1)
+		return cfg.parseProtobuf(w, r, dst, res)
 	default:
+		cfg.stats.parses[kindUnsupported].Add(1)
 		http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
 		return errors.New("unsupported content type")
 	}
 }
 
-// parseJSON handles JSON payload.
-func (cfg *Config) parseJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
-	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
-		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+// parseJSON handles JSON payload. A pointer to a slice of structs takes a
+// top-level array whose elements are validated one by one.
+func (cfg *Config) parseJSON(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	body, err := cfg.mergeJSON(dst, cfg.limitJSON(r.Body), res)
+	if err != nil {
+		return cfg.jsonFailed(w, err)
+	}
+	body, files, fileErrors, err := cfg.extractDataURIs(w, r, dst, body, res)
+	if err != nil {
+		return err
+	}
+	if err := cfg.decodeJSONBody(body, dst); err != nil {
+		return cfg.jsonFailed(w, err)
+	}
+	setFiles(dst, files)
+	if isStructSlice(dst) {
+		return cfg.validateItems(w, r, dst, res)
+	}
+	return cfg.validateAndRespond(w, r, dst, res, fileErrors)
+}
+
+// jsonFailed responds to a JSON document that could not be decoded.
+func (cfg *Config) jsonFailed(w http.ResponseWriter, err error) error {
+	var depthErr *MaxDepthError
+	var tokensErr *MaxTokensError
+	switch {
+	case errors.As(err, &depthErr):
+		http.Error(w, "JSON body nested too deeply", http.StatusBadRequest)
+	case errors.As(err, &tokensErr):
+		http.Error(w, "JSON body has too many elements", http.StatusBadRequest)
+	default:
+		cfg.httpError(w, "Invalid JSON body", http.StatusBadRequest, err)
+	}
+	return err
+}
+
+// parseXML handles XML payload.
+func (cfg *Config) parseXML(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	if err := xml.NewDecoder(r.Body).Decode(dst); err != nil {
+		cfg.httpError(w, "Invalid XML body", http.StatusBadRequest, err)
+		return err
+	}
+	return cfg.validateAndRespond(w, r, dst, res, nil)
+}
+
+// parseYAML handles YAML payload.
+func (cfg *Config) parseYAML(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	if err := yaml.NewDecoder(r.Body).Decode(dst); err != nil {
+		cfg.httpError(w, "Invalid YAML body", http.StatusBadRequest, err)
+		return err
+	}
+	return cfg.validateAndRespond(w, r, dst, res, nil)
+}
+
+// parseMsgpack handles MessagePack payload. Fields are matched by their
+// `msgpack` tag, falling back to the `json` tag.
+func (cfg *Config) parseMsgpack(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	dec := msgpack.NewDecoder(r.Body)
+	dec.SetCustomStructTag("json")
+	if err := dec.Decode(dst); err != nil {
+		cfg.httpError(w, "Invalid MessagePack body", http.StatusBadRequest, err)
+		return err
+	}
+	return cfg.validateAndRespond(w, r, dst, res, nil)
+}
+
+// parseTOML handles TOML payload. Fields are matched by their `toml` tag,
+// else case-insensitively by name.
+func (cfg *Config) parseTOML(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	if err := toml.NewDecoder(r.Body).Decode(dst); err != nil {
+		cfg.httpError(w, "Invalid TOML body", http.StatusBadRequest, err)
+		return err
+	}
+	return cfg.validateAndRespond(w, r, dst, res, nil)
+}
+
+// parseCBOR handles CBOR payload. Fields are matched by their `cbor` tag,
+// falling back to the `json` tag.
+func (cfg *Config) parseCBOR(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	if err := cbor.NewDecoder(r.Body).Decode(dst); err != nil {
+		cfg.httpError(w, "Invalid CBOR body", http.StatusBadRequest, err)
+		return err
+	}
+	return cfg.validateAndRespond(w, r, dst, res, nil)
+}
+
+// parseProtobuf handles protobuf payload for destinations generated by
+// protoc-gen-go. Validator tags on the generated struct still apply.
+func (cfg *Config) parseProtobuf(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	msg, ok := dst.(proto.Message)
+	if !ok {
+		http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
+		return errors.New("formparser: protobuf body requires a proto.Message destination")
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		cfg.httpError(w, "Error reading body", http.StatusBadRequest, err)
 		return err
 	}
-	return cfg.validateAndRespond(w, dst)
+	if err := proto.Unmarshal(body, msg); err != nil {
+		cfg.httpError(w, "Invalid protobuf body", http.StatusBadRequest, err)
+		return err
+	}
+	return cfg.validateAndRespond(w, r, dst, res, nil)
 }
 
 // parseURLEncoded handles application/x-www-form-urlencoded data.
-func (cfg *Config) parseURLEncoded(w http.ResponseWriter, r *http.Request, dst interface{}) error {
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Can't parse form", http.StatusBadRequest)
+func (cfg *Config) parseURLEncoded(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	values, err := cfg.readPostForm(r)
+	if err != nil {
+		cfg.httpError(w, "Can't parse form", http.StatusBadRequest, err)
 		return err
 	}
-	_ = cfg.Decoder.Decode(dst, r.PostForm)
-	return cfg.validateAndRespond(w, dst)
+	dec, err := charsetDecoder(formCharset(r.Header.Get("Content-Type"), values))
+	if err != nil {
+		http.Error(w, "Unsupported charset", http.StatusUnsupportedMediaType)
+		return err
+	}
+	if dec != nil {
+		if err := transcodeValues(values, dec, nil); err != nil {
+			cfg.httpError(w, "Can't parse form", http.StatusBadRequest, err)
+			return err
+		}
+	}
+	res.Values = copyValues(values)
+	res.setFileActions(values)
+	fieldErrors := cfg.prepareValues(r, dst, values)
+	if err := cfg.decodeValues(dst, values, res, fieldErrors); err != nil {
+		cfg.httpError(w, "Form nested too deeply", http.StatusBadRequest, err)
+		return err
+	}
+	return cfg.validateAndRespond(w, r, dst, res, fieldErrors)
 }
 
 // parseMultipart handles multipart/form-data and stores uploaded files.
-func (cfg *Config) parseMultipart(w http.ResponseWriter, r *http.Request, dst interface{}) error {
-	mr, err := r.MultipartReader()
+func (cfg *Config) parseMultipart(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	res.fileRules = fileRules(dst)
+	values, fileErrors, err := cfg.readMultipart(w, r, res, nil)
 	if err != nil {
-		http.Error(w, "Can't parse multipart", http.StatusBadRequest)
 		return err
 	}
+	res.Values = copyValues(values)
+	for field := range res.Files {
+		delete(res.Values, field) // file fields hold the file's hash
+	}
+	res.setFileActions(values)
+	if res.jsonPayload != nil {
+		if err := cfg.decodeJSONBody(cfg.limitJSON(bytes.NewReader(res.jsonPayload)), dst); err != nil {
+			return cfg.jsonFailed(w, err)
+		}
+	}
+	takeUploadValues(dst, values)
+	for field, msg := range cfg.prepareValues(r, dst, values) {
+		fileErrors[field] = msg
+	}
+	if err := cfg.decodeValues(dst, values, res, fileErrors); err != nil {
+		cfg.httpError(w, "Form nested too deeply", http.StatusBadRequest, err)
+		return err
+	}
+	setUploads(dst, res)
+	return cfg.validateAndRespond(w, r, dst, res, fileErrors)
+}
 
-	values := make(url.Values)
-	cfg.Files = make(map[string]*UploadedFile)
-
-	if cfg.MaxFileSize == 0 {
-		cfg.MaxFileSize = 5 << 20 // default 5MB
+// readMultipart reads every part of a multipart body, collecting text parts
+// into values and checking, processing and storing file parts into
+// res.Files. fileField, when set, names the field a file part is recorded
+// under; it sees the text parts read so far and may reject the part. On
+// error the response has been written.
+func (cfg *Config) readMultipart(w http.ResponseWriter, r *http.Request, res *ParseResult, fileField func(values url.Values, formName string) (string, error)) (url.Values, FieldErrors, error) {
+	r.Body = cfg.guardBody(r.Body)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		cfg.httpError(w, "Can't parse multipart", http.StatusBadRequest, err)
+		return nil, nil, err
 	}
 
+	values := make(url.Values)
+	fileErrors := make(FieldErrors)
+	res.Files = make(map[string]*UploadedFile)
+	res.AllFiles = make(map[string][]*UploadedFile)
+	textFields := make(map[string]bool) // text parts without a charset of their own
+	fileCounts := make(map[string]int)  // file parts per `file`-tagged field
+
 	for {
 		part, err := mr.NextPart()
 		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return nil, nil, readFailed(w, r.Body, err, "Can't parse multipart", http.StatusBadRequest)
+		}
 		defer part.Close()
 
 		formName := part.FormName()
 
-		if part.FileName() == "" {
+		if isFilePart(part) && fileField != nil {
+			if formName, err = fileField(values, formName); err != nil {
+				cfg.httpError(w, "Can't parse multipart", http.StatusBadRequest, err)
+				return nil, nil, err
+			}
+		}
+
+		if !isFilePart(part) {
 			buf := new(bytes.Buffer)
-			_, _ = buf.ReadFrom(part)
-			values.Add(formName, buf.String())
+			if _, err := buf.ReadFrom(part); err != nil {
+				return nil, nil, readFailed(w, r.Body, err, "Can't parse multipart", http.StatusBadRequest)
+			}
+			if fileField == nil && cfg.isJSONPayloadPart(formName, part.Header.Get("Content-Type")) {
+				res.jsonPayload = buf.Bytes()
+				res.events.emit(ParseEvent{Type: EventField, Field: formName})
+				continue
+			}
+			value := buf.String()
+			dec, err := charsetDecoder(declaredCharset(part.Header.Get("Content-Type")))
+			if err != nil {
+				http.Error(w, "Unsupported charset", http.StatusUnsupportedMediaType)
+				return nil, nil, err
+			}
+			if dec == nil {
+				textFields[formName] = true
+			} else if value, err = dec.String(value); err != nil {
+				cfg.httpError(w, "Can't parse multipart", http.StatusBadRequest, err)
+				return nil, nil, err
+			}
+			values.Add(formName, value)
+			res.events.emit(ParseEvent{Type: EventField, Field: formName})
+			if cfg.OnField != nil {
+				cfg.OnField(formName, value)
+			}
+			continue
+		}
+
+		content, empty, err := peekFilePart(part)
+		if err != nil {
+			return nil, nil, readFailed(w, r.Body, err, "Error reading file", http.StatusInternalServerError)
+		}
+		if empty {
+			if msg := cfg.emptyFileError(r, formName); msg != "" {
+				fileErrors[formName] = msg
+			}
 			continue
 		}
 
 		contentType := part.Header.Get("Content-Type")
-		if !cfg.isAllowedContentType(contentType) {
+		rule, tagged := res.fileRules[formName]
+		if tagged {
+			fileCounts[formName]++
+			if rule.maxCount > 0 && fileCounts[formName] > rule.maxCount {
+				fileErrors[rule.name] = cfg.fileRuleMessage(r, rule, "maxcount")
+				continue
+			}
+		}
+		if tagged && rule.accept != nil {
+			if !matchesMIMEType(rule.accept, contentType) {
+				fileErrors[rule.name] = cfg.fileRuleMessage(r, rule, "accept")
+				continue
+			}
+		} else if !cfg.isAllowedContentType(contentType) {
 			http.Error(w, "Unsupported file type", http.StatusBadRequest)
-			return fmt.Errorf("unsupported file type: %s", contentType)
+			return nil, nil, fmt.Errorf("unsupported file type: %s", contentType)
+		}
+
+		header := FileHeader{Field: formName, Filename: part.FileName(), ContentType: contentType, Header: part.Header}
+		grant, msg := cfg.admitFile(header, values.Get(cfg.UploadTokenFields[formName]))
+		if msg != "" {
+			fileErrors[formName] = msg
+			continue
+		}
+		declared := declaredHash(header)
+		existing, err := cfg.lookupDuplicate(r.Context(), header, declared)
+		if err != nil {
+			cfg.httpError(w, "Error checking duplicate file", http.StatusInternalServerError, err)
+			return nil, nil, err
+		}
+		if existing != nil {
+			res.addFile(formName, existing)
+			values.Add(formName, existing.Hash)
+			continue
+		}
+
+		fileMax := cfg.fileMaxSize(rule)
+		maxSize := fileMax
+		if grant != nil && grant.Size < maxSize {
+			maxSize = grant.Size
 		}
 
 		var fileBuf bytes.Buffer
-		n, err := io.CopyN(&fileBuf, part, cfg.MaxFileSize+1)
-		if err != nil && err != io.EOF {
-			http.Error(w, "Error reading file", http.StatusInternalServerError)
-			return err
+		n, err := cfg.copyLimited(&fileBuf, res.events.fileReader(content, formName, part.FileName()), maxSize+1)
+		if err != nil {
+			return nil, nil, readFailed(w, r.Body, err, "Error reading file", http.StatusInternalServerError)
 		}
-		if n > cfg.MaxFileSize {
+		res.events.emit(ParseEvent{Type: EventFileDone, Field: formName, Filename: part.FileName()})
+		if grant != nil && n != grant.Size {
+			fileErrors[formName] = fmt.Sprintf("%s does not match its upload token", formName)
+			continue
+		}
+		if tagged && rule.maxSize > 0 && n > rule.maxSize {
+			fileErrors[rule.name] = cfg.fileRuleMessage(r, rule, "maxsize")
+			continue
+		}
+		if n > fileMax {
 			http.Error(w, "File too large", http.StatusRequestEntityTooLarge)
-			return fmt.Errorf("file too large: %d bytes", n)
+			return nil, nil, fmt.Errorf("file too large: %d bytes", n)
 		}
 
-		content := fileBuf.Bytes()
-		hash := sha256.Sum256(content)
+		hash := sha256.Sum256(fileBuf.Bytes())
 
-		cfg.Files[formName] = &UploadedFile{
+		file := &UploadedFile{
 			Filename:    part.FileName(),
 			ContentType: contentType,
-			Content:     content,
+			Content:     fileBuf.Bytes(),
+			Size:        n,
 			Hash:        fmt.Sprintf("%x", hash),
 		}
+		if declared != "" && declared != file.Hash {
+			fileErrors[formName] = fmt.Sprintf("%s does not match its declared checksum", formName)
+			continue
+		}
+		file, msg, err = cfg.processFile(w, r, res, formName, file)
+		if err != nil {
+			return nil, nil, err
+		}
+		if msg != "" {
+			fileErrors[formName] = msg
+			continue
+		}
+		res.addFile(formName, file)
+
+		values.Add(formName, file.Hash)
+	}
+
+	// Text parts without their own charset take the form's.
+	dec, err := charsetDecoder(formCharset(r.Header.Get("Content-Type"), values))
+	if err != nil {
+		http.Error(w, "Unsupported charset", http.StatusUnsupportedMediaType)
+		return nil, nil, err
+	}
+	if dec != nil {
+		keep := func(key string) bool { return textFields[key] && res.Files[key] == nil }
+		if err := transcodeValues(values, dec, keep); err != nil {
+			cfg.httpError(w, "Can't parse multipart", http.StatusBadRequest, err)
+			return nil, nil, err
+		}
+	}
+	return values, fileErrors, nil
+}
+
+// admitFile decides from its header whether an upload may proceed:
+// OnFileStart must accept it and fields in UploadTokenFields need a valid
+// token for it. It returns the token's grant, if any, or a field error.
+func (cfg *Config) admitFile(header FileHeader, token string) (*UploadGrant, string) {
+	if !cfg.fileStartAllowed(header) {
+		return nil, fmt.Sprintf("%s was rejected", header.Field)
+	}
+	return cfg.uploadGrantFor(header, token)
+}
 
-		values.Add(formName, fmt.Sprintf("%x", hash))
+// processFile runs the checks and conversions every uploaded file goes
+// through once its content is read, multipart or data URI alike, and stores
+// the result. It returns the processed file or a field error. Only files
+// that pass every check are stored and remembered, so the dedup shortcut
+// never hands out a rejected file. On error the response has been written.
+func (cfg *Config) processFile(w http.ResponseWriter, r *http.Request, res *ParseResult, field string, file *UploadedFile) (*UploadedFile, string, error) {
+	if msg := cfg.checkMIMEPolicy(field, file); msg != "" {
+		return nil, msg, nil
 	}
+	if msg := cfg.checkPDF(field, file); msg != "" {
+		return nil, msg, nil
+	}
+	if msg := cfg.checkMedia(r.Context(), field, file); msg != "" {
+		return nil, msg, nil
+	}
+	file, msg := cfg.normalizeText(field, file)
+	if msg != "" {
+		return nil, msg, nil
+	}
+	if file, msg = cfg.convertFile(r.Context(), field, file); msg != "" {
+		return nil, msg, nil
+	}
+	if msg := cfg.generateThumbnails(field, file); msg != "" {
+		return nil, msg, nil
+	}
+	if err := cfg.persistFile(r.Context(), res, field, file); err != nil {
+		cfg.httpError(w, "Error storing file", http.StatusInternalServerError, err)
+		return nil, "", err
+	}
+	return file, "", nil
+}
 
+// prepareValues applies tag-driven rewrites to form-encoded values before
+// they are decoded into dst, returning errors for values it cannot coerce.
+func (cfg *Config) prepareValues(r *http.Request, dst interface{}, values url.Values) FieldErrors {
+	cfg.reconcileFormValues(dst, values)
+	splitValues(dst, values)
+	fieldErrors := make(FieldErrors)
+	for field, msg := range cfg.coerceNumbers(r, dst, values) {
+		fieldErrors[field] = msg
+	}
+	return fieldErrors
+}
+
+// decodeValues decodes form-encoded values into dst once they pass the
+// depth limit, adding errors for `encoding`-tagged and network address
+// values to fieldErrors.
+// Decoder errors are ignored; validation reports missing fields.
+func (cfg *Config) decodeValues(dst interface{}, values url.Values, res *ParseResult, fieldErrors FieldErrors) error {
+	if err := cfg.checkValuesDepth(values); err != nil {
+		return err
+	}
+	cfg.mergeValues(dst, values, res)
+	decodeBinaryValues(dst, values, fieldErrors)
+	decodeNetworkValues(dst, values, fieldErrors)
+	decodeWrapperValues(dst, values, fieldErrors)
 	_ = cfg.Decoder.Decode(dst, values)
-	return cfg.validateAndRespond(w, dst)
+	cfg.decodeStateValues(dst, values, res, fieldErrors)
+	return nil
 }
 
 // validateAndRespond validates the dst struct and returns JSON error if failed.
-func (cfg *Config) validateAndRespond(w http.ResponseWriter, dst interface{}) error {
-	if err := cfg.Validator.Struct(dst); err != nil {
-		if validationErrs, ok := err.(validator.ValidationErrors); ok {
-			fieldErrors := make(map[string]string)
-			for _, ve := range validationErrs {
-				field := strings.ToLower(ve.Field())
-				if msg, exists := cfg.FieldErrorMessages[field]; exists {
-					fieldErrors[field] = msg
-				} else {
-					fieldErrors[field] = fmt.Sprintf("%s is %s", field, ve.Tag())
-				}
+// preErrors carries field errors found before validation (file checks, value
+// coercion); they are reported in the same response as validation failures.
+func (cfg *Config) validateAndRespond(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult, preErrors FieldErrors) error {
+	fieldErrors, err := cfg.validateFields(r, dst, res, preErrors)
+	var f *validationFailure
+	if errors.As(err, &f) {
+		cfg.httpError(w, f.msg, f.status, f.err)
+		return f.err
+	}
+	if err == nil && len(fieldErrors) > 0 {
+		err = fieldErrors
+	}
+	if err != nil {
+		cfg.respondFieldErrors(w, r, res, fieldErrors)
+	}
+	return err
+}
+
+// validatorFor returns the validator for r: the one ValidatorResolver
+// picks, else Validator.
+func (cfg *Config) validatorFor(r *http.Request) *validator.Validate {
+	if cfg.ValidatorResolver != nil {
+		if v := cfg.ValidatorResolver(r); v != nil {
+			return v
+		}
+	}
+	return cfg.Validator
+}
+
+// validationFailure is an error validateFields could not turn into field
+// errors, with the response validateAndRespond writes for it.
+type validationFailure struct {
+	status int
+	msg    string
+	err    error
+}
+
+func (f *validationFailure) Error() string { return f.err.Error() }
+func (f *validationFailure) Unwrap() error { return f.err }
+
+// validateFields runs the hooks and the validator over dst and returns the
+// field errors found, merged with preErrors. The error is the validator's
+// when it rejected dst, or a *validationFailure when a hook or the
+// validator itself failed. Confirmation fields are stripped on success.
+func (cfg *Config) validateFields(r *http.Request, dst interface{}, res *ParseResult, preErrors FieldErrors) (FieldErrors, error) {
+	textErrors := cfg.limitText(r, dst, res)
+	recordChanges(dst, res)
+	fieldErrors := make(FieldErrors)
+	for field, msg := range preErrors {
+		fieldErrors[field] = msg
+	}
+	for field, msg := range textErrors {
+		fieldErrors[field] = msg
+	}
+	if !res.stateDecoded {
+		for field, msg := range clearStateFields(dst) {
+			fieldErrors[field] = msg
+		}
+	}
+	for field, msg := range cfg.enrich(r.Context(), dst) {
+		fieldErrors[field] = msg
+	}
+	for field, msg := range canonicalizeCodes(dst) {
+		fieldErrors[field] = msg
+	}
+	for field, msg := range cfg.checkCards(r, dst) {
+		fieldErrors[field] = msg
+	}
+	addressErrors, err := cfg.normalizeAddresses(r.Context(), dst, res)
+	if err != nil {
+		return nil, &validationFailure{http.StatusInternalServerError, "Can't normalize address", err}
+	}
+	for field, msg := range addressErrors {
+		fieldErrors[field] = msg
+	}
+	computeErrors, err := cfg.compute(dst)
+	if err != nil {
+		return nil, &validationFailure{http.StatusInternalServerError, "Can't compute fields", err}
+	}
+	for field, msg := range computeErrors {
+		fieldErrors[field] = msg
+	}
+	for field, msg := range checkConfirmations(dst) {
+		fieldErrors[field] = msg
+	}
+	for field, msg := range cfg.checkFileRequirements(r, dst, res) {
+		if _, exists := fieldErrors[field]; !exists {
+			fieldErrors[field] = msg
+		}
+	}
+	for field, msg := range cfg.checkRequiredFiles(r, dst, res) {
+		if _, exists := fieldErrors[field]; !exists {
+			fieldErrors[field] = msg
+		}
+	}
+	passwordErrors, err := cfg.checkPasswords(r, dst, res)
+	if err != nil {
+		return nil, &validationFailure{http.StatusInternalServerError, "Can't check password", err}
+	}
+	for field, msg := range passwordErrors {
+		if _, exists := fieldErrors[field]; !exists {
+			fieldErrors[field] = msg
+		}
+	}
+
+	if cfg.DecodeOnly {
+		res.ValidationSkipped = true
+	} else if v := cfg.validatorFor(r); v == nil {
+		return nil, &validationFailure{http.StatusInternalServerError, "Validation unavailable", errors.New("formparser: nil Validator; set DecodeOnly to skip validation")}
+	} else if err := v.Struct(dst); err != nil {
+		validationErrs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return nil, &validationFailure{http.StatusBadRequest, "Validation failed", err}
+		}
+		lang := requestLang(r)
+		for _, ve := range validationErrs {
+			field := strings.ToLower(ve.Field())
+			if _, exists := fieldErrors[field]; exists {
+				continue // keep the more specific pre-validation error
+			}
+			res.recordErrorDetail(field, ve)
+			if msg, exists := cfg.lookupMessage(field, ve.Tag(), lang); exists {
+				fieldErrors[field] = msg
+			} else {
+				fieldErrors[field] = cfg.defaultMessage(dst, field, ve)
 			}
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_ = json.NewEncoder(w).Encode(map[string]any{
-				"message": "Validation failed",
-				"fields":  fieldErrors,
-			})
-			return err
 		}
-		http.Error(w, "Validation failed", http.StatusBadRequest)
-		return err
+		return fieldErrors, err
 	}
-	return nil
+
+	if len(fieldErrors) == 0 {
+		stripConfirmations(dst)
+	}
+	return fieldErrors, nil
 }
 
-// isAllowedContentType checks against user-defined or default MIME types.
-func (cfg *Config) isAllowedContentType(contentType string) bool {
-	// No allowed MIME types = no files allowed
-	if len(cfg.AllowedMIMETypes) == 0 {
+// respondFieldErrors writes the standard validation failure JSON in the
+// ErrorFormat negotiated for r. Version 1 adds the error codes of res under
+// "codes" when there are any. Card numbers in messages are masked in place,
+// so the returned errors are safe to log too.
+func (cfg *Config) respondFieldErrors(w http.ResponseWriter, r *http.Request, res *ParseResult, fieldErrors FieldErrors) {
+	for field, msg := range fieldErrors {
+		fieldErrors[field] = redactPANs(msg)
+	}
+	format := cfg.errorFormat(r)
+	w.Header().Set(ErrorFormatHeader, format.String())
+	var body map[string]any
+	if format == ErrorFormatV2 {
+		body = map[string]any{
+			"message": "Validation failed",
+			"version": 2,
+			"errors":  fieldErrorsV2(res, fieldErrors),
+		}
+	} else {
+		body = map[string]any{
+			"message": "Validation failed",
+			"fields":  fieldErrors,
+		}
+		if len(res.ErrorCodes) > 0 {
+			body["codes"] = res.ErrorCodes
+		}
+	}
+	if cfg.Mode == ModeDebug {
+		body["debug"] = fieldDebug(res, fieldErrors)
+	}
+	cfg.writeErrorJSON(w, r, body)
+}
+
+// hasStructuredSuffix reports whether contentType uses the structured syntax
+// suffix (RFC 6839) for syntax, as application/problem+json does for "json".
+func hasStructuredSuffix(contentType, syntax string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.HasSuffix(strings.ToLower(strings.TrimSpace(mediaType)), "+"+syntax)
+}
+
+// maxFileSize returns MaxFileSize or the default when unset.
+func (cfg *Config) maxFileSize() int64 {
+	if cfg.MaxFileSize > 0 {
+		return cfg.MaxFileSize
+	}
+	return defaultMaxFileSize
+}
+
+// multipartJSONField returns MultipartJSONField or the default when unset.
+func (cfg *Config) multipartJSONField() string {
+	if cfg.MultipartJSONField != "" {
+		return cfg.MultipartJSONField
+	}
+	return "data"
+}
+
+// isJSONPayloadPart reports whether a multipart/form-data part is the JSON
+// payload of the "metadata part plus sibling files" convention: the part
+// named MultipartJSONField, sent as application/json.
+func (cfg *Config) isJSONPayloadPart(formName, contentType string) bool {
+	if name := cfg.multipartJSONField(); name == "-" || formName != name {
 		return false
 	}
-	for _, allowed := range cfg.AllowedMIMETypes {
-		if contentType == allowed {
+	mediaType, _ := parseMediaType(contentType)
+	return mediaType == "application/json" || hasStructuredSuffix(mediaType, "json")
+}
+
+// isAllowedContentType checks contentType against AllowedMIMETypes. No
+// allowed MIME types means no files are allowed.
+func (cfg *Config) isAllowedContentType(contentType string) bool {
+	return matchesMIMEType(cfg.AllowedMIMETypes, contentType)
+}
+
+// matchesMIMEType reports whether the media type of contentType, ignoring
+// its parameters and case, is one of patterns. Patterns may name a whole
+// family ("image/*") or everything (AllowAllMIMETypes).
+func matchesMIMEType(patterns []string, contentType string) bool {
+	mediaType, _ := parseMediaType(contentType)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if mediaType == pattern || pattern == AllowAllMIMETypes {
+			return true
+		}
+		if family, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(mediaType, family+"/") {
 			return true
 		}
 	}
@@ -0,0 +1,166 @@
+package formparser
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidFormState is returned for malformed or tampered form state.
+var ErrInvalidFormState = errors.New("invalid form state")
+
+// formStateContext separates form state signatures from upload tokens, so
+// one can never be replayed as the other when the secrets are shared.
+const formStateContext = "formparser.state\x00"
+
+// SignFormState encodes state, e.g. the record version a form was rendered
+// from or the price it offered, as a tamper-proof token signed with
+// FormStateSecret for the hidden field key. Embed it in that field, for
+// instance with FormStateField, and bind it back with a `state:"<key>"` tag.
+// The signature covers key, so a token cannot be replayed in another state
+// field; give the state fields of different forms distinct keys, e.g.
+// "_checkout_offer", to keep tokens from crossing forms.
+func (cfg *Config) SignFormState(key string, state interface{}) (string, error) {
+	if len(cfg.FormStateSecret) == 0 {
+		return "", errors.New("formparser: FormStateSecret is not set")
+	}
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(cfg.signFormState(key, payload)), nil
+}
+
+// VerifyFormState checks that token was signed for the hidden field key and
+// decodes its state into dst.
+func (cfg *Config) VerifyFormState(key, token string, dst interface{}) error {
+	enc := base64.RawURLEncoding
+	rawPayload, rawSig, ok := strings.Cut(token, ".")
+	if !ok || len(cfg.FormStateSecret) == 0 {
+		return ErrInvalidFormState
+	}
+	payload, err := enc.DecodeString(rawPayload)
+	if err != nil {
+		return ErrInvalidFormState
+	}
+	sig, err := enc.DecodeString(rawSig)
+	if err != nil || !hmac.Equal(sig, cfg.signFormState(key, payload)) {
+		return ErrInvalidFormState
+	}
+	if err := json.Unmarshal(payload, dst); err != nil {
+		return ErrInvalidFormState
+	}
+	return nil
+}
+
+// FormStateField renders a hidden input named name carrying state signed
+// with SignFormState for name, for use in html/template forms.
+func (cfg *Config) FormStateField(name string, state interface{}) (template.HTML, error) {
+	token, err := cfg.SignFormState(name, state)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(fmt.Sprintf(`<input type="hidden" name="%s" value="%s">`,
+		template.HTMLEscapeString(name), template.HTMLEscapeString(token))), nil
+}
+
+// signFormState returns the HMAC-SHA256 of key and payload.
+func (cfg *Config) signFormState(key string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, cfg.FormStateSecret)
+	mac.Write([]byte(formStateContext))
+	mac.Write([]byte(key + "\x00"))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// stateField is a top-level field tagged `state:"<form field>"`.
+type stateField struct {
+	index int
+	key   string // form field carrying the token
+	name  string // field error key
+}
+
+// stateFieldsCache maps reflect.Type to []stateField.
+var stateFieldsCache sync.Map
+
+// stateFields returns the top-level `state`-tagged fields of dst.
+func stateFields(dst interface{}) []stateField {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := stateFieldsCache.Load(t); ok {
+		return cached.([]stateField)
+	}
+
+	var fields []stateField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := f.Tag.Get("state")
+		if !f.IsExported() || key == "" {
+			continue
+		}
+		fields = append(fields, stateField{index: i, key: key, name: strings.ToLower(f.Name)})
+	}
+
+	stateFieldsCache.Store(t, fields)
+	return fields
+}
+
+// decodeStateValues verifies the signed state of `state`-tagged fields and
+// decodes it into dst. It runs after the form decoder and overwrites the
+// fields, so values posted for them directly are discarded. A missing or
+// modified token is reported in fieldErrors and leaves the field zero, so
+// the handler never sees state the client altered.
+func (cfg *Config) decodeStateValues(dst interface{}, values url.Values, res *ParseResult, fieldErrors FieldErrors) {
+	res.stateDecoded = true
+	fields := stateFields(dst)
+	if len(fields) == 0 {
+		return
+	}
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, f := range fields {
+		field := v.Field(f.index)
+		field.SetZero()
+		token := values.Get(f.key)
+		if token == "" {
+			fieldErrors[f.name] = f.name + " is missing"
+			continue
+		}
+		decoded := reflect.New(field.Type())
+		if err := cfg.VerifyFormState(f.key, token, decoded.Interface()); err != nil {
+			fieldErrors[f.name] = f.name + " has been modified"
+			continue
+		}
+		field.Set(decoded.Elem())
+	}
+}
+
+// clearStateFields zeroes the `state`-tagged fields of a dst decoded from a
+// body without hidden form fields, such as JSON or XML, where the client
+// would otherwise set them directly, and reports them as missing.
+func clearStateFields(dst interface{}) FieldErrors {
+	fields := stateFields(dst)
+	if len(fields) == 0 {
+		return nil
+	}
+	fieldErrors := make(FieldErrors)
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, f := range fields {
+		v.Field(f.index).SetZero()
+		fieldErrors[f.name] = f.name + " is missing"
+	}
+	return fieldErrors
+}
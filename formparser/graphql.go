@@ -0,0 +1,125 @@
+package formparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ParseGraphQLMultipart parses a GraphQL multipart request as sent by
+// Apollo, urql and other clients following the graphql-multipart-request-spec:
+// an "operations" part holding the JSON operation, a "map" part assigning
+// file parts to object paths within it, then the file parts themselves.
+//
+// The operation is decoded into dst like a JSON body and validated. Files
+// are checked and processed like multipart uploads and keyed by object path,
+// e.g. Files["variables.file"] or Files["variables.files.0"], so per-field
+// rules such as MIMEPolicies use those paths too. A file mapped to several
+// paths appears under each of them. Batched operations are not supported.
+func (cfg *Config) ParseGraphQLMultipart(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	res := &ParseResult{}
+	sw := &statusWriter{ResponseWriter: w}
+	var read int64
+	if r.Body != nil {
+		r.Body = &countingBody{ReadCloser: r.Body, n: &read}
+	}
+	err := cfg.parseGraphQLRequest(sw, r, dst, res)
+	cfg.stats.bytesRead.Add(read)
+	cfg.stats.recordFailure(err, sw.status)
+	cfg.Result, cfg.Files = res, res.Files
+	return err
+}
+
+// parseGraphQLRequest runs the pre-checks and the parse, publishing its events.
+func (cfg *Config) parseGraphQLRequest(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	cfg.stats.parses[kindMultipart].Add(1)
+	if err := cfg.checkBeforeBody(w, r); err != nil {
+		return err
+	}
+	res.MediaType, res.MediaParams = parseMediaType(r.Header.Get("Content-Type"))
+	if res.MediaType != "multipart/form-data" {
+		http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
+		return errors.New("unsupported content type")
+	}
+	res.events = cfg.newEventEmitter(r)
+	res.events.emit(ParseEvent{Type: EventStarted})
+	err := cfg.parseGraphQL(w, r, dst, res)
+	res.events.finish(err)
+	return err
+}
+
+// parseGraphQL implements ParseGraphQLMultipart.
+func (cfg *Config) parseGraphQL(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	var fileMap map[string][]string
+	loadMap := func(values url.Values) error {
+		if fileMap != nil {
+			return nil
+		}
+		if _, ok := values["map"]; !ok {
+			return errors.New("missing map")
+		}
+		if err := json.Unmarshal([]byte(values.Get("map")), &fileMap); err != nil || fileMap == nil {
+			return errors.New("invalid map")
+		}
+		return nil
+	}
+
+	values, fileErrors, err := cfg.readMultipart(w, r, res, func(values url.Values, formName string) (string, error) {
+		if _, ok := values["operations"]; !ok {
+			return "", errors.New("operations must precede file parts")
+		}
+		if err := loadMap(values); err != nil {
+			return "", err
+		}
+		paths := fileMap[formName]
+		if len(paths) == 0 {
+			return "", fmt.Errorf("file part %q is not in map", formName)
+		}
+		return paths[0], nil
+	})
+	if err != nil {
+		return err
+	}
+
+	operations := bytes.TrimSpace([]byte(values.Get("operations")))
+	if len(operations) == 0 {
+		http.Error(w, "Can't parse multipart: missing operations", http.StatusBadRequest)
+		return errors.New("missing operations")
+	}
+	if operations[0] == '[' {
+		http.Error(w, "Batched GraphQL operations are not supported", http.StatusBadRequest)
+		return errors.New("batched operations")
+	}
+	if err := loadMap(values); err != nil {
+		cfg.httpError(w, "Can't parse multipart: missing or invalid map", http.StatusBadRequest, err)
+		return err
+	}
+	for part, paths := range fileMap {
+		if len(paths) == 0 {
+			continue
+		}
+		file, uploaded := res.Files[paths[0]]
+		msg, rejected := fileErrors[paths[0]]
+		if !uploaded && !rejected {
+			http.Error(w, fmt.Sprintf("Can't parse multipart: missing file part %q", part), http.StatusBadRequest)
+			return fmt.Errorf("missing file part %q", part)
+		}
+		for _, path := range paths[1:] {
+			if uploaded {
+				res.addFile(path, file)
+			}
+			if rejected {
+				fileErrors[path] = msg
+			}
+		}
+	}
+
+	if err := cfg.decodeJSONBody(cfg.limitJSON(bytes.NewReader(operations)), dst); err != nil {
+		http.Error(w, "Invalid GraphQL operations", http.StatusBadRequest)
+		return err
+	}
+	return cfg.validateAndRespond(w, r, dst, res, fileErrors)
+}
@@ -0,0 +1,16 @@
+package formparser
+
+import "net/textproto"
+
+// FileHeader describes a multipart file part before its content is read.
+type FileHeader struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Header      textproto.MIMEHeader
+}
+
+// fileStartAllowed consults OnFileStart, accepting the file when no hook is set.
+func (cfg *Config) fileStartAllowed(h FileHeader) bool {
+	return cfg.OnFileStart == nil || cfg.OnFileStart(h)
+}
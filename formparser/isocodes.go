@@ -0,0 +1,227 @@
+package formparser
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+// isoCodeKind describes one `iso` tag value.
+type isoCodeKind struct {
+	standard  string                           // named in error messages
+	canonical func(code string) (string, bool) // canonical form of a valid code
+	list      func() []string                  // all canonical codes, for suggestions
+}
+
+var isoCodeKinds = map[string]isoCodeKind{
+	"country":  {standard: "ISO 3166 country", canonical: canonicalCountry, list: countryCodes},
+	"language": {standard: "ISO 639 language", canonical: canonicalLanguage, list: languageCodes},
+	"currency": {standard: "ISO 4217 currency", canonical: canonicalCurrency, list: currencyCodes},
+}
+
+// isoTables checks codes against the validator's ISO 3166 and ISO 4217
+// tables, which are stricter than the region and currency lists of x/text.
+var isoTables = validator.New()
+
+// canonicalCountry accepts alpha-2, alpha-3 and numeric ISO 3166 codes in
+// any case and returns the upper-case alpha-2 code.
+func canonicalCountry(code string) (string, bool) {
+	region, err := language.ParseRegion(code)
+	if err != nil {
+		return "", false
+	}
+	alpha2 := region.String()
+	return alpha2, isoTables.Var(alpha2, "iso3166_1_alpha2") == nil
+}
+
+// canonicalLanguage accepts ISO 639-1 and 639-2/3 codes in any case and
+// returns the shortest code, e.g. "eng" -> "en".
+func canonicalLanguage(code string) (string, bool) {
+	base, err := language.ParseBase(code)
+	if err != nil {
+		return "", false
+	}
+	return base.String(), true
+}
+
+// canonicalCurrency returns the upper-case ISO 4217 code.
+func canonicalCurrency(code string) (string, bool) {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return "", false
+	}
+	return unit.String(), isoTables.Var(unit.String(), "iso4217") == nil
+}
+
+var (
+	countryCodesOnce, languageCodesOnce, currencyCodesOnce sync.Once
+	countryCodeList, languageCodeList, currencyCodeList    []string
+)
+
+func countryCodes() []string {
+	countryCodesOnce.Do(func() { countryCodeList = twoLetterCodes(canonicalCountry) })
+	return countryCodeList
+}
+
+func languageCodes() []string {
+	languageCodesOnce.Do(func() { languageCodeList = twoLetterCodes(canonicalLanguage) })
+	return languageCodeList
+}
+
+func currencyCodes() []string {
+	currencyCodesOnce.Do(func() {
+		for iter := currency.Query(currency.Historical, currency.NonTender); iter.Next(); {
+			if code, ok := canonicalCurrency(iter.Unit().String()); ok {
+				currencyCodeList = append(currencyCodeList, code)
+			}
+		}
+		sort.Strings(currencyCodeList)
+		currencyCodeList = slices.Compact(currencyCodeList)
+	})
+	return currencyCodeList
+}
+
+// twoLetterCodes returns the canonical codes among "aa" to "zz".
+func twoLetterCodes(canonical func(string) (string, bool)) []string {
+	var codes []string
+	for a := 'a'; a <= 'z'; a++ {
+		for b := 'a'; b <= 'z'; b++ {
+			code := string([]rune{a, b})
+			if c, ok := canonical(code); ok && strings.EqualFold(c, code) {
+				codes = append(codes, c)
+			}
+		}
+	}
+	return codes
+}
+
+// isoField is a string field carrying an `iso` tag.
+type isoField struct {
+	index int
+	name  string // field error key
+	kind  isoCodeKind
+}
+
+// isoFieldsCache maps reflect.Type to []isoField.
+var isoFieldsCache sync.Map
+
+// isoFields returns the top-level string fields of dst tagged with
+// `iso:"country"`, `iso:"language"` or `iso:"currency"`.
+func isoFields(dst interface{}) []isoField {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := isoFieldsCache.Load(t); ok {
+		return cached.([]isoField)
+	}
+
+	var fields []isoField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		kind, ok := isoCodeKinds[f.Tag.Get("iso")]
+		if !ok || !f.IsExported() || f.Type.Kind() != reflect.String {
+			continue
+		}
+		fields = append(fields, isoField{index: i, name: strings.ToLower(f.Name), kind: kind})
+	}
+
+	isoFieldsCache.Store(t, fields)
+	return fields
+}
+
+// canonicalizeCodes rewrites `iso`-tagged fields of dst to their canonical
+// code ("us" -> "US", "USA" -> "US", "EN" -> "en") and reports unknown codes
+// with the nearest valid ones. Empty fields are left to `required`.
+func canonicalizeCodes(dst interface{}) FieldErrors {
+	fields := isoFields(dst)
+	if len(fields) == 0 {
+		return nil
+	}
+	fieldErrors := make(FieldErrors)
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, f := range fields {
+		field := v.Field(f.index)
+		code := strings.TrimSpace(field.String())
+		if code == "" {
+			continue
+		}
+		if canonical, ok := f.kind.canonical(code); ok {
+			field.SetString(canonical)
+			continue
+		}
+		msg := fmt.Sprintf("%s must be an %s code", f.name, f.kind.standard)
+		if near := nearestCodes(code, f.kind.list()); len(near) > 0 {
+			msg += "; did you mean " + joinOr(near) + "?"
+		}
+		fieldErrors[f.name] = msg
+	}
+	return fieldErrors
+}
+
+// maxSuggestions caps the codes listed in an unknown-code message.
+const maxSuggestions = 3
+
+// nearestCodes returns up to maxSuggestions codes one edit away from code,
+// preferring those that share a longer prefix with it.
+func nearestCodes(code string, codes []string) []string {
+	code = strings.ToUpper(code)
+	var near []string
+	for _, c := range codes {
+		if editDistance(code, strings.ToUpper(c)) == 1 {
+			near = append(near, c)
+		}
+	}
+	sort.SliceStable(near, func(i, j int) bool {
+		return commonPrefixLen(code, strings.ToUpper(near[i])) > commonPrefixLen(code, strings.ToUpper(near[j]))
+	})
+	return near[:min(len(near), maxSuggestions)]
+}
+
+// commonPrefixLen returns the length of the common prefix of a and b.
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// joinOr joins items as "A", "A or B", "A, B or C".
+func joinOr(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " or " + items[len(items)-1]
+}
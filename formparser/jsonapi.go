@@ -0,0 +1,223 @@
+package formparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonAPIMediaType is the media type of JSON:API documents.
+const jsonAPIMediaType = "application/vnd.api+json"
+
+// errNoPrimaryData rejects JSON:API documents without a "data" member.
+var errNoPrimaryData = errors.New("JSON:API document has no primary data")
+
+// jsonAPIDocument is the part of a JSON:API request document the parser reads.
+type jsonAPIDocument struct {
+	Data *jsonAPIResource `json:"data"`
+}
+
+type jsonAPIResource struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id,omitempty"`
+	Attributes    map[string]json.RawMessage     `json:"attributes"`
+	Relationships map[string]jsonAPIRelationship `json:"relationships"`
+}
+
+type jsonAPIRelationship struct {
+	Data json.RawMessage `json:"data"`
+}
+
+type jsonAPIIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// JSONAPIError is one entry of the "errors" array in JSON:API responses.
+type JSONAPIError struct {
+	Status string         `json:"status"`
+	Code   string         `json:"code,omitempty"`
+	Title  string         `json:"title"`
+	Detail string         `json:"detail,omitempty"`
+	Source *JSONAPISource `json:"source,omitempty"`
+}
+
+// JSONAPISource points at the member of the request document an error is about.
+type JSONAPISource struct {
+	Pointer string `json:"pointer"`
+}
+
+// parseJSONAPI handles a JSON:API document when JSONAPI is set. The primary
+// resource is flattened into a plain JSON object before decoding: its id
+// and attributes become members, and each relationship becomes the id of
+// its linkage, or a list of ids for to-many relationships. Errors are
+// written as JSON:API error objects pointing back into the document.
+func (cfg *Config) parseJSONAPI(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	var doc jsonAPIDocument
+	err := json.NewDecoder(cfg.limitJSON(r.Body)).Decode(&doc)
+	if err == nil && doc.Data == nil {
+		err = errNoPrimaryData
+	}
+	var flat []byte
+	if err == nil {
+		flat, err = doc.Data.flatten()
+	}
+	var body io.Reader
+	if err == nil {
+		body, err = cfg.mergeJSON(dst, bytes.NewReader(flat), res)
+	}
+	if err == nil {
+		err = cfg.decodeJSONBody(body, dst)
+	}
+	if err != nil {
+		cfg.writeJSONAPIErrors(w, r, []JSONAPIError{{
+			Status: strconv.Itoa(http.StatusBadRequest),
+			Title:  "Invalid JSON:API document",
+			Detail: jsonAPIDecodeMessage(err),
+		}})
+		return err
+	}
+
+	fieldErrors, err := cfg.validateFields(r, dst, res, nil)
+	var f *validationFailure
+	if errors.As(err, &f) {
+		cfg.httpError(w, f.msg, f.status, f.err)
+		return f.err
+	}
+	if err == nil && len(fieldErrors) > 0 {
+		err = fieldErrors
+	}
+	if err != nil {
+		cfg.writeJSONAPIErrors(w, r, jsonAPIFieldErrors(dst, doc.Data, res, fieldErrors))
+	}
+	return err
+}
+
+// flatten returns the resource as a plain JSON object.
+func (resource *jsonAPIResource) flatten() ([]byte, error) {
+	flat := make(map[string]json.RawMessage, len(resource.Attributes)+len(resource.Relationships)+1)
+	if resource.ID != "" {
+		flat["id"], _ = json.Marshal(resource.ID)
+	}
+	for name, value := range resource.Attributes {
+		flat[name] = value
+	}
+	for name, rel := range resource.Relationships {
+		value, err := rel.ids()
+		if err != nil {
+			return nil, err
+		}
+		flat[name] = value
+	}
+	return json.Marshal(flat)
+}
+
+// ids returns the relationship's resource linkage as an id, a list of ids
+// or null.
+func (rel jsonAPIRelationship) ids() (json.RawMessage, error) {
+	data := bytes.TrimSpace(rel.Data)
+	switch {
+	case len(data) == 0 || bytes.Equal(data, []byte("null")):
+		return json.RawMessage("null"), nil
+	case data[0] == '[':
+		var linkage []jsonAPIIdentifier
+		if err := json.Unmarshal(data, &linkage); err != nil {
+			return nil, err
+		}
+		ids := make([]string, len(linkage))
+		for i, l := range linkage {
+			ids[i] = l.ID
+		}
+		return json.Marshal(ids)
+	default:
+		var linkage jsonAPIIdentifier
+		if err := json.Unmarshal(data, &linkage); err != nil {
+			return nil, err
+		}
+		return json.Marshal(linkage.ID)
+	}
+}
+
+// jsonAPIFieldErrors converts field errors into error objects whose source
+// pointers name the attribute or relationship the field was decoded from.
+func jsonAPIFieldErrors(dst interface{}, resource *jsonAPIResource, res *ParseResult, fieldErrors FieldErrors) []JSONAPIError {
+	members := make(map[string]string)
+	if t := reflect.TypeOf(dst); t != nil {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+				if name == "" || name == "-" {
+					name = f.Name
+				}
+				members[strings.ToLower(f.Name)] = name
+			}
+		}
+	}
+
+	fields := make([]string, 0, len(fieldErrors))
+	for field := range fieldErrors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	errs := make([]JSONAPIError, 0, len(fields))
+	for _, field := range fields {
+		e := JSONAPIError{
+			Status: strconv.Itoa(http.StatusBadRequest),
+			Title:  "Validation failed",
+			Detail: redactPANs(fieldErrors[field]),
+			Source: &JSONAPISource{Pointer: jsonAPIPointer(resource, members[field])},
+		}
+		if codes := res.ErrorCodes[field]; len(codes) > 0 {
+			e.Code = codes[0]
+		}
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+// jsonAPIPointer returns the JSON pointer of member within the document.
+func jsonAPIPointer(resource *jsonAPIResource, member string) string {
+	escaped := strings.NewReplacer("~", "~0", "/", "~1").Replace(member)
+	if _, ok := resource.Relationships[member]; ok {
+		return "/data/relationships/" + escaped
+	}
+	if _, ok := resource.Attributes[member]; !ok && member == "id" {
+		return "/data/id"
+	}
+	if member == "" {
+		return "/data"
+	}
+	return "/data/attributes/" + escaped
+}
+
+// writeJSONAPIErrors writes errs as a 400 JSON:API error document.
+func (cfg *Config) writeJSONAPIErrors(w http.ResponseWriter, r *http.Request, errs []JSONAPIError) {
+	cfg.writeErrorDocument(w, r, jsonAPIMediaType, map[string]any{"errors": errs})
+}
+
+// jsonAPIDecodeMessage describes why a document could not be decoded.
+func jsonAPIDecodeMessage(err error) string {
+	if errors.Is(err, errNoPrimaryData) {
+		return "Document has no primary data"
+	}
+	var depthErr *MaxDepthError
+	if errors.As(err, &depthErr) {
+		return "JSON body nested too deeply"
+	}
+	var tokensErr *MaxTokensError
+	if errors.As(err, &tokensErr) {
+		return "JSON body has too many elements"
+	}
+	return "Invalid JSON body"
+}
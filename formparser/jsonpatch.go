@@ -0,0 +1,500 @@
+package formparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONPatchError reports why one operation of a JSON Patch document was
+// rejected. Index is the operation's 0-based position in the document.
+type JSONPatchError struct {
+	Index   int    `json:"index"`
+	Op      string `json:"op,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+
+	err error // detail kept out of Message, e.g. a Go type mismatch
+}
+
+// JSONPatchErrors is returned by ParseJSONPatch when the patch document is
+// malformed or an operation cannot be applied.
+type JSONPatchErrors []JSONPatchError
+
+func (pe JSONPatchErrors) Error() string {
+	msgs := make([]string, len(pe))
+	for i, e := range pe {
+		msgs[i] = fmt.Sprintf("op %d (%s %s): %s", e.Index, e.Op, e.Path, e.Message)
+		if e.err != nil {
+			msgs[i] += ": " + e.err.Error()
+		}
+	}
+	return "json patch errors: " + strings.Join(msgs, "; ")
+}
+
+// jsonPatchOp is one operation of a JSON Patch document.
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+
+	path, from []string // parsed pointers
+	value      any      // decoded Value
+}
+
+// ParseJSONPatch applies an RFC 6902 JSON Patch document from r to target,
+// a pointer to an already-loaded struct, and validates the result like any
+// other parse. The patch is atomic: target is only modified when every
+// operation applies.
+//
+// Malformed operations are all reported at once with a 400; an operation
+// whose path does not resolve, or a failing "test", stops the patch with a
+// 422. Both responses list the offending operations by index and are
+// returned as JSONPatchErrors. The body must be application/json-patch+json
+// or application/json.
+//
+// The patch operates on target's JSON form: top-level fields hidden from
+// JSON (json:"-") keep their values, and ParseResult.Present and
+// ParseResult.Changes report the top-level fields the operations touched.
+func (cfg *Config) ParseJSONPatch(w http.ResponseWriter, r *http.Request, target interface{}) error {
+	res := &ParseResult{}
+	sw := &statusWriter{ResponseWriter: w}
+	var read int64
+	if r.Body != nil {
+		r.Body = &countingBody{ReadCloser: r.Body, n: &read}
+	}
+	err := cfg.parseJSONPatch(sw, r, target, res)
+	cfg.stats.bytesRead.Add(read)
+	cfg.stats.recordFailure(err, sw.status)
+	cfg.Result, cfg.Files = res, nil
+	return err
+}
+
+// parseJSONPatch implements ParseJSONPatch, recording details on res.
+func (cfg *Config) parseJSONPatch(w http.ResponseWriter, r *http.Request, target interface{}, res *ParseResult) error {
+	cfg.stats.parses[kindJSON].Add(1)
+	if err := cfg.checkBeforeBody(w, r); err != nil {
+		return err
+	}
+	res.MediaType, res.MediaParams = parseMediaType(r.Header.Get("Content-Type"))
+	switch res.MediaType {
+	case "application/json-patch+json", "application/json":
+	default:
+		http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
+		return errors.New("unsupported content type")
+	}
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		http.Error(w, "Can't apply patch", http.StatusInternalServerError)
+		return errors.New("formparser: ParseJSONPatch needs a pointer to a struct")
+	}
+
+	var ops []jsonPatchOp
+	if err := json.NewDecoder(cfg.limitJSON(r.Body)).Decode(&ops); err != nil {
+		return cfg.patchFailed(w, err, "Invalid JSON patch")
+	}
+	if errs := prepareJSONPatch(ops); len(errs) > 0 {
+		cfg.respondJSONPatchErrors(w, http.StatusBadRequest, errs)
+		return errs
+	}
+
+	doc, err := toJSONTree(target)
+	if err != nil {
+		cfg.httpError(w, "Can't apply patch", http.StatusInternalServerError, err)
+		return err
+	}
+	for i := range ops {
+		if doc, err = ops[i].apply(doc); err != nil {
+			// apply only reports the patch's own pointers and indexes,
+			// so its message is safe to send back.
+			errs := JSONPatchErrors{{Index: i, Op: ops[i].Op, Path: *ops[i].Path, Message: err.Error()}}
+			cfg.respondJSONPatchErrors(w, http.StatusUnprocessableEntity, errs)
+			return errs
+		}
+	}
+	result, err := decodePatched(v.Elem(), doc)
+	if err != nil {
+		i := firstMisfit(v.Elem(), ops)
+		errs := JSONPatchErrors{{Index: i, Op: ops[i].Op, Path: *ops[i].Path, Message: "value does not fit the target", err: err}}
+		cfg.respondJSONPatchErrors(w, http.StatusUnprocessableEntity, errs)
+		return errs
+	}
+
+	snapshotFields(target, res)
+	res.Present = make(map[string]bool)
+	for _, op := range ops {
+		for _, p := range [][]string{op.path, op.from} {
+			if len(p) == 0 {
+				continue
+			}
+			if f, ok := patchField(v.Elem().Type(), p[0]); ok {
+				res.Present[f.key] = true
+			}
+		}
+	}
+	v.Elem().Set(result.Elem())
+	return cfg.validateAndRespond(w, r, target, res, nil)
+}
+
+// decodePatched decodes the patched JSON form of target into a copy of it.
+// Top-level fields visible to JSON start from zero so removed members stay
+// removed; fields hidden from JSON keep target's values.
+func decodePatched(target reflect.Value, doc any) (reflect.Value, error) {
+	patched, err := json.Marshal(doc)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	result := reflect.New(target.Type())
+	result.Elem().Set(target)
+	for _, f := range structMergeFields(target.Type()) {
+		if target.Type().Field(f.index).Tag.Get("json") != "-" {
+			result.Elem().Field(f.index).SetZero()
+		}
+	}
+	if err := json.Unmarshal(patched, result.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return result, nil
+}
+
+// firstMisfit replays ops one at a time to find the first whose result no
+// longer decodes into target.
+func firstMisfit(target reflect.Value, ops []jsonPatchOp) int {
+	doc, _ := toJSONTree(target.Interface())
+	for i := range ops {
+		doc, _ = ops[i].apply(doc)
+		if _, err := decodePatched(target, cloneJSON(doc)); err != nil {
+			return i
+		}
+	}
+	return len(ops) - 1
+}
+
+// respondJSONPatchErrors writes the rejected operations as a JSON error,
+// with each operation's detail appended to its message in ModeDebug.
+func (cfg *Config) respondJSONPatchErrors(w http.ResponseWriter, status int, errs JSONPatchErrors) {
+	ops := make(JSONPatchErrors, len(errs))
+	for i, e := range errs {
+		e.Message = cfg.debugMessage(e.Message, e.err)
+		ops[i] = e
+	}
+	data, _ := json.Marshal(map[string]any{"message": "Invalid JSON patch", "operations": ops})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// prepareJSONPatch checks every operation's members and parses its
+// pointers and value.
+func prepareJSONPatch(ops []jsonPatchOp) JSONPatchErrors {
+	var errs JSONPatchErrors
+	for i := range ops {
+		op := &ops[i]
+		report := func(msg string) {
+			e := JSONPatchError{Index: i, Op: op.Op, Message: msg}
+			if op.Path != nil {
+				e.Path = *op.Path
+			}
+			errs = append(errs, e)
+		}
+		switch op.Op {
+		case "add", "remove", "replace", "move", "copy", "test":
+		default:
+			report(fmt.Sprintf("unknown op %q", op.Op))
+			continue
+		}
+		if op.Path == nil {
+			report(`missing "path"`)
+			continue
+		}
+		var err error
+		if op.path, err = parseJSONPointer(*op.Path); err != nil {
+			report(err.Error())
+			continue
+		}
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				report(`missing "value"`)
+				continue
+			}
+			dec := json.NewDecoder(bytes.NewReader(op.Value))
+			dec.UseNumber()
+			if err := dec.Decode(&op.value); err != nil {
+				report("invalid value")
+				continue
+			}
+		case "move", "copy":
+			if op.From == nil {
+				report(`missing "from"`)
+				continue
+			}
+			if op.from, err = parseJSONPointer(*op.From); err != nil {
+				report(err.Error())
+				continue
+			}
+			if op.Op == "move" && len(op.from) < len(op.path) && isPointerPrefix(op.from, op.path) {
+				report("cannot move a value into one of its children")
+			}
+		}
+	}
+	return errs
+}
+
+// apply runs the operation against doc and returns the new document.
+func (op *jsonPatchOp) apply(doc any) (any, error) {
+	switch op.Op {
+	case "add":
+		return jsonAdd(doc, op.path, cloneJSON(op.value))
+	case "remove":
+		return jsonRemove(doc, op.path)
+	case "replace":
+		doc, err := jsonRemove(doc, op.path)
+		if err != nil {
+			return nil, err
+		}
+		return jsonAdd(doc, op.path, cloneJSON(op.value))
+	case "move":
+		value, err := jsonGet(doc, op.from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if doc, err = jsonRemove(doc, op.from); err != nil {
+			return nil, err
+		}
+		return jsonAdd(doc, op.path, value)
+	case "copy":
+		value, err := jsonGet(doc, op.from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		return jsonAdd(doc, op.path, cloneJSON(value))
+	default: // test
+		value, err := jsonGet(doc, op.path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(value, op.value) {
+			return nil, errors.New("test failed")
+		}
+		return doc, nil
+	}
+}
+
+// parseJSONPointer splits an RFC 6901 pointer into unescaped tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+func isPointerPrefix(prefix, path []string) bool {
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// toJSONTree returns v's JSON form as maps, slices and json.Numbers.
+func toJSONTree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	err = dec.Decode(&tree)
+	return tree, err
+}
+
+// jsonGet returns the value at path.
+func jsonGet(doc any, path []string) (any, error) {
+	for i, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %s does not exist", formatJSONPointer(path[:i+1]))
+			}
+			doc = value
+		case []any:
+			idx, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[idx]
+		default:
+			return nil, fmt.Errorf("path %s does not exist", formatJSONPointer(path[:i+1]))
+		}
+	}
+	return doc, nil
+}
+
+// jsonUpdate replaces the container at parent with fn's result.
+func jsonUpdate(doc any, parent []string, fn func(container any) (any, error)) (any, error) {
+	if len(parent) == 0 {
+		return fn(doc)
+	}
+	child, err := jsonGet(doc, parent[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = jsonUpdate(child, parent[1:], fn); err != nil {
+		return nil, err
+	}
+	switch node := doc.(type) {
+	case map[string]any:
+		node[parent[0]] = child
+	case []any:
+		idx, _ := arrayIndex(parent[0], len(node)-1)
+		node[idx] = child
+	}
+	return doc, nil
+}
+
+// jsonAdd adds value at path: it sets an object member, inserts into an
+// array ("-" appends), or replaces the whole document for the root.
+func jsonAdd(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	last := path[len(path)-1]
+	return jsonUpdate(doc, path[:len(path)-1], func(container any) (any, error) {
+		switch node := container.(type) {
+		case map[string]any:
+			node[last] = value
+			return node, nil
+		case []any:
+			idx := len(node)
+			if last != "-" {
+				var err error
+				if idx, err = arrayIndex(last, len(node)); err != nil {
+					return nil, err
+				}
+			}
+			node = append(node, nil)
+			copy(node[idx+1:], node[idx:])
+			node[idx] = value
+			return node, nil
+		}
+		return nil, fmt.Errorf("path %s does not exist", formatJSONPointer(path[:len(path)-1]))
+	})
+}
+
+// jsonRemove removes the value at path, which must exist.
+func jsonRemove(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	last := path[len(path)-1]
+	return jsonUpdate(doc, path[:len(path)-1], func(container any) (any, error) {
+		switch node := container.(type) {
+		case map[string]any:
+			if _, ok := node[last]; !ok {
+				return nil, fmt.Errorf("path %s does not exist", formatJSONPointer(path))
+			}
+			delete(node, last)
+			return node, nil
+		case []any:
+			idx, err := arrayIndex(last, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:idx], node[idx+1:]...), nil
+		}
+		return nil, fmt.Errorf("path %s does not exist", formatJSONPointer(path))
+	})
+}
+
+// arrayIndex parses an array index token, which must be within 0..max.
+func arrayIndex(token string, max int) (int, error) {
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if idx > max {
+		return 0, fmt.Errorf("array index %d out of range", idx)
+	}
+	return idx, nil
+}
+
+func formatJSONPointer(path []string) string {
+	var b strings.Builder
+	for _, token := range path {
+		b.WriteString("/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return b.String()
+}
+
+// cloneJSON deep-copies a JSON tree.
+func cloneJSON(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(node))
+		for k, child := range node {
+			out[k] = cloneJSON(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(node))
+		for i, child := range node {
+			out[i] = cloneJSON(child)
+		}
+		return out
+	}
+	return v
+}
+
+// jsonEqual compares JSON trees, treating numbers by value.
+func jsonEqual(a, b any) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, errX := x.Float64()
+		fy, errY := y.Float64()
+		return errX == nil && errY == nil && fx == fy
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
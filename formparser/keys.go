@@ -0,0 +1,88 @@
+package formparser
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// KeyFunc computes the storage key for an uploaded file. Keys use forward
+// slashes; FileStore implementations map them onto their own namespace.
+type KeyFunc func(field string, file *UploadedFile) string
+
+// DefaultKey stores files as <field>/<sha256>/<filename>.
+func DefaultKey(field string, file *UploadedFile) string {
+	return path.Join(field, file.Hash, path.Base(file.Filename))
+}
+
+// ContentAddressedKey stores files by content hash, sharded on its first two
+// byte pairs (ab/cd/abcd….ext) so identical uploads share a single key.
+func ContentAddressedKey(field string, file *UploadedFile) string {
+	h := file.Hash
+	if len(h) < 4 {
+		return h + fileExt(file)
+	}
+	return path.Join(h[:2], h[2:4], h+fileExt(file))
+}
+
+// DateKey stores files under <field>/YYYY/MM/DD/<sha256>.ext using the UTC upload date.
+var DateKey = NewDateKey(nil)
+
+// ULIDKey stores files under <field>/<ULID>.ext, giving unique names that sort by upload time.
+var ULIDKey = NewULIDKey(nil, nil)
+
+// NewDateKey returns a DateKey that reads the time from now, so tests can
+// pass a fake clock such as Config.Clock. A nil now uses time.Now.
+func NewDateKey(now func() time.Time) KeyFunc {
+	if now == nil {
+		now = time.Now
+	}
+	return func(field string, file *UploadedFile) string {
+		return path.Join(field, now().UTC().Format("2006/01/02"), file.Hash+fileExt(file))
+	}
+}
+
+// NewULIDKey returns a ULIDKey using the given clock and entropy source, such
+// as Config.Clock and Config.Random. Nil values use time.Now and crypto/rand.
+func NewULIDKey(now func() time.Time, random io.Reader) KeyFunc {
+	if now == nil {
+		now = time.Now
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	return func(field string, file *UploadedFile) string {
+		return path.Join(field, newULID(now(), random)+fileExt(file))
+	}
+}
+
+// fileExt returns the lower-cased extension of the uploaded filename.
+func fileExt(file *UploadedFile) string {
+	return strings.ToLower(filepath.Ext(path.Base(file.Filename)))
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID returns a 26-character ULID: 48 bits of milliseconds followed by
+// 80 random bits, Crockford base32 encoded.
+func newULID(t time.Time, random io.Reader) string {
+	var id [16]byte
+	ms := uint64(t.UnixMilli())
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	_, _ = io.ReadFull(random, id[6:])
+
+	// Encode 128 bits as 26 base32 digits, most significant first.
+	hi, lo := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
@@ -0,0 +1,180 @@
+package formparser
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// KeyValueStore is the storage behind the package's stateful features, such
+// as the DedupStore returned by NewDedupStore. MemoryStore keeps entries in
+// process; redisstore.Store, in its own package so only programs that use
+// it depend on a Redis client, shares them between instances.
+type KeyValueStore interface {
+	// Get returns the value stored under key, or found == false when there
+	// is none or it has expired.
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	// Set stores value under key for ttl; a zero ttl never expires.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key, if present.
+	Delete(ctx context.Context, key string) error
+}
+
+const (
+	defaultMemoryShards = 32
+	memorySweepInterval = time.Minute
+)
+
+// MemoryStore is an in-process KeyValueStore. Keys are spread over
+// independently locked shards so concurrent requests rarely contend, and
+// expired entries are dropped on access and by a background sweep every
+// minute until Close is called. The zero value is ready to use, with the
+// default number of shards.
+type MemoryStore struct {
+	Clock func() time.Time // Optional: time source for expiry (default time.Now)
+
+	shards   []memoryShard
+	stop     chan struct{}
+	initOnce sync.Once
+	once     sync.Once
+}
+
+type memoryShard struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+}
+
+type memoryItem struct {
+	value   []byte
+	expires time.Time // zero = never
+}
+
+// NewMemoryStore returns a MemoryStore with the given number of shards
+// (default 32 when shards <= 0) and starts its expiry sweep.
+func NewMemoryStore(shards int) *MemoryStore {
+	s := &MemoryStore{}
+	s.init(shards)
+	return s
+}
+
+// init allocates the shards and starts the sweep on first use, so a zero
+// MemoryStore works like NewMemoryStore(0).
+func (s *MemoryStore) init(shards int) {
+	s.initOnce.Do(func() {
+		if shards <= 0 {
+			shards = defaultMemoryShards
+		}
+		s.shards = make([]memoryShard, shards)
+		for i := range s.shards {
+			s.shards[i].items = make(map[string]memoryItem)
+		}
+		s.stop = make(chan struct{})
+		go s.sweepLoop()
+	})
+}
+
+// Get implements KeyValueStore.
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	shard := s.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	item, ok := shard.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if item.expired(s.now()) {
+		delete(shard.items, key)
+		return nil, false, nil
+	}
+	return append([]byte(nil), item.value...), true, nil
+}
+
+// Set implements KeyValueStore.
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	item := memoryItem{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		item.expires = s.now().Add(ttl)
+	}
+	shard := s.shard(key)
+	shard.mu.Lock()
+	shard.items[key] = item
+	shard.mu.Unlock()
+	return nil
+}
+
+// Delete implements KeyValueStore.
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	shard := s.shard(key)
+	shard.mu.Lock()
+	delete(shard.items, key)
+	shard.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of stored entries, including expired ones not yet
+// swept.
+func (s *MemoryStore) Len() int {
+	n := 0
+	for i := range s.shards {
+		s.shards[i].mu.Lock()
+		n += len(s.shards[i].items)
+		s.shards[i].mu.Unlock()
+	}
+	return n
+}
+
+// Sweep drops every expired entry. It runs periodically on its own; call it
+// directly to reclaim memory at a time of your choosing.
+func (s *MemoryStore) Sweep() {
+	now := s.now()
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		for key, item := range shard.items {
+			if item.expired(now) {
+				delete(shard.items, key)
+			}
+		}
+		shard.mu.Unlock()
+	}
+}
+
+// Close stops the background sweep. The store stays usable.
+func (s *MemoryStore) Close() error {
+	s.init(0)
+	s.once.Do(func() { close(s.stop) })
+	return nil
+}
+
+func (s *MemoryStore) sweepLoop() {
+	ticker := time.NewTicker(memorySweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Sweep()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// shard returns the shard holding key.
+func (s *MemoryStore) shard(key string) *memoryShard {
+	s.init(0)
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return &s.shards[h.Sum32()%uint32(len(s.shards))]
+}
+
+func (s *MemoryStore) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+func (item memoryItem) expired(now time.Time) bool {
+	return !item.expires.IsZero() && !now.Before(item.expires)
+}
@@ -0,0 +1,85 @@
+package formparser
+
+import (
+	"bytes"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+)
+
+// LegacyForm exposes a parse's form values and files through the methods
+// handlers written against net/http already call, so code that used
+// r.ParseMultipartForm, r.FormValue and r.FormFile can be migrated one
+// endpoint at a time: parse with the Config, then hand the LegacyForm to
+// the parts of the handler that have not been rewritten yet.
+type LegacyForm struct {
+	res   *ParseResult
+	query url.Values
+}
+
+// NewLegacyForm returns a LegacyForm over the results of parsing r, e.g.
+// cfg.Result after ParseFormBasedOnContentType.
+func NewLegacyForm(r *http.Request, res *ParseResult) *LegacyForm {
+	if res == nil {
+		res = &ParseResult{}
+	}
+	return &LegacyForm{res: res, query: r.URL.Query()}
+}
+
+// FormValue returns the first value for name from the body, else from the
+// query string, like http.Request.FormValue.
+func (f *LegacyForm) FormValue(name string) string {
+	if values := f.res.Values[name]; len(values) > 0 {
+		return values[0]
+	}
+	return f.query.Get(name)
+}
+
+// PostFormValue returns the first value for name from the body only, like
+// http.Request.PostFormValue.
+func (f *LegacyForm) PostFormValue(name string) string {
+	return f.res.Values.Get(name)
+}
+
+// Form returns the body and query values combined, body values first, like
+// http.Request.Form after ParseForm.
+func (f *LegacyForm) Form() url.Values {
+	form := copyValues(f.res.Values)
+	for name, values := range f.query {
+		form[name] = append(form[name], values...)
+	}
+	return form
+}
+
+// FormFile returns the file uploaded as name, like http.Request.FormFile,
+// or http.ErrMissingFile. The file is served from memory; the header's
+// Open method is not supported, so read from the returned File instead.
+func (f *LegacyForm) FormFile(name string) (multipart.File, *multipart.FileHeader, error) {
+	file := f.res.Files[name]
+	if file == nil {
+		return nil, nil, http.ErrMissingFile
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": name, "filename": file.Filename}))
+	header.Set("Content-Type", file.ContentType)
+	fh := &multipart.FileHeader{Filename: file.Filename, Header: header, Size: file.Size}
+	return memoryFile{bytes.NewReader(file.Content)}, fh, nil
+}
+
+// memoryFile serves an UploadedFile's content as a multipart.File.
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error { return nil }
+
+// copyValues returns a deep copy of values.
+func copyValues(values url.Values) url.Values {
+	out := make(url.Values, len(values))
+	for name, list := range values {
+		out[name] = append([]string(nil), list...)
+	}
+	return out
+}
@@ -0,0 +1,133 @@
+package formparser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MediaInfo holds metadata extracted from an uploaded audio or video file.
+type MediaInfo struct {
+	Duration   time.Duration
+	VideoCodec string
+	AudioCodec string
+	Width      int
+	Height     int
+}
+
+// MediaProber extracts MediaInfo from uploaded audio/video content.
+type MediaProber interface {
+	Probe(ctx context.Context, file *UploadedFile) (*MediaInfo, error)
+}
+
+// MediaRule constrains audio/video uploads for a single form field.
+type MediaRule struct {
+	MaxDuration   time.Duration // Optional: reject longer media (0 = no limit)
+	AllowedCodecs []string      // Optional: accepted audio/video codec names (empty = any)
+}
+
+// FFProbe is a MediaProber backed by the ffprobe command-line tool.
+type FFProbe struct {
+	Path string // Optional: ffprobe binary (default "ffprobe" on PATH)
+}
+
+// Probe runs ffprobe over the file content piped through stdin.
+func (p FFProbe) Probe(ctx context.Context, file *UploadedFile) (*MediaInfo, error) {
+	path := p.Path
+	if path == "" {
+		path = "ffprobe"
+	}
+
+	cmd := exec.CommandContext(ctx, path, "-v", "error", "-print_format", "json", "-show_format", "-show_streams", "-")
+	cmd.Stdin = bytes.NewReader(file.Content)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe: %w", err)
+	}
+
+	var probe struct {
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			CodecName string `json:"codec_name"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("ffprobe: %w", err)
+	}
+
+	info := &MediaInfo{}
+	if secs, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.Duration = time.Duration(secs * float64(time.Second))
+	}
+	for _, s := range probe.Streams {
+		switch s.CodecType {
+		case "video":
+			if info.VideoCodec == "" {
+				info.VideoCodec, info.Width, info.Height = s.CodecName, s.Width, s.Height
+			}
+		case "audio":
+			if info.AudioCodec == "" {
+				info.AudioCodec = s.CodecName
+			}
+		}
+	}
+	return info, nil
+}
+
+// checkMedia probes audio/video uploads for fields with a MediaRule,
+// recording the metadata on the file. It returns a field error message or "".
+func (cfg *Config) checkMedia(ctx context.Context, field string, file *UploadedFile) string {
+	rule, ok := cfg.MediaRules[field]
+	if !ok || cfg.MediaProber == nil {
+		return ""
+	}
+	if !strings.HasPrefix(file.ContentType, "audio/") && !strings.HasPrefix(file.ContentType, "video/") {
+		return ""
+	}
+
+	var info *MediaInfo
+	err := cfg.callHook(ctx, "MediaProber", func(ctx context.Context) (err error) {
+		info, err = cfg.MediaProber.Probe(ctx, file)
+		return err
+	})
+	if errors.Is(err, errHookSkipped) {
+		return ""
+	}
+	if err != nil {
+		return fmt.Sprintf("%s could not be read as media", field)
+	}
+	file.Media = info
+
+	if rule.MaxDuration > 0 && info.Duration > rule.MaxDuration {
+		return fmt.Sprintf("%s must be at most %s long", field, rule.MaxDuration)
+	}
+	if len(rule.AllowedCodecs) > 0 {
+		for _, codec := range []string{info.VideoCodec, info.AudioCodec} {
+			if codec != "" && !containsString(rule.AllowedCodecs, codec) {
+				return fmt.Sprintf("%s uses unsupported codec %s", field, codec)
+			}
+		}
+	}
+	return ""
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package formparser
+
+import (
+	"mime"
+	"strings"
+)
+
+// parseMediaType splits a Content-Type header into its lower-cased media
+// type and its parameters, whose names are lower-cased as well, so
+// "Application/JSON; Charset=UTF-8" routes like "application/json". A
+// header whose parameters are malformed still yields its media type.
+func parseMediaType(contentType string) (string, map[string]string) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if mediaType == "" && err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	}
+	if params == nil {
+		params = map[string]string{}
+	}
+	return mediaType, params
+}
@@ -0,0 +1,237 @@
+package formparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// mergeFieldsCache maps reflect.Type to the []mergeField of its top-level
+// exported fields.
+var mergeFieldsCache sync.Map
+
+// mergeField names a top-level struct field in each submission format.
+type mergeField struct {
+	index int
+	key   string // lower-cased Go name, as used for field errors
+	form  string
+	json  string
+}
+
+// mergeFields returns the top-level exported fields of dst's struct type.
+func mergeFields(dst interface{}) []mergeField {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return structMergeFields(t)
+}
+
+// structMergeFields returns the exported fields of the struct type t.
+func structMergeFields(t reflect.Type) []mergeField {
+	if cached, ok := mergeFieldsCache.Load(t); ok {
+		return cached.([]mergeField)
+	}
+
+	var fields []mergeField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		jsonName := tagName(f.Tag.Get("json"))
+		if jsonName == "" {
+			jsonName = f.Name
+		}
+		fields = append(fields, mergeField{index: i, key: strings.ToLower(f.Name), form: formKey(f), json: jsonName})
+	}
+
+	mergeFieldsCache.Store(t, fields)
+	return fields
+}
+
+// mergeValues prepares dst for a form decode in Merge mode: it snapshots
+// dst, records which fields were submitted in res.Present and clears
+// submitted slices and maps, which the form decoder would otherwise append to.
+func (cfg *Config) mergeValues(dst interface{}, values url.Values, res *ParseResult) {
+	if !cfg.Merge {
+		return
+	}
+	cfg.snapshotMerge(dst, res)
+	res.Present = make(map[string]bool)
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, f := range mergeFields(dst) {
+		if !formKeySubmitted(values, f.form) {
+			continue
+		}
+		res.Present[f.key] = true
+		field := v.Field(f.index)
+		if field.Kind() == reflect.Slice || field.Kind() == reflect.Map {
+			field.SetZero()
+		}
+	}
+}
+
+// formKeySubmitted reports whether values hold key itself or a nested key
+// below it ("key.sub", "key[0]").
+func formKeySubmitted(values url.Values, key string) bool {
+	if _, ok := values[key]; ok {
+		return true
+	}
+	for k := range values {
+		if strings.HasPrefix(k, key+".") || strings.HasPrefix(k, key+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeJSON snapshots dst and records in res.Present which top-level fields
+// a JSON body submits. encoding/json already leaves absent fields untouched, so the body
+// only needs buffering; the returned reader replays it for decoding.
+func (cfg *Config) mergeJSON(dst interface{}, body io.Reader, res *ParseResult) (io.Reader, error) {
+	if !cfg.Merge {
+		return body, nil
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	cfg.snapshotMerge(dst, res)
+	res.Present = make(map[string]bool)
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(data, &raw) == nil {
+		for _, f := range mergeFields(dst) {
+			for name := range raw {
+				if strings.EqualFold(name, f.json) || name == f.form {
+					res.Present[f.key] = true
+				}
+			}
+		}
+	}
+	return bytes.NewReader(data), nil
+}
+
+// FieldChange is one submitted field whose value differs from the value dst
+// held before a Merge-mode parse.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// snapshotMerge records deep copies of dst's top-level fields so
+// recordChanges can compare them once the submission is decoded.
+func (cfg *Config) snapshotMerge(dst interface{}, res *ParseResult) {
+	if cfg.Merge {
+		snapshotFields(dst, res)
+	}
+}
+
+// snapshotFields implements snapshotMerge.
+func snapshotFields(dst interface{}, res *ParseResult) {
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	res.mergeBase = make(map[int]reflect.Value)
+	for _, f := range mergeFields(dst) {
+		res.mergeBase[f.index] = deepCopy(v.Field(f.index))
+	}
+}
+
+// recordChanges fills res.Changes with the submitted fields whose values
+// changed, in struct field order.
+func recordChanges(dst interface{}, res *ParseResult) {
+	if res.mergeBase == nil {
+		return
+	}
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	res.Changes = []FieldChange{}
+	for _, f := range mergeFields(dst) {
+		if !res.Present[f.key] {
+			continue
+		}
+		before, after := res.mergeBase[f.index], v.Field(f.index)
+		if reflect.DeepEqual(before.Interface(), after.Interface()) {
+			continue
+		}
+		res.Changes = append(res.Changes, FieldChange{Field: f.key, Old: before.Interface(), New: deepCopy(after).Interface()})
+	}
+	res.mergeBase = nil
+}
+
+// deepCopy copies v so that decoding into the original cannot modify the
+// copy through shared slices, maps or pointers. Values reached twice, as
+// in cyclic structures, are copied once and shared in the copy.
+func deepCopy(v reflect.Value) reflect.Value {
+	return copyValue(v, make(map[copyVisit]reflect.Value))
+}
+
+// copyVisit identifies a pointer, slice or map already being copied.
+type copyVisit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+func copyValue(v reflect.Value, seen map[copyVisit]reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			visit := copyVisit{ptr: v.Pointer(), typ: v.Type()}
+			if p, ok := seen[visit]; ok {
+				return p
+			}
+			p := reflect.New(v.Type().Elem())
+			seen[visit] = p
+			p.Elem().Set(copyValue(v.Elem(), seen))
+			out.Set(p)
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			visit := copyVisit{ptr: v.Pointer(), typ: v.Type(), len: v.Len()}
+			if s, ok := seen[visit]; ok {
+				return s
+			}
+			s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+			seen[visit] = s
+			for i := 0; i < v.Len(); i++ {
+				s.Index(i).Set(copyValue(v.Index(i), seen))
+			}
+			out.Set(s)
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			visit := copyVisit{ptr: v.Pointer(), typ: v.Type()}
+			if m, ok := seen[visit]; ok {
+				return m
+			}
+			m := reflect.MakeMapWithSize(v.Type(), v.Len())
+			seen[visit] = m
+			iter := v.MapRange()
+			for iter.Next() {
+				m.SetMapIndex(iter.Key(), copyValue(iter.Value(), seen))
+			}
+			out.Set(m)
+		}
+	case reflect.Struct:
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(copyValue(v.Field(i), seen))
+			}
+		}
+	default:
+		out.Set(v)
+	}
+	return out
+}
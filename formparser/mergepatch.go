@@ -0,0 +1,215 @@
+package formparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// errPatchNotObject rejects merge patches whose top level is not an object.
+var errPatchNotObject = errors.New("merge patch is not a JSON object")
+
+// ParseMergePatch applies an RFC 7396 JSON merge patch from r onto existing,
+// a pointer to an already-loaded struct, and validates the result like any
+// other parse. Members absent from the patch keep their values, members set
+// to null are reset to their zero value, and nested objects are merged
+// recursively.
+//
+// ParseResult.Present lists the top-level fields the patch mentioned,
+// including nulls, and ParseResult.Changes those whose value changed, so a
+// PATCH handler can tell "omitted" from "cleared". The body must be
+// application/merge-patch+json or application/json.
+func (cfg *Config) ParseMergePatch(w http.ResponseWriter, r *http.Request, existing interface{}) error {
+	res := &ParseResult{}
+	sw := &statusWriter{ResponseWriter: w}
+	var read int64
+	if r.Body != nil {
+		r.Body = &countingBody{ReadCloser: r.Body, n: &read}
+	}
+	err := cfg.parseMergePatch(sw, r, existing, res)
+	cfg.stats.bytesRead.Add(read)
+	cfg.stats.recordFailure(err, sw.status)
+	cfg.Result, cfg.Files = res, nil
+	return err
+}
+
+// parseMergePatch implements ParseMergePatch, recording details on res.
+func (cfg *Config) parseMergePatch(w http.ResponseWriter, r *http.Request, existing interface{}, res *ParseResult) error {
+	cfg.stats.parses[kindJSON].Add(1)
+	if err := cfg.checkBeforeBody(w, r); err != nil {
+		return err
+	}
+	res.MediaType, res.MediaParams = parseMediaType(r.Header.Get("Content-Type"))
+	switch res.MediaType {
+	case "application/merge-patch+json", "application/json":
+	default:
+		http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
+		return errors.New("unsupported content type")
+	}
+	v := reflect.ValueOf(existing)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		http.Error(w, "Can't apply patch", http.StatusInternalServerError)
+		return errors.New("formparser: ParseMergePatch needs a pointer to a struct")
+	}
+
+	var patch json.RawMessage
+	err := json.NewDecoder(cfg.limitJSON(r.Body)).Decode(&patch)
+	if err == nil && !isJSONObject(patch) {
+		err = errPatchNotObject
+	}
+	if err != nil {
+		return cfg.patchFailed(w, err, "Invalid merge patch")
+	}
+
+	snapshotFields(existing, res)
+	res.Present = patchedFields(existing, patch)
+	if err := applyMergePatch(v.Elem(), patch); err != nil {
+		return cfg.patchFailed(w, err, "Invalid merge patch")
+	}
+	return cfg.validateAndRespond(w, r, existing, res, nil)
+}
+
+// patchFailed responds to a patch that could not be decoded or applied,
+// with msg unless the JSON limits or shape explain the failure.
+func (cfg *Config) patchFailed(w http.ResponseWriter, err error, msg string) error {
+	var depthErr *MaxDepthError
+	var tokensErr *MaxTokensError
+	switch {
+	case errors.Is(err, errPatchNotObject):
+		msg = "Merge patch must be a JSON object"
+	case errors.As(err, &depthErr):
+		msg = "JSON body nested too deeply"
+	case errors.As(err, &tokensErr):
+		msg = "JSON body has too many elements"
+	}
+	cfg.httpError(w, msg, http.StatusBadRequest, err)
+	return err
+}
+
+// patchedFields returns the keys of the top-level fields patch mentions.
+func patchedFields(dst interface{}, patch json.RawMessage) map[string]bool {
+	var members map[string]json.RawMessage
+	_ = json.Unmarshal(patch, &members)
+	present := make(map[string]bool)
+	t := reflect.TypeOf(dst).Elem()
+	for name := range members {
+		if f, ok := patchField(t, name); ok {
+			present[f.key] = true
+		}
+	}
+	return present
+}
+
+// applyMergePatch merges patch into v following RFC 7396.
+func applyMergePatch(v reflect.Value, patch json.RawMessage) error {
+	if !isJSONObject(patch) {
+		// Non-object values replace the target outright.
+		v.SetZero()
+		return json.Unmarshal(patch, v.Addr().Interface())
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return applyMergePatch(v.Elem(), patch)
+	case reflect.Struct:
+		for name, value := range members {
+			f, ok := patchField(v.Type(), name)
+			if !ok {
+				continue // unknown members are ignored, as by encoding/json
+			}
+			field := v.Field(f.index)
+			if isJSONNull(value) {
+				field.SetZero()
+				continue
+			}
+			if err := applyMergePatch(field, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for name, value := range members {
+			key := reflect.ValueOf(name).Convert(v.Type().Key())
+			if isJSONNull(value) {
+				v.SetMapIndex(key, reflect.Value{})
+				continue
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if current := v.MapIndex(key); current.IsValid() {
+				elem.Set(current)
+			}
+			if err := applyMergePatch(elem, value); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+		return nil
+	}
+
+	// Any other target is replaced by the patch with its nulls removed.
+	v.SetZero()
+	return json.Unmarshal(withoutNulls(patch), v.Addr().Interface())
+}
+
+// patchField finds the struct field a patch member names, preferring an
+// exact json name over a case-insensitive match as encoding/json does.
+func patchField(t reflect.Type, name string) (mergeField, bool) {
+	fields := structMergeFields(t)
+	for _, f := range fields {
+		if f.json == name && t.Field(f.index).Tag.Get("json") != "-" {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.json, name) && t.Field(f.index).Tag.Get("json") != "-" {
+			return f, true
+		}
+	}
+	return mergeField{}, false
+}
+
+// withoutNulls returns the patch object with null members removed at every
+// level.
+func withoutNulls(patch json.RawMessage) json.RawMessage {
+	if !isJSONObject(patch) {
+		return patch
+	}
+	var members map[string]json.RawMessage
+	if json.Unmarshal(patch, &members) != nil {
+		return patch
+	}
+	for name, value := range members {
+		if isJSONNull(value) {
+			delete(members, name)
+		} else {
+			members[name] = withoutNulls(value)
+		}
+	}
+	data, _ := json.Marshal(members)
+	return data
+}
+
+func isJSONObject(data json.RawMessage) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
+
+func isJSONNull(data json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
@@ -0,0 +1,85 @@
+package formparser
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// MessageProvider looks up the error message for a failed validation rule.
+// lang is the request's preferred language (from Accept-Language) or "".
+type MessageProvider interface {
+	Messages(field, tag, lang string) (string, bool)
+}
+
+// MessageKey identifies a catalog entry. Tag and Lang may be empty to act as
+// wildcards for any rule or language.
+type MessageKey struct {
+	Field string
+	Tag   string
+	Lang  string
+}
+
+// MessageLoader fetches a complete message catalog, e.g. from a DB or CMS.
+type MessageLoader func(ctx context.Context) (map[MessageKey]string, error)
+
+// MessageCatalog is a MessageProvider serving an in-memory snapshot that can
+// be swapped at runtime with Reload, so lookups never hit the backing store.
+type MessageCatalog struct {
+	load     MessageLoader
+	snapshot atomic.Pointer[map[MessageKey]string]
+}
+
+// NewMessageCatalog returns an empty catalog backed by load. Call Reload to
+// populate it, and again whenever the source changes.
+func NewMessageCatalog(load MessageLoader) *MessageCatalog {
+	c := &MessageCatalog{load: load}
+	c.snapshot.Store(&map[MessageKey]string{})
+	return c
+}
+
+// Reload replaces the snapshot with a fresh one from the loader. On error the
+// previous snapshot stays in place.
+func (c *MessageCatalog) Reload(ctx context.Context) error {
+	entries, err := c.load(ctx)
+	if err != nil {
+		return err
+	}
+	c.snapshot.Store(&entries)
+	return nil
+}
+
+// Messages looks up field/tag/lang, falling back from the regional language
+// to its base ("pt-BR" to "pt"), then to any language, then to any rule.
+func (c *MessageCatalog) Messages(field, tag, lang string) (string, bool) {
+	entries := *c.snapshot.Load()
+	base, _, _ := strings.Cut(lang, "-")
+	for _, key := range []MessageKey{
+		{field, tag, lang}, {field, tag, base}, {field, tag, ""},
+		{field, "", lang}, {field, "", base}, {field, "", ""},
+	} {
+		if msg, ok := entries[key]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// requestLang returns the first language listed in Accept-Language.
+func requestLang(r *http.Request) string {
+	first, _, _ := strings.Cut(r.Header.Get("Accept-Language"), ",")
+	lang, _, _ := strings.Cut(first, ";")
+	return strings.TrimSpace(lang)
+}
+
+// lookupMessage resolves a custom message from Messages, then FieldErrorMessages.
+func (cfg *Config) lookupMessage(field, tag, lang string) (string, bool) {
+	if cfg.Messages != nil {
+		if msg, ok := cfg.Messages.Messages(field, tag, lang); ok {
+			return msg, true
+		}
+	}
+	msg, ok := cfg.FieldErrorMessages[field]
+	return msg, ok
+}
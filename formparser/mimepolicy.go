@@ -0,0 +1,155 @@
+package formparser
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// MIMEPolicy decides what happens to a file whose extension, declared
+// Content-Type and sniffed content type disagree.
+type MIMEPolicy int
+
+const (
+	// MIMETrustDeclared keeps the declared Content-Type. This is the default.
+	MIMETrustDeclared MIMEPolicy = iota
+	// MIMETrustSniffed replaces the declared Content-Type with the sniffed
+	// one, which must then be in AllowedMIMETypes.
+	MIMETrustSniffed
+	// MIMEReject reports a field error for the mismatching file.
+	MIMEReject
+	// MIMERename behaves like MIMETrustSniffed and also gives the filename
+	// the extension of the sniffed type.
+	MIMERename
+)
+
+// String returns the policy's name.
+func (p MIMEPolicy) String() string {
+	switch p {
+	case MIMETrustDeclared:
+		return "trust_declared"
+	case MIMETrustSniffed:
+		return "trust_sniffed"
+	case MIMEReject:
+		return "reject"
+	case MIMERename:
+		return "rename"
+	default:
+		return fmt.Sprintf("MIMEPolicy(%d)", int(p))
+	}
+}
+
+// MarshalText lets policies appear by name in EffectiveConfig.
+func (p MIMEPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// preferredExtensions picks the conventional extension for types that
+// mime.ExtensionsByType lists several extensions for.
+var preferredExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/bmp":       ".bmp",
+	"application/pdf": ".pdf",
+	"application/zip": ".zip",
+	"text/plain":      ".txt",
+	"text/html":       ".html",
+	"audio/mpeg":      ".mp3",
+	"video/mp4":       ".mp4",
+}
+
+// checkMIMEPolicy applies the field's MIMEPolicy when the file's extension,
+// declared type and sniffed type disagree. Types are compared after
+// resolving aliases such as image/jpg. Types that cannot be determined (no
+// extension, content sniffed as application/octet-stream) never count as a
+// mismatch, nor does text/plain, which sniffing reports for any text such as
+// CSV or JSON.
+func (cfg *Config) checkMIMEPolicy(field string, file *UploadedFile) string {
+	policy := cfg.MIMEPolicies[field]
+	if policy == MIMETrustDeclared {
+		return ""
+	}
+
+	declared := baseMediaType(file.ContentType)
+	sniffed := baseMediaType(http.DetectContentType(file.Content))
+	if sniffed == "application/octet-stream" || sniffed == "text/plain" {
+		sniffed = ""
+	}
+	byExt := baseMediaType(mime.TypeByExtension(fileExt(file)))
+	if !typesDisagree(declared, sniffed, byExt) {
+		return ""
+	}
+
+	switch policy {
+	case MIMEReject:
+		return fmt.Sprintf("%s content does not match its file type", field)
+	case MIMETrustSniffed, MIMERename:
+		if sniffed == "" {
+			return fmt.Sprintf("%s content does not match its file type", field)
+		}
+		if !cfg.isAllowedContentType(sniffed) {
+			return fmt.Sprintf("%s has an unsupported file type", field)
+		}
+		file.ContentType = sniffed
+		if policy == MIMERename {
+			file.Filename = renameExt(file.Filename, sniffed)
+		}
+	}
+	return ""
+}
+
+// mediaTypeAliases maps non-standard names clients and mime tables use to
+// the canonical media type.
+var mediaTypeAliases = map[string]string{
+	"image/jpg":                    "image/jpeg",
+	"image/pjpeg":                  "image/jpeg",
+	"image/x-png":                  "image/png",
+	"image/x-ms-bmp":               "image/bmp",
+	"text/xml":                     "application/xml",
+	"application/x-pdf":            "application/pdf",
+	"application/x-zip-compressed": "application/zip",
+	"audio/mp3":                    "audio/mpeg",
+	"text/javascript":              "application/javascript",
+}
+
+// typesDisagree reports whether any two of the known types differ once
+// aliases are resolved.
+func typesDisagree(types ...string) bool {
+	seen := ""
+	for _, t := range types {
+		if t == "" {
+			continue
+		}
+		if alias, ok := mediaTypeAliases[t]; ok {
+			t = alias
+		}
+		if seen != "" && t != seen {
+			return true
+		}
+		seen = t
+	}
+	return false
+}
+
+// baseMediaType strips parameters and case from a media type.
+func baseMediaType(contentType string) string {
+	base, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(base))
+}
+
+// renameExt replaces the extension of filename with one for contentType.
+func renameExt(filename, contentType string) string {
+	ext, ok := preferredExtensions[contentType]
+	if !ok {
+		exts, _ := mime.ExtensionsByType(contentType)
+		if len(exts) == 0 {
+			return filename
+		}
+		ext = exts[0]
+	}
+	return strings.TrimSuffix(filename, path.Ext(filename)) + ext
+}
@@ -0,0 +1,93 @@
+package formparser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// Part is one body part of a multipart/mixed or multipart/related request.
+type Part struct {
+	Header      textproto.MIMEHeader
+	ContentType string
+	ContentID   string // the Content-ID header without its angle brackets
+	Filename    string
+	Content     []byte
+	Root        bool // the part decoded into dst
+}
+
+// parseMixed handles multipart/mixed and multipart/related payloads, the
+// shape of "JSON metadata plus binary attachments" APIs. Every part is kept
+// in ParseResult.Parts with its headers. The root part, named by the
+// related "start" parameter or else the first part, is decoded into dst
+// when it is JSON; otherwise dst is validated as is. Non-root parts must
+// have an allowed content type and fit in MaxFileSize.
+func (cfg *Config) parseMixed(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	if res.MediaParams["boundary"] == "" {
+		http.Error(w, "Can't parse multipart", http.StatusBadRequest)
+		return errors.New("missing multipart boundary")
+	}
+	start := strings.Trim(res.MediaParams["start"], "<>")
+
+	r.Body = cfg.guardBody(r.Body)
+	mr := multipart.NewReader(r.Body, res.MediaParams["boundary"])
+	maxFileSize := cfg.maxFileSize()
+	var root *Part
+	for {
+		p, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return readFailed(w, r.Body, err, "Can't parse multipart", http.StatusBadRequest)
+		}
+		var buf bytes.Buffer
+		n, err := cfg.copyLimited(&buf, p, maxFileSize+1)
+		p.Close()
+		if err != nil {
+			return readFailed(w, r.Body, err, "Can't parse multipart", http.StatusBadRequest)
+		}
+		if n > maxFileSize {
+			http.Error(w, "Part too large", http.StatusRequestEntityTooLarge)
+			return fmt.Errorf("part too large: %d bytes", n)
+		}
+		part := &Part{
+			Header:      p.Header,
+			ContentType: p.Header.Get("Content-Type"),
+			ContentID:   strings.Trim(p.Header.Get("Content-ID"), "<>"),
+			Filename:    p.FileName(),
+			Content:     buf.Bytes(),
+		}
+		if part.ContentType == "" {
+			part.ContentType = "text/plain; charset=us-ascii" // RFC 2046 default
+		}
+		if root == nil && (start == "" || part.ContentID == start) {
+			root, part.Root = part, true
+		}
+		res.Parts = append(res.Parts, part)
+	}
+	if root == nil {
+		http.Error(w, "Can't parse multipart: missing root part", http.StatusBadRequest)
+		return errors.New("missing root part")
+	}
+	for _, part := range res.Parts {
+		mediaType, _ := parseMediaType(part.ContentType)
+		if !part.Root && !cfg.isAllowedContentType(mediaType) {
+			http.Error(w, "Unsupported file type", http.StatusBadRequest)
+			return fmt.Errorf("unsupported file type: %s", part.ContentType)
+		}
+	}
+
+	if rootType, _ := parseMediaType(root.ContentType); rootType != "application/json" && !hasStructuredSuffix(rootType, "json") {
+		return cfg.validateAndRespond(w, r, dst, res, nil)
+	}
+	rootReq := r.Clone(r.Context())
+	rootReq.Header.Set("Content-Type", root.ContentType)
+	rootReq.Body = io.NopCloser(bytes.NewReader(root.Content))
+	return cfg.parseJSON(w, rootReq, dst, res)
+}
@@ -0,0 +1,89 @@
+package formparser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Mode selects how much internal detail error responses carry. Either way
+// the error returned to the caller keeps the full details for logging.
+type Mode int
+
+const (
+	// ModeRelease answers with sanitized, client-safe messages such as
+	// "Invalid JSON body".
+	ModeRelease Mode = iota
+	// ModeDebug appends the underlying error to plain-text responses, with
+	// decode offsets where known, and adds each failed field's struct path
+	// and rule to validation error bodies. Use it in development only.
+	ModeDebug
+)
+
+// String returns the mode's name.
+func (m Mode) String() string {
+	switch m {
+	case ModeRelease:
+		return "release"
+	case ModeDebug:
+		return "debug"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+func parseMode(name string) (Mode, bool) {
+	for _, m := range []Mode{ModeRelease, ModeDebug} {
+		if name == "" || strings.EqualFold(name, m.String()) {
+			return m, true
+		}
+	}
+	return ModeRelease, false
+}
+
+// httpError writes msg with status, followed by err's details in ModeDebug.
+func (cfg *Config) httpError(w http.ResponseWriter, msg string, status int, err error) {
+	http.Error(w, cfg.debugMessage(msg, err), status)
+}
+
+// debugMessage returns msg, followed by err's details in ModeDebug.
+func (cfg *Config) debugMessage(msg string, err error) string {
+	if cfg.Mode == ModeDebug && err != nil {
+		msg += ": " + redactPANs(debugDetail(err))
+	}
+	return msg
+}
+
+// debugDetail describes err, adding the byte offset of JSON decode errors.
+func debugDetail(err error) string {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Sprintf("%v (offset %d)", err, syntaxErr.Offset)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fmt.Sprintf("%v (offset %d)", err, typeErr.Offset)
+	}
+	return err.Error()
+}
+
+// FieldDebug is what ModeDebug adds to a validation error body about a
+// field: the struct path and the rule that failed.
+type FieldDebug struct {
+	Namespace string `json:"namespace"`       // e.g. "SignupForm.Address.Zip"
+	Rule      string `json:"rule,omitempty"`  // the validator tag, e.g. "email"
+	Param     string `json:"param,omitempty"` // the tag's parameter, e.g. "8" for min=8
+}
+
+// fieldDebug returns the debug details of the validator errors in res.
+func fieldDebug(res *ParseResult, fieldErrors FieldErrors) map[string]FieldDebug {
+	debug := make(map[string]FieldDebug)
+	for field := range fieldErrors {
+		if d, ok := res.errorDetails[field]; ok {
+			debug[field] = FieldDebug{Namespace: d.namespace, Rule: d.code, Param: d.param}
+		}
+	}
+	return debug
+}
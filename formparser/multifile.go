@@ -0,0 +1,92 @@
+package formparser
+
+import (
+	"net/url"
+	"reflect"
+	"sync"
+)
+
+// uploadedFilesType is the type of []*UploadedFile fields.
+var uploadedFilesType = reflect.TypeOf([]*UploadedFile(nil))
+
+// addFile records file as uploaded under field. AllFiles keeps every file
+// in order; Files keeps the last, as it always has.
+func (res *ParseResult) addFile(field string, file *UploadedFile) {
+	if res.Files == nil {
+		res.Files = make(map[string]*UploadedFile)
+	}
+	if res.AllFiles == nil {
+		res.AllFiles = make(map[string][]*UploadedFile)
+	}
+	res.Files[field] = file
+	res.AllFiles[field] = append(res.AllFiles[field], file)
+}
+
+// FilesFor returns every file uploaded under field in request order, so
+// `<input type="file" multiple>` fields keep all their files. It falls
+// back to Files for results built without AllFiles.
+func (res *ParseResult) FilesFor(field string) []*UploadedFile {
+	if files, ok := res.AllFiles[field]; ok {
+		return files
+	}
+	if file := res.Files[field]; file != nil {
+		return []*UploadedFile{file}
+	}
+	return nil
+}
+
+// uploadFieldsCache maps reflect.Type to the []mergeField of its
+// *UploadedFile and []*UploadedFile fields.
+var uploadFieldsCache sync.Map
+
+// uploadFields returns the top-level *UploadedFile and []*UploadedFile
+// fields of dst.
+func uploadFields(dst interface{}) []mergeField {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := uploadFieldsCache.Load(t); ok {
+		return cached.([]mergeField)
+	}
+
+	var fields []mergeField
+	for _, f := range structMergeFields(t) {
+		if ft := t.Field(f.index).Type; (ft == uploadedFileType || ft == uploadedFilesType) && f.form != "-" {
+			fields = append(fields, f)
+		}
+	}
+
+	uploadFieldsCache.Store(t, fields)
+	return fields
+}
+
+// takeUploadValues removes the values of dst's upload fields, which hold
+// file hashes the form decoder cannot put into them.
+func takeUploadValues(dst interface{}, values url.Values) {
+	for _, f := range uploadFields(dst) {
+		delete(values, f.form)
+	}
+}
+
+// setUploads stores the files of a multipart request in dst's upload
+// fields: the last file, as in Files, in *UploadedFile fields and all of
+// them in []*UploadedFile fields. Fields without uploads are left alone.
+func setUploads(dst interface{}, res *ParseResult) {
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, f := range uploadFields(dst) {
+		files := res.FilesFor(f.form)
+		if len(files) == 0 {
+			continue
+		}
+		field := v.Field(f.index)
+		if field.Type() == uploadedFileType {
+			field.Set(reflect.ValueOf(files[len(files)-1]))
+		} else {
+			field.Set(reflect.ValueOf(append([]*UploadedFile(nil), files...)))
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package formparser
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxNDJSONLine caps the length of a single NDJSON record.
+const maxNDJSONLine = 1 << 20
+
+// RecordError reports why one NDJSON record or CSV row was rejected. Line
+// is 1-based and counts blank lines, so it matches what editors show.
+type RecordError struct {
+	Line    int         `json:"line"`
+	Message string      `json:"message,omitempty"` // set when the line could not be decoded
+	Fields  FieldErrors `json:"fields,omitempty"`
+}
+
+// RecordErrors is returned by ParseNDJSON and CSV bodies when some records
+// were rejected.
+type RecordErrors []RecordError
+
+func (re RecordErrors) Error() string {
+	msgs := make([]string, len(re))
+	for i, e := range re {
+		if e.Fields != nil {
+			msgs[i] = fmt.Sprintf("line %d: %s", e.Line, e.Fields.Error())
+		} else {
+			msgs[i] = fmt.Sprintf("line %d: %s", e.Line, e.Message)
+		}
+	}
+	return "record errors: " + strings.Join(msgs, "; ")
+}
+
+// ParseNDJSON streams an application/x-ndjson body, decoding each line into
+// a fresh T, validating it like a JSON body and passing it to fn. Only one
+// record is held in memory at a time, so bodies may have any number of lines.
+//
+// Rejected records are skipped and collected; once the body is consumed they
+// are written as a 400 response listing each record's line and errors, and
+// returned as RecordErrors. fn may return FieldErrors to reject a record
+// itself; any other error from fn aborts the parse with a 500.
+func ParseNDJSON[T any](cfg *Config, w http.ResponseWriter, r *http.Request, fn func(line int, record *T) error) error {
+	sw := &statusWriter{ResponseWriter: w}
+	var read int64
+	if r.Body != nil {
+		r.Body = &countingBody{ReadCloser: r.Body, n: &read}
+	}
+	err := parseNDJSON(cfg, sw, r, fn)
+	cfg.stats.bytesRead.Add(read)
+	cfg.stats.recordFailure(err, sw.status)
+	return err
+}
+
+// parseNDJSON implements ParseNDJSON.
+func parseNDJSON[T any](cfg *Config, w http.ResponseWriter, r *http.Request, fn func(line int, record *T) error) error {
+	cfg.stats.parses[kindNDJSON].Add(1)
+	if err := cfg.checkBeforeBody(w, r); err != nil {
+		return err
+	}
+	if mediaType, _ := parseMediaType(r.Header.Get("Content-Type")); mediaType != "application/x-ndjson" {
+		http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
+		return errors.New("unsupported content type")
+	}
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+	var recordErrors RecordErrors
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		record := new(T)
+		if err := cfg.decodeJSONBody(cfg.limitJSON(bytes.NewReader(data)), record); err != nil {
+			recordErrors = append(recordErrors, RecordError{Line: line, Message: ndjsonDecodeMessage(err)})
+			continue
+		}
+		fieldErrors, err := cfg.validateFields(r, record, &ParseResult{}, nil)
+		var f *validationFailure
+		if errors.As(err, &f) {
+			cfg.httpError(w, f.msg, f.status, f.err)
+			return f.err
+		}
+		if len(fieldErrors) > 0 {
+			recordErrors = append(recordErrors, RecordError{Line: line, Fields: fieldErrors})
+			continue
+		}
+
+		err = fn(line, record)
+		var errs FieldErrors
+		if errors.As(err, &errs) {
+			recordErrors = append(recordErrors, RecordError{Line: line, Fields: errs})
+			continue
+		}
+		if err != nil {
+			http.Error(w, "Can't process record", http.StatusInternalServerError)
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			http.Error(w, "NDJSON record too long", http.StatusRequestEntityTooLarge)
+			return err
+		}
+		http.Error(w, "Error reading body", http.StatusBadRequest)
+		return err
+	}
+
+	if len(recordErrors) > 0 {
+		cfg.respondRecordErrors(w, r, recordErrors)
+		return recordErrors
+	}
+	return nil
+}
+
+// respondRecordErrors writes the validation failure JSON for rejected
+// records, masking card numbers like respondFieldErrors.
+func (cfg *Config) respondRecordErrors(w http.ResponseWriter, r *http.Request, recordErrors RecordErrors) {
+	for _, e := range recordErrors {
+		for field, msg := range e.Fields {
+			e.Fields[field] = redactPANs(msg)
+		}
+	}
+	cfg.writeErrorJSON(w, r, map[string]any{
+		"message": "Validation failed",
+		"records": recordErrors,
+	})
+}
+
+// ndjsonDecodeMessage describes why a line could not be decoded.
+func ndjsonDecodeMessage(err error) string {
+	var depthErr *MaxDepthError
+	if errors.As(err, &depthErr) {
+		return "JSON record nested too deeply"
+	}
+	var tokensErr *MaxTokensError
+	if errors.As(err, &tokensErr) {
+		return "JSON record has too many elements"
+	}
+	return "Invalid JSON record"
+}
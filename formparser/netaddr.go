@@ -0,0 +1,183 @@
+package formparser
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	addrType         = reflect.TypeOf(netip.Addr{})
+	prefixType       = reflect.TypeOf(netip.Prefix{})
+	addrPortType     = reflect.TypeOf(netip.AddrPort{})
+	hardwareAddrType = reflect.TypeOf(net.HardwareAddr{})
+)
+
+// networkField is a netip.Addr, netip.Prefix, netip.AddrPort or
+// net.HardwareAddr field, or a pointer to one.
+type networkField struct {
+	index int
+	key   string // form key
+	name  string // field error key
+	typ   reflect.Type
+}
+
+// networkFieldsCache maps reflect.Type to []networkField.
+var networkFieldsCache sync.Map
+
+// networkFields returns the top-level network address fields of dst.
+func networkFields(dst interface{}) []networkField {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := networkFieldsCache.Load(t); ok {
+		return cached.([]networkField)
+	}
+
+	var fields []networkField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if !f.IsExported() || (ft != addrType && ft != prefixType && ft != addrPortType && ft != hardwareAddrType) {
+			continue
+		}
+		fields = append(fields, networkField{index: i, key: formKey(f), name: strings.ToLower(f.Name), typ: ft})
+	}
+
+	networkFieldsCache.Store(t, fields)
+	return fields
+}
+
+// decodeNetworkValues parses values for network address fields straight
+// into dst and removes them from values, reporting what is wrong with
+// unparsable input in fieldErrors. Empty values leave the field unset.
+func decodeNetworkValues(dst interface{}, values url.Values, fieldErrors FieldErrors) {
+	fields := networkFields(dst)
+	if len(fields) == 0 {
+		return
+	}
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, f := range fields {
+		raw, ok := values[f.key]
+		if !ok {
+			continue
+		}
+		delete(values, f.key)
+		s := ""
+		if len(raw) > 0 {
+			s = strings.TrimSpace(raw[0])
+		}
+		if s == "" {
+			continue
+		}
+
+		parsed, msg := parseNetworkValue(f.typ, s)
+		if msg != "" {
+			fieldErrors[f.name] = f.name + " " + msg
+			continue
+		}
+		field := v.Field(f.index)
+		if field.Kind() == reflect.Ptr {
+			p := reflect.New(f.typ)
+			p.Elem().Set(parsed)
+			parsed = p
+		}
+		field.Set(parsed)
+	}
+}
+
+// parseNetworkValue parses s as typ, returning a message fragment that
+// explains the problem when it fails.
+func parseNetworkValue(typ reflect.Type, s string) (reflect.Value, string) {
+	switch typ {
+	case addrType:
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return reflect.Value{}, "must be a valid IP address"
+		}
+		return reflect.ValueOf(addr), ""
+
+	case prefixType:
+		addrPart, bitsPart, found := strings.Cut(s, "/")
+		if !found {
+			return reflect.Value{}, "must include a prefix length, e.g. 10.0.0.0/8"
+		}
+		addr, err := netip.ParseAddr(addrPart)
+		if err != nil {
+			return reflect.Value{}, "must start with a valid IP address"
+		}
+		if bits, err := strconv.Atoi(bitsPart); err != nil || bits < 0 || bits > addr.BitLen() {
+			return reflect.Value{}, fmt.Sprintf("prefix length must be between 0 and %d", addr.BitLen())
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return reflect.Value{}, "must be a valid CIDR prefix"
+		}
+		return reflect.ValueOf(prefix), ""
+
+	case addrPortType:
+		host, port, err := net.SplitHostPort(s)
+		if err != nil {
+			return reflect.Value{}, "must be an IP address and port, e.g. 10.0.0.1:8080"
+		}
+		if _, err := netip.ParseAddr(host); err != nil {
+			return reflect.Value{}, "must start with a valid IP address"
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+			return reflect.Value{}, "port must be between 0 and 65535"
+		}
+		addrPort, err := netip.ParseAddrPort(s)
+		if err != nil {
+			return reflect.Value{}, "must be an IP address and port, e.g. 10.0.0.1:8080"
+		}
+		return reflect.ValueOf(addrPort), ""
+
+	case hardwareAddrType:
+		mac, err := net.ParseMAC(s)
+		if err != nil {
+			return reflect.Value{}, "must be a valid MAC address, e.g. 00:1a:2b:3c:4d:5e"
+		}
+		return reflect.ValueOf(mac), ""
+	}
+	return reflect.Value{}, "has an unsupported type"
+}
+
+// RegisterNetworkValidators lets validate tags check netip.Addr,
+// netip.Prefix, netip.AddrPort and net.HardwareAddr fields through their
+// string form, so rules such as `validate:"required,ipv4"` or
+// `validate:"cidrv6"` work on the parsed types. Unset values validate as "".
+func RegisterNetworkValidators(v *validator.Validate) {
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		switch value := field.Interface().(type) {
+		case netip.Addr:
+			if value.IsValid() {
+				return value.String()
+			}
+		case netip.Prefix:
+			if value.IsValid() {
+				return value.String()
+			}
+		case netip.AddrPort:
+			if value.IsValid() {
+				return value.String()
+			}
+		case net.HardwareAddr:
+			return value.String()
+		}
+		return ""
+	}, netip.Addr{}, netip.Prefix{}, netip.AddrPort{}, net.HardwareAddr{})
+}
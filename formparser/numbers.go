@@ -0,0 +1,148 @@
+package formparser
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NumberLocaleAuto selects the number format from the request's Accept-Language.
+const NumberLocaleAuto = "auto"
+
+// decimalCommaLanguages lists base languages that write 12,5 for twelve and a half.
+var decimalCommaLanguages = map[string]bool{
+	"bg": true, "cs": true, "da": true, "de": true, "el": true, "es": true, "et": true,
+	"fi": true, "fr": true, "hr": true, "hu": true, "id": true, "it": true, "lt": true,
+	"lv": true, "nb": true, "nl": true, "nn": true, "no": true, "pl": true, "pt": true,
+	"ro": true, "ru": true, "sk": true, "sl": true, "sr": true, "sv": true, "tr": true,
+	"uk": true, "vi": true,
+}
+
+// floatField is a float-typed field subject to locale-aware coercion.
+type floatField struct {
+	key  string // form key
+	name string // field error key
+	bits int
+}
+
+// floatFieldsCache maps reflect.Type to []floatField.
+var floatFieldsCache sync.Map
+
+// floatFields returns the top-level float, *float and []float fields of dst.
+func floatFields(dst interface{}) []floatField {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := floatFieldsCache.Load(t); ok {
+		return cached.([]floatField)
+	}
+
+	var fields []floatField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr || ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		if !f.IsExported() || (ft.Kind() != reflect.Float32 && ft.Kind() != reflect.Float64) {
+			continue
+		}
+		fields = append(fields, floatField{key: formKey(f), name: strings.ToLower(f.Name), bits: ft.Bits()})
+	}
+
+	floatFieldsCache.Store(t, fields)
+	return fields
+}
+
+// coerceNumbers rewrites locale-formatted float values ("1.234,5") into the
+// canonical form strconv understands, reporting values that still fail.
+func (cfg *Config) coerceNumbers(r *http.Request, dst interface{}, values url.Values) FieldErrors {
+	if cfg.NumberLocale == "" {
+		return nil
+	}
+	locale := cfg.NumberLocale
+	if locale == NumberLocaleAuto {
+		locale = requestLang(r)
+	}
+	base, _, _ := strings.Cut(strings.ToLower(locale), "-")
+	decimalComma := decimalCommaLanguages[base]
+
+	fieldErrors := make(FieldErrors)
+	for _, f := range floatFields(dst) {
+		raw, ok := values[f.key]
+		if !ok {
+			continue
+		}
+		for i, v := range raw {
+			v, ok := normalizeNumber(v, decimalComma)
+			if !ok {
+				fieldErrors[f.name] = fmt.Sprintf("%s must be a number", f.name)
+				continue
+			}
+			if v == "" {
+				raw[i] = v
+				continue
+			}
+			if _, err := strconv.ParseFloat(v, f.bits); err != nil {
+				fieldErrors[f.name] = fmt.Sprintf("%s must be a number", f.name)
+				continue
+			}
+			raw[i] = v
+		}
+	}
+	return fieldErrors
+}
+
+// groupSeparators replaces every grouping separator a locale may use with
+// '\x00', so they can be checked in one pass.
+func groupSeparators(group string) *strings.Replacer {
+	return strings.NewReplacer(group, "\x00", " ", "\x00", "\u00a0", "\x00", "\u202f", "\x00", "'", "\x00")
+}
+
+var (
+	pointGroups = groupSeparators(".")
+	commaGroups = groupSeparators(",")
+)
+
+// normalizeNumber strips grouping separators and converts the decimal
+// separator to '.'. It reports false when a grouping separator follows the
+// decimal separator or does not split the integer part into groups of
+// three digits, so "1.5" in a decimal-comma locale is rejected rather than
+// read as 15.
+func normalizeNumber(v string, decimalComma bool) (string, bool) {
+	v = strings.TrimSpace(v)
+	groups, decimal := commaGroups, "."
+	if decimalComma {
+		groups, decimal = pointGroups, ","
+	}
+	v = groups.Replace(v)
+	intPart, frac, hasFrac := strings.Cut(v, decimal)
+	if strings.Contains(frac, "\x00") {
+		return "", false
+	}
+	sign := ""
+	if intPart != "" && (intPart[0] == '-' || intPart[0] == '+') {
+		sign, intPart = intPart[:1], intPart[1:]
+	}
+	parts := strings.Split(intPart, "\x00")
+	if len(parts) > 1 {
+		for i, part := range parts {
+			if part == "" || len(part) > 3 || i > 0 && len(part) != 3 {
+				return "", false
+			}
+		}
+	}
+	v = sign + strings.Join(parts, "")
+	if hasFrac {
+		v += "." + frac
+	}
+	return v, true
+}
@@ -0,0 +1,205 @@
+package formparser
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Submission is an accepted request as recorded in the Outbox: the decoded
+// struct and references to its stored files, enough to process it again
+// if the handler fails after the parse. Secrets are kept out of Data as in
+// Echo: fields tagged sensitive:"true" (or any value other than "last4" and
+// "mask") and PasswordPolicies fields are left out, sensitive:"last4" and
+// `cc:"pan"` fields keep only their last four characters, and
+// sensitive:"mask" fields are masked entirely.
+type Submission struct {
+	ID         string               `json:"id"` // a ULID, also set as ParseResult.SubmissionID
+	ReceivedAt time.Time            `json:"received_at"`
+	Method     string               `json:"method"`
+	Path       string               `json:"path"`
+	MediaType  string               `json:"media_type"`
+	Data       json.RawMessage      `json:"data"` // dst as JSON with secrets masked; files appear as FileRefs
+	Files      map[string][]FileRef `json:"files,omitempty"`
+}
+
+// Outbox persists accepted submissions before the parse returns, so a
+// submission the client was told succeeded is never lost to a handler
+// crash. Save should commit durably (e.g. in a database transaction) before
+// returning; the handler marks the submission done, by its ID, once it has
+// processed it, and a worker reprocesses those left pending.
+type Outbox interface {
+	Save(ctx context.Context, sub *Submission) error
+}
+
+// OutboxFunc adapts an ordinary function to the Outbox interface.
+type OutboxFunc func(ctx context.Context, sub *Submission) error
+
+// Save calls f(ctx, sub).
+func (f OutboxFunc) Save(ctx context.Context, sub *Submission) error {
+	return f(ctx, sub)
+}
+
+// saveSubmission records a successfully parsed request in the Outbox and
+// sets res.SubmissionID. A failed save fails the parse with a 500, so the
+// handler only runs for persisted submissions.
+func (cfg *Config) saveSubmission(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	if cfg.Outbox == nil {
+		return nil
+	}
+	data, err := json.Marshal(cfg.submissionValue(dst))
+	if err != nil {
+		cfg.httpError(w, "Can't persist submission", http.StatusInternalServerError, err)
+		return err
+	}
+	now := cfg.now()
+	sub := &Submission{
+		ID:         newULID(now, cfg.random()),
+		ReceivedAt: now.UTC(),
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		MediaType:  res.MediaType,
+		Data:       data,
+		Files:      submissionFiles(res),
+	}
+	err = cfg.callHook(r.Context(), "Outbox", func(ctx context.Context) error {
+		return cfg.Outbox.Save(ctx, sub)
+	})
+	if errors.Is(err, errHookSkipped) {
+		return nil
+	}
+	if err != nil {
+		cfg.httpError(w, "Can't persist submission", http.StatusInternalServerError, err)
+		return err
+	}
+	res.SubmissionID = sub.ID
+	return nil
+}
+
+// submissionFiles returns the references of every file of res by field.
+func submissionFiles(res *ParseResult) map[string][]FileRef {
+	if len(res.Files) == 0 {
+		return nil
+	}
+	files := make(map[string][]FileRef, len(res.Files))
+	for field := range res.Files {
+		for _, file := range res.FilesFor(field) {
+			files[field] = append(files[field], file.Ref())
+		}
+	}
+	return files
+}
+
+// submissionValue returns dst as a JSON-encodable tree with the secrets
+// Submission describes masked or left out, at any depth.
+func (cfg *Config) submissionValue(dst interface{}) any {
+	return cfg.redact(reflect.ValueOf(dst), true, make(map[uintptr]bool))
+}
+
+// redact converts v like encoding/json would, applying sensitive tags to
+// struct fields. top marks dst itself, whose PasswordPolicies fields are
+// left out. seen holds the pointers on the current path, so cycles end in
+// null instead of recursing forever.
+func (cfg *Config) redact(v reflect.Value, top bool, seen map[uintptr]bool) any {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return cfg.redact(v.Elem(), false, seen)
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return nil
+		}
+		if marshalsItself(v.Type()) {
+			return v.Interface()
+		}
+		seen[v.Pointer()] = true
+		defer delete(seen, v.Pointer())
+		return cfg.redact(v.Elem(), top, seen)
+	case reflect.Struct:
+		if marshalsItself(v.Type()) || marshalsItself(reflect.PointerTo(v.Type())) && v.CanAddr() {
+			return v.Interface()
+		}
+		out := make(map[string]any)
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			field := v.Field(i)
+			if strings.Contains(opts, "omitempty") && field.IsZero() {
+				continue
+			}
+			if top {
+				if _, ok := cfg.PasswordPolicies[strings.ToLower(f.Name)]; ok {
+					continue
+				}
+			}
+			switch sensitiveTag(f) {
+			case "":
+				out[name] = cfg.redact(field, false, seen)
+			case "last4":
+				out[name] = maskValue(field, 4)
+			case "mask":
+				out[name] = maskValue(field, 0)
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface() // []byte encodes as base64
+		}
+		fallthrough
+	case reflect.Array:
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = cfg.redact(v.Index(i), false, seen)
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if marshalsItself(v.Type()) {
+			return v.Interface()
+		}
+		out := make(map[string]any, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			out[mapKeyString(iter.Key())] = cfg.redact(iter.Value(), false, seen)
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+// mapKeyString formats a map key as encoding/json does for the common
+// cases: strings as is, text marshalers by their text, others by fmt.
+func mapKeyString(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if m, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(k.Interface())
+}
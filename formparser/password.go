@@ -0,0 +1,221 @@
+package formparser
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Password error codes, reported in ParseResult.ErrorCodes and in the
+// "codes" member of the validation failure response so frontends can map
+// them to hints. Each code is also the tag under which a custom message is
+// looked up in Messages and FieldErrorMessages.
+const (
+	PasswordTooShort     = "password_too_short"
+	PasswordLowEntropy   = "password_low_entropy"
+	PasswordPersonalInfo = "password_personal_info"
+	PasswordBreached     = "password_breached"
+)
+
+// PasswordPolicy configures the checks for one password field.
+type PasswordPolicy struct {
+	MinLength  int      // minimum length in characters
+	MinEntropy float64  // minimum estimated entropy in bits, e.g. 50
+	Personal   []string // sibling fields (by Go name, any case) whose values must not appear in the password
+	Breached   bool     // reject passwords known to Config.BreachChecker
+}
+
+// BreachChecker looks up breached passwords by k-anonymity: it receives only
+// the first five hex characters of the password's SHA-1 and returns the
+// upper-case suffixes of breached hashes sharing that prefix.
+type BreachChecker interface {
+	BreachedSuffixes(ctx context.Context, prefix string) (map[string]bool, error)
+}
+
+// HIBPClient is a BreachChecker backed by the Have I Been Pwned range API.
+type HIBPClient struct {
+	Client  *http.Client // default http.DefaultClient
+	BaseURL string       // default https://api.pwnedpasswords.com/range/
+}
+
+// BreachedSuffixes fetches the range for prefix. Padding entries, which the
+// API reports with a count of zero, are skipped.
+func (c *HIBPClient) BreachedSuffixes(ctx context.Context, prefix string) (map[string]bool, error) {
+	client, baseURL := c.Client, c.BaseURL
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if baseURL == "" {
+		baseURL = "https://api.pwnedpasswords.com/range/"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+prefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Add-Padding", "true")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("formparser: breach range lookup: %s", resp.Status)
+	}
+
+	suffixes := make(map[string]bool)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		suffix, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if n, err := strconv.Atoi(count); err == nil && n > 0 {
+			suffixes[strings.ToUpper(suffix)] = true
+		}
+	}
+	return suffixes, scanner.Err()
+}
+
+// checkPasswords applies PasswordPolicies to dst's top-level string fields,
+// reporting the first failed check per field as a field error and every
+// failed check in res.ErrorCodes.
+func (cfg *Config) checkPasswords(r *http.Request, dst interface{}, res *ParseResult) (FieldErrors, error) {
+	if len(cfg.PasswordPolicies) == 0 {
+		return nil, nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	if v.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	fieldErrors := make(FieldErrors)
+	lang := requestLang(r)
+	for name, policy := range cfg.PasswordPolicies {
+		field := fieldByLowerName(v, name)
+		if !field.IsValid() || field.Kind() != reflect.String || field.String() == "" {
+			continue // required-ness is left to the validator
+		}
+		codes, err := cfg.passwordCodes(r.Context(), v, field.String(), policy)
+		if err != nil {
+			return nil, err
+		}
+		if len(codes) == 0 {
+			continue
+		}
+		if res.ErrorCodes == nil {
+			res.ErrorCodes = make(map[string][]string)
+		}
+		res.ErrorCodes[name] = codes
+		if msg, ok := cfg.lookupMessage(name, codes[0], lang); ok {
+			fieldErrors[name] = msg
+		} else {
+			fieldErrors[name] = passwordMessage(name, codes[0], policy)
+		}
+	}
+	return fieldErrors, nil
+}
+
+// passwordCodes returns the codes of the checks password fails.
+func (cfg *Config) passwordCodes(ctx context.Context, v reflect.Value, password string, policy PasswordPolicy) ([]string, error) {
+	var codes []string
+	if len([]rune(password)) < policy.MinLength {
+		codes = append(codes, PasswordTooShort)
+	}
+	if policy.MinEntropy > 0 && PasswordEntropy(password) < policy.MinEntropy {
+		codes = append(codes, PasswordLowEntropy)
+	}
+	lower := strings.ToLower(password)
+	for _, name := range policy.Personal {
+		sibling := fieldByLowerName(v, strings.ToLower(name))
+		if sibling.Kind() != reflect.String {
+			continue
+		}
+		// Ignore very short values, which would match by accident.
+		if value := strings.ToLower(strings.TrimSpace(sibling.String())); len(value) >= 3 && strings.Contains(lower, value) {
+			codes = append(codes, PasswordPersonalInfo)
+			break
+		}
+	}
+	if policy.Breached && cfg.BreachChecker != nil {
+		sum := fmt.Sprintf("%X", sha1.Sum([]byte(password)))
+		var suffixes map[string]bool
+		err := cfg.callHook(ctx, "BreachChecker", func(ctx context.Context) (err error) {
+			suffixes, err = cfg.BreachChecker.BreachedSuffixes(ctx, sum[:5])
+			return err
+		})
+		if err != nil && !errors.Is(err, errHookSkipped) {
+			return nil, err
+		}
+		if suffixes[sum[5:]] {
+			codes = append(codes, PasswordBreached)
+		}
+	}
+	return codes, nil
+}
+
+// passwordMessage is the default message for a password error code.
+func passwordMessage(field, code string, policy PasswordPolicy) string {
+	switch code {
+	case PasswordTooShort:
+		return fmt.Sprintf("%s must be at least %d characters", field, policy.MinLength)
+	case PasswordLowEntropy:
+		return fmt.Sprintf("%s is too easy to guess; make it longer or mix letters, digits and symbols", field)
+	case PasswordPersonalInfo:
+		return fmt.Sprintf("%s must not contain your personal details", field)
+	case PasswordBreached:
+		return fmt.Sprintf("%s has appeared in a data breach; choose a different one", field)
+	}
+	return fmt.Sprintf("%s is not allowed", field)
+}
+
+// PasswordEntropy estimates the entropy of password in bits from the size
+// of the character classes it draws on. Characters that repeat or continue
+// a sequence of their predecessor ("aaa", "abc", "321") add nothing.
+func PasswordEntropy(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	effective := 0
+	var prev rune
+	for i, c := range password {
+		switch {
+		case unicode.IsLower(c) && c < unicode.MaxASCII:
+			lower = true
+		case unicode.IsUpper(c) && c < unicode.MaxASCII:
+			upper = true
+		case unicode.IsDigit(c) && c < unicode.MaxASCII:
+			digit = true
+		case c < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+		if i == 0 || (c != prev && c != prev+1 && c != prev-1) {
+			effective++
+		}
+		prev = c
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.used {
+			pool += class.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	return float64(effective) * math.Log2(float64(pool))
+}
+
+// fieldByLowerName returns the field of struct value v whose lower-cased Go
+// name is name.
+func fieldByLowerName(v reflect.Value, name string) reflect.Value {
+	return v.FieldByNameFunc(func(n string) bool { return strings.ToLower(n) == name })
+}
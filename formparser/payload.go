@@ -0,0 +1,60 @@
+package formparser
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+)
+
+// PayloadResult is the outcome of ValidatePayload.
+type PayloadResult[T any] struct {
+	Value  *T           // the decoded payload, possibly partial when rejected
+	Result *ParseResult // the details an HTTP parse would have recorded
+	Status int          // the status an HTTP handler would have answered with
+	Body   []byte       // the error response an HTTP handler would have written
+}
+
+// ValidatePayload decodes and validates body as if it had been POSTed with
+// contentType, without an HTTP request or response, so batch jobs, message
+// consumers and CLIs apply exactly the rules cfg applies to requests:
+// content-type routing, limits, hooks, enrichers and validators alike.
+//
+// The error is the one the HTTP parse would have returned, e.g. FieldErrors
+// or validator.ValidationErrors for invalid payloads; Status and Body carry
+// the response it would have written. Parses are counted in cfg's Stats.
+func ValidatePayload[T any](cfg *Config, contentType string, body []byte) (*PayloadResult[T], error) {
+	r, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", contentType)
+
+	w := &payloadWriter{header: make(http.Header)}
+	out := &PayloadResult[T]{Value: new(T)}
+	out.Result, err = cfg.parse(w, r, out.Value)
+	out.Status, out.Body = w.status, w.body.Bytes()
+	if out.Status == 0 {
+		out.Status = http.StatusOK
+	}
+	return out, err
+}
+
+// payloadWriter is the in-memory ResponseWriter behind ValidatePayload.
+type payloadWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *payloadWriter) Header() http.Header { return w.header }
+
+func (w *payloadWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *payloadWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
@@ -0,0 +1,78 @@
+package formparser
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// PDFInfo holds metadata extracted from an uploaded PDF.
+type PDFInfo struct {
+	Pages     int
+	Encrypted bool
+}
+
+// PDFRule constrains PDF uploads for a single form field.
+type PDFRule struct {
+	MaxPages        int  // Optional: reject documents with more pages (0 = no limit)
+	RejectEncrypted bool // Optional: reject password-protected documents
+}
+
+var (
+	pdfPageObject = regexp.MustCompile(`/Type\s*/Page\b`)
+	pdfPageCount  = regexp.MustCompile(`/Type\s*/Pages\b[^>]*?/Count\s+(\d+)|/Count\s+(\d+)[^>]*?/Type\s*/Pages\b`)
+	pdfEncrypt    = regexp.MustCompile(`/Encrypt\s+(\d+\s+\d+\s+R|<<)`)
+)
+
+// inspectPDF extracts page count and encryption status from raw PDF bytes.
+// It is a lightweight scan rather than a full parser: pages are taken from
+// the largest /Pages /Count found, falling back to counting /Page objects
+// when page trees live in compressed object streams.
+func inspectPDF(content []byte) (*PDFInfo, error) {
+	if !bytes.HasPrefix(content, []byte("%PDF-")) {
+		return nil, fmt.Errorf("not a PDF document")
+	}
+
+	info := &PDFInfo{Encrypted: pdfEncrypt.Match(content)}
+	for _, m := range pdfPageCount.FindAllSubmatch(content, -1) {
+		raw := m[1]
+		if raw == nil {
+			raw = m[2]
+		}
+		if n, err := strconv.Atoi(string(raw)); err == nil && n > info.Pages {
+			info.Pages = n
+		}
+	}
+	if objects := len(pdfPageObject.FindAll(content, -1)); objects > info.Pages {
+		info.Pages = objects
+	}
+	return info, nil
+}
+
+// checkPDF applies the field's PDFRule to an uploaded file, recording the
+// extracted metadata on it. It returns a field error message or "".
+//
+// Every file on a field with a rule must start with the %PDF- magic,
+// whatever Content-Type the client declared, so a PDF cannot skip the rule
+// by being sent as application/octet-stream.
+func (cfg *Config) checkPDF(field string, file *UploadedFile) string {
+	rule, ok := cfg.PDFRules[field]
+	if !ok {
+		return ""
+	}
+
+	info, err := inspectPDF(file.Content)
+	if err != nil {
+		return fmt.Sprintf("%s is not a valid PDF", field)
+	}
+	file.PDF = info
+
+	if rule.RejectEncrypted && info.Encrypted {
+		return fmt.Sprintf("%s must not be encrypted", field)
+	}
+	if rule.MaxPages > 0 && info.Pages > rule.MaxPages {
+		return fmt.Sprintf("%s must have at most %d pages", field, rule.MaxPages)
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package formparser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"unicode/utf8"
+)
+
+const defaultMaxTextBodySize = 64 << 10 // 64KB
+
+// textBodyField returns the index of the top-level string field of t tagged
+// `body:"text"`, or -1.
+func textBodyField(t reflect.Type) int {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("body") == "text" && f.IsExported() && f.Type.Kind() == reflect.String {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseText binds a text/plain body into dst's `body:"text"` field. The body
+// is limited to MaxTextBodySize and converted to UTF-8 from the charset of
+// its byte order mark or Content-Type, as text uploads are.
+func (cfg *Config) parseText(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	field := -1
+	if v.Kind() == reflect.Struct {
+		field = textBodyField(v.Type())
+	}
+	if field < 0 {
+		http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
+		return errors.New("unsupported content type")
+	}
+
+	maxSize := cfg.maxTextBodySize()
+	var buf bytes.Buffer
+	n, err := cfg.copyLimited(&buf, r.Body, maxSize+1)
+	if err != nil {
+		return readFailed(w, r.Body, err, "Error reading body", http.StatusBadRequest)
+	}
+	if n > maxSize {
+		http.Error(w, "Text body too large", http.StatusRequestEntityTooLarge)
+		return fmt.Errorf("text body too large: %d bytes", n)
+	}
+
+	content, charset := stripBOM(buf.Bytes())
+	if charset == "" {
+		charset = declaredCharset(r.Header.Get("Content-Type"))
+	}
+	if charset != "" {
+		enc, err := textEncoding(charset)
+		if err != nil {
+			http.Error(w, "Unsupported charset", http.StatusUnsupportedMediaType)
+			return fmt.Errorf("unsupported charset %q: %w", charset, err)
+		}
+		if content, err = enc.NewDecoder().Bytes(content); err != nil {
+			http.Error(w, "Invalid text body", http.StatusBadRequest)
+			return err
+		}
+	}
+	if !utf8.Valid(content) {
+		http.Error(w, "Text body is not valid UTF-8", http.StatusBadRequest)
+		return errors.New("text body is not valid UTF-8")
+	}
+
+	v.Field(field).SetString(string(content))
+	return cfg.validateAndRespond(w, r, dst, res, nil)
+}
+
+// maxTextBodySize returns MaxTextBodySize or the default when unset.
+func (cfg *Config) maxTextBodySize() int64 {
+	if cfg.MaxTextBodySize > 0 {
+		return cfg.MaxTextBodySize
+	}
+	return defaultMaxTextBodySize
+}
@@ -0,0 +1,50 @@
+package formparser
+
+import (
+	"reflect"
+	"sync"
+)
+
+// dstPool recycles destination structs of a single registered type.
+type dstPool struct {
+	pool  sync.Pool
+	reset func(any)
+}
+
+// RegisterDstPool enables pooling of *T destinations on cfg. reset is called
+// on every released value before it is reused; when nil the value is zeroed.
+//
+// Aliasing rules: once ReleaseDst is called the handler must not keep dst,
+// nor any slice, map or pointer reachable from it, since the next request
+// may receive the same value. Copy out anything that outlives the request.
+func RegisterDstPool[T any](cfg *Config, reset func(*T)) {
+	p := &dstPool{}
+	p.pool.New = func() any { return new(T) }
+	if reset != nil {
+		p.reset = func(v any) { reset(v.(*T)) }
+	} else {
+		p.reset = func(v any) { *v.(*T) = *new(T) }
+	}
+	cfg.dstPools.Store(reflect.TypeFor[T](), p)
+}
+
+// AcquireDst returns a *T ready to be parsed into, taken from the pool
+// registered with RegisterDstPool or freshly allocated if none exists.
+func AcquireDst[T any](cfg *Config) *T {
+	if p, ok := cfg.dstPools.Load(reflect.TypeFor[T]()); ok {
+		return p.(*dstPool).pool.Get().(*T)
+	}
+	return new(T)
+}
+
+// ReleaseDst resets dst and returns it to its pool. Values of unregistered
+// types are left for the garbage collector.
+func ReleaseDst[T any](cfg *Config, dst *T) {
+	if dst == nil {
+		return
+	}
+	if p, ok := cfg.dstPools.Load(reflect.TypeFor[T]()); ok {
+		p.(*dstPool).reset(dst)
+		p.(*dstPool).pool.Put(dst)
+	}
+}
@@ -0,0 +1,62 @@
+package formparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// validatorKeywords are validate tag entries that steer the validator rather
+// than name a validation function.
+var validatorKeywords = map[string]bool{
+	"": true, "-": true, "omitempty": true, "omitnil": true, "omitzero": true,
+	"dive": true, "keys": true, "endkeys": true, "structonly": true, "nostructlevel": true,
+}
+
+// unknownValidateTags walks t (recursing into nested structs, slices and maps)
+// and returns "Field: tag" entries for validate tags the validator does not know.
+func (cfg *Config) unknownValidateTags(t reflect.Type) []string {
+	var unknown []string
+	seen := map[reflect.Type]bool{}
+
+	var walk func(t reflect.Type, path string)
+	walk = func(t reflect.Type, path string) {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || seen[t] {
+			return
+		}
+		seen[t] = true
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := path + f.Name
+			for _, rule := range strings.FieldsFunc(f.Tag.Get("validate"), func(r rune) bool { return r == ',' || r == '|' }) {
+				tag, _, _ := strings.Cut(rule, "=")
+				if !validatorKeywords[tag] && !cfg.knowsValidateTag(f.Type, rule) {
+					unknown = append(unknown, name+": "+tag)
+				}
+			}
+			walk(f.Type, name+".")
+		}
+	}
+	walk(t, "")
+	return unknown
+}
+
+// knowsValidateTag reports whether the validator can run rule. Unknown tags
+// make the validator panic; panics for other reasons (such as rules that do
+// not apply to a zero value) mean the tag itself is known.
+func (cfg *Config) knowsValidateTag(t reflect.Type, rule string) (known bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			known = !strings.Contains(fmt.Sprint(r), "Undefined validation function")
+		}
+	}()
+	_ = cfg.Validator.Var(reflect.Zero(t).Interface(), rule)
+	return true
+}
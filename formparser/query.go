@@ -0,0 +1,146 @@
+package formparser
+
+import (
+	"container/list"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// ParseQuery decodes the URL query string into dst and validates it. When
+// QueryCacheSize is set, validated results are cached per destination type
+// and raw query so identical requests skip decoding and validation. Results
+// that depend on more than the query are never cached: those of structs
+// with `ctx` tags, or parsed with an Enricher, a ValidatorResolver or
+// NumberLocaleAuto.
+//
+// Cached values are shallow copies: slices, maps and pointers inside dst are
+// shared between requests and must be treated as read-only by handlers.
+func (cfg *Config) ParseQuery(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	res := &ParseResult{}
+	sw := &statusWriter{ResponseWriter: w}
+	err := cfg.parseQuery(sw, r, dst, res)
+	cfg.stats.recordFailure(err, sw.status)
+	cfg.Result, cfg.Files = res, nil
+	return err
+}
+
+// parseQuery implements ParseQuery, recording details on res.
+func (cfg *Config) parseQuery(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	cfg.stats.parses[kindQuery].Add(1)
+	key := queryCacheKey{typ: reflect.TypeOf(dst), query: r.URL.RawQuery}
+	cache := cfg.getQueryCache()
+	if cfg.Merge || !cfg.queryCacheable(dst) {
+		cache = nil // merged results depend on dst's prior contents, others on the request
+	}
+	if cache != nil {
+		if cached, ok := cache.get(key); ok {
+			reflect.ValueOf(dst).Elem().Set(cached)
+			return nil
+		}
+	}
+
+	values, err := cfg.queryValues(r)
+	if err != nil {
+		http.Error(w, "Can't parse query", http.StatusBadRequest)
+		return err
+	}
+	fieldErrors := cfg.prepareValues(r, dst, values)
+	if err := cfg.decodeValues(dst, values, res, fieldErrors); err != nil {
+		http.Error(w, "Query nested too deeply", http.StatusBadRequest)
+		return err
+	}
+	if err := cfg.validateAndRespond(w, r, dst, res, fieldErrors); err != nil {
+		return err
+	}
+
+	if cache != nil {
+		v := reflect.New(key.typ.Elem()).Elem()
+		v.Set(reflect.ValueOf(dst).Elem())
+		cache.add(key, v)
+	}
+	return nil
+}
+
+// queryCacheable reports whether ParseQuery results for dst depend only on
+// the raw query, so a cached result is the same for every request.
+func (cfg *Config) queryCacheable(dst interface{}) bool {
+	if cfg.Enricher != nil || cfg.ValidatorResolver != nil || cfg.NumberLocale == NumberLocaleAuto {
+		return false
+	}
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("ctx"); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// getQueryCache returns the query cache, creating it on first use, or nil
+// when caching is disabled.
+func (cfg *Config) getQueryCache() *queryCache {
+	if cfg.QueryCacheSize <= 0 {
+		return nil
+	}
+	cfg.queryCacheOnce.Do(func() {
+		cfg.queryCache = newQueryCache(cfg.QueryCacheSize)
+	})
+	return cfg.queryCache
+}
+
+// queryCacheKey identifies a cached ParseQuery result.
+type queryCacheKey struct {
+	typ   reflect.Type
+	query string
+}
+
+type queryCacheEntry struct {
+	key   queryCacheKey
+	value reflect.Value
+}
+
+// queryCache is a mutex-guarded LRU of decoded query structs.
+type queryCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[queryCacheKey]*list.Element
+}
+
+func newQueryCache(size int) *queryCache {
+	return &queryCache{size: size, order: list.New(), items: make(map[queryCacheKey]*list.Element)}
+}
+
+func (c *queryCache) get(key queryCacheKey) (reflect.Value, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return reflect.Value{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*queryCacheEntry).value, true
+}
+
+func (c *queryCache) add(key queryCacheKey, value reflect.Value) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*queryCacheEntry).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&queryCacheEntry{key: key, value: value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*queryCacheEntry).key)
+	}
+}
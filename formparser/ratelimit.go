@@ -0,0 +1,53 @@
+package formparser
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultAsyncRetryAfter is the Retry-After sent when the ParseAsync queue is full.
+const defaultAsyncRetryAfter = time.Second
+
+// RateLimit is a limiter's state for one client, written as response
+// headers when a request is throttled: Retry-After plus the RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset fields of the IETF RateLimit
+// header draft. Zero values are left out, except Remaining when Limit is set.
+type RateLimit struct {
+	Limit      int           // requests allowed in the current window
+	Remaining  int           // requests left in the current window
+	Reset      time.Duration // time until the window resets
+	RetryAfter time.Duration // time the client should wait; defaults to Reset
+}
+
+// SetHeaders writes the headers for rl to h. Durations are rounded up to
+// whole seconds so clients never retry early.
+func (rl RateLimit) SetHeaders(h http.Header) {
+	retryAfter := rl.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = rl.Reset
+	}
+	if retryAfter > 0 {
+		h.Set("Retry-After", strconv.FormatInt(ceilSeconds(retryAfter), 10))
+	}
+	if rl.Limit > 0 {
+		h.Set("RateLimit-Limit", strconv.Itoa(rl.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(max(rl.Remaining, 0)))
+	}
+	if rl.Reset > 0 {
+		h.Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(rl.Reset), 10))
+	}
+}
+
+// ceilSeconds returns d in seconds, rounded up.
+func ceilSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
+
+// asyncRetryAfter returns AsyncRetryAfter or the default when unset.
+func (cfg *Config) asyncRetryAfter() time.Duration {
+	if cfg.AsyncRetryAfter > 0 {
+		return cfg.AsyncRetryAfter
+	}
+	return defaultAsyncRetryAfter
+}
@@ -0,0 +1,98 @@
+package formparser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+)
+
+// RawBody is implemented by destinations that take an application/octet-stream
+// body themselves. SetRawBody receives the body once it has been read within
+// MaxFileSize and hashed.
+type RawBody interface {
+	SetRawBody(body *UploadedFile) error
+}
+
+var (
+	bytesType  = reflect.TypeOf([]byte(nil))
+	readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+)
+
+// rawBodyField returns the index of the top-level field of t tagged
+// `body:"raw"` with type []byte or io.Reader, or -1.
+func rawBodyField(t reflect.Type) int {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("body") == "raw" && f.IsExported() && (f.Type == bytesType || f.Type == readerType) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseOctetStream handles application/octet-stream payload for destinations
+// that implement RawBody or declare a `body:"raw"` field. The body is limited
+// to MaxFileSize, hashed and recorded in ParseResult.Body; an io.Reader field
+// reads it from memory.
+func (cfg *Config) parseOctetStream(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult) error {
+	receiver, isReceiver := dst.(RawBody)
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	field := -1
+	if !isReceiver && v.Kind() == reflect.Struct {
+		field = rawBodyField(v.Type())
+	}
+	if !isReceiver && field < 0 {
+		http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
+		return errors.New("unsupported content type")
+	}
+
+	maxFileSize := cfg.maxFileSize()
+	var buf bytes.Buffer
+	n, err := cfg.copyLimited(&buf, r.Body, maxFileSize+1)
+	if err != nil {
+		return readFailed(w, r.Body, err, "Error reading body", http.StatusBadRequest)
+	}
+	if n > maxFileSize {
+		http.Error(w, "File too large", http.StatusRequestEntityTooLarge)
+		return fmt.Errorf("file too large: %d bytes", n)
+	}
+
+	body := &UploadedFile{
+		Filename:    rawBodyFilename(r),
+		ContentType: "application/octet-stream",
+		Content:     buf.Bytes(),
+		Size:        n,
+		Hash:        fmt.Sprintf("%x", sha256.Sum256(buf.Bytes())),
+	}
+	res.Body = body
+
+	if isReceiver {
+		err := receiver.SetRawBody(body)
+		var errs FieldErrors
+		if err != nil && !errors.As(err, &errs) {
+			http.Error(w, "Can't read body", http.StatusInternalServerError)
+			return err
+		}
+		return cfg.validateAndRespond(w, r, dst, res, errs)
+	}
+	if v.Field(field).Type() == bytesType {
+		v.Field(field).SetBytes(body.Content)
+	} else {
+		v.Field(field).Set(reflect.ValueOf(bytes.NewReader(body.Content)))
+	}
+	return cfg.validateAndRespond(w, r, dst, res, nil)
+}
+
+// rawBodyFilename returns the filename of the Content-Disposition header, if any.
+func rawBodyFilename(r *http.Request) string {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Disposition"))
+	if err != nil {
+		return ""
+	}
+	return params["filename"]
+}
@@ -0,0 +1,40 @@
+// Package redisstore provides a formparser.KeyValueStore backed by Redis,
+// kept apart so the core package does not depend on a Redis client.
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store is a formparser.KeyValueStore backed by Redis, for state shared
+// between several instances of a service. Expiry is left to Redis.
+type Store struct {
+	Client redis.UniversalClient
+	Prefix string // Optional: prepended to every key, e.g. "formparser:"
+}
+
+// Get implements formparser.KeyValueStore.
+func (s Store) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.Client.Get(ctx, s.Prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set implements formparser.KeyValueStore.
+func (s Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.Client.Set(ctx, s.Prefix+key, value, ttl).Err()
+}
+
+// Delete implements formparser.KeyValueStore.
+func (s Store) Delete(ctx context.Context, key string) error {
+	return s.Client.Del(ctx, s.Prefix+key).Err()
+}
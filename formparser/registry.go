@@ -0,0 +1,57 @@
+package formparser
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// ParserFunc decodes a request body of a registered media type into dst.
+// Returning FieldErrors reports per-field problems alongside the
+// validator's; any other error rejects the body with a 400.
+type ParserFunc func(body io.Reader, dst interface{}) error
+
+// RegisterParser makes bodies of mediaType, e.g. "application/x-amf", parse
+// with fn and then flow through the same hooks, validation and error
+// responses as the built-in formats. mediaType is matched exactly, ignoring
+// case and parameters, and takes precedence over a built-in parser for the
+// same type. A nil fn removes the registration.
+func (cfg *Config) RegisterParser(mediaType string, fn ParserFunc) {
+	mediaType, _ = parseMediaType(mediaType)
+	if fn == nil {
+		cfg.parsers.Delete(mediaType)
+		return
+	}
+	cfg.parsers.Store(mediaType, fn)
+}
+
+// registeredParser returns the ParserFunc registered for mediaType.
+func (cfg *Config) registeredParser(mediaType string) (ParserFunc, bool) {
+	fn, ok := cfg.parsers.Load(mediaType)
+	if !ok {
+		return nil, false
+	}
+	return fn.(ParserFunc), true
+}
+
+// registeredMediaTypes lists the media types given to RegisterParser, sorted.
+func (cfg *Config) registeredMediaTypes() []string {
+	var types []string
+	cfg.parsers.Range(func(mediaType, _ any) bool {
+		types = append(types, mediaType.(string))
+		return true
+	})
+	sort.Strings(types)
+	return types
+}
+
+// parseRegistered handles a body of a registered media type.
+func (cfg *Config) parseRegistered(w http.ResponseWriter, r *http.Request, dst interface{}, res *ParseResult, fn ParserFunc) error {
+	var fieldErrors FieldErrors
+	if err := fn(r.Body, dst); err != nil && !errors.As(err, &fieldErrors) {
+		cfg.httpError(w, "Invalid request body", http.StatusBadRequest, err)
+		return err
+	}
+	return cfg.validateAndRespond(w, r, dst, res, fieldErrors)
+}
@@ -0,0 +1,120 @@
+package formparser
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fileRequirement is a file field tagged `file_required_if`.
+type fileRequirement struct {
+	name  string // field error key
+	key   string // form key the file is uploaded under
+	conds []fileCondition
+}
+
+// fileCondition holds when the field at index formats as value.
+type fileCondition struct {
+	index int
+	value string
+}
+
+// fileRequirementsCache maps reflect.Type to []fileRequirement.
+var fileRequirementsCache sync.Map
+
+// fileRequirements returns the top-level fields of dst tagged
+// `file_required_if:"<Field> <value> ..."`. Like validator's required_if,
+// the tag lists field/value pairs that must all match; fields are matched by
+// Go name (case-insensitively), form key or json name. Tags naming unknown
+// fields are ignored.
+func fileRequirements(dst interface{}) []fileRequirement {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := fileRequirementsCache.Load(t); ok {
+		return cached.([]fileRequirement)
+	}
+
+	var reqs []fileRequirement
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		params := strings.Fields(f.Tag.Get("file_required_if"))
+		if len(params) == 0 || len(params)%2 != 0 {
+			continue
+		}
+		req := fileRequirement{name: strings.ToLower(f.Name), key: formKey(f)}
+		for p := 0; p < len(params); p += 2 {
+			index := fieldIndexByName(t, params[p])
+			if index < 0 {
+				req.conds = nil
+				break
+			}
+			req.conds = append(req.conds, fileCondition{index: index, value: params[p+1]})
+		}
+		if len(req.conds) > 0 {
+			reqs = append(reqs, req)
+		}
+	}
+
+	fileRequirementsCache.Store(t, reqs)
+	return reqs
+}
+
+// fieldIndexByName returns the index of the top-level field of t with the
+// given Go name (case-insensitively), form key or json name, or -1.
+func fieldIndexByName(t reflect.Type, name string) int {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if strings.EqualFold(f.Name, name) || formKey(f) == name || tagName(f.Tag.Get("json")) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkFileRequirements reports `file_required_if` fields whose conditions
+// hold but for which no file was uploaded. Empty uploads count as missing.
+func (cfg *Config) checkFileRequirements(r *http.Request, dst interface{}, res *ParseResult) FieldErrors {
+	reqs := fileRequirements(dst)
+	if len(reqs) == 0 {
+		return nil
+	}
+	fieldErrors := make(FieldErrors)
+	lang := requestLang(r)
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, req := range reqs {
+		if res.Files[req.key] != nil || !conditionsHold(v, req.conds) {
+			continue
+		}
+		if msg, ok := cfg.lookupMessage(req.name, "required", lang); ok {
+			fieldErrors[req.name] = msg
+		} else {
+			fieldErrors[req.name] = fmt.Sprintf("%s is required", req.name)
+		}
+	}
+	return fieldErrors
+}
+
+// conditionsHold reports whether every condition matches v. Pointer fields
+// are dereferenced; nil pointers match nothing.
+func conditionsHold(v reflect.Value, conds []fileCondition) bool {
+	for _, c := range conds {
+		field := v.Field(c.index)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				return false
+			}
+			field = field.Elem()
+		}
+		if fmt.Sprint(field.Interface()) != c.value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package formparser
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CreatedFile is one entry of the RespondCreated envelope.
+type CreatedFile struct {
+	Field string `json:"field"`
+	FileRef
+	URL string `json:"url,omitempty"`
+}
+
+// RespondCreated writes a 201 response listing the files stored by the last
+// multipart parse, so an upload handler reduces to parse, check, respond:
+//
+//	if err := cfg.ParseFormBasedOnContentType(w, r, &form); err != nil {
+//		return
+//	}
+//	cfg.RespondCreated(w)
+func (cfg *Config) RespondCreated(w http.ResponseWriter) error {
+	fields := make([]string, 0, len(cfg.Files))
+	for field := range cfg.Files {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	files := make([]CreatedFile, 0, len(fields))
+	for _, field := range fields {
+		uploads := []*UploadedFile{cfg.Files[field]}
+		if cfg.Result != nil && len(cfg.Result.AllFiles[field]) > 0 {
+			uploads = cfg.Result.AllFiles[field]
+		}
+		for _, file := range uploads {
+			created := CreatedFile{Field: field, FileRef: file.Ref()}
+			if cfg.FileURL != nil && file.StorageKey != "" {
+				created.URL = cfg.FileURL(file.StorageKey)
+			}
+			files = append(files, created)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(map[string]any{
+		"message": "Upload successful",
+		"files":   files,
+	})
+}
+
+// writeErrorJSON writes body as a 400 JSON response. Bodies of at least
+// ErrorGzipThreshold bytes are gzipped for clients that accept it, which
+// keeps large batch validation reports fast.
+func (cfg *Config) writeErrorJSON(w http.ResponseWriter, r *http.Request, body any) {
+	cfg.writeErrorDocument(w, r, "application/json", body)
+}
+
+// writeErrorDocument is writeErrorJSON with the given JSON-based media type.
+func (cfg *Config) writeErrorDocument(w http.ResponseWriter, r *http.Request, contentType string, body any) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, "Validation failed", http.StatusBadRequest)
+		return
+	}
+	data = append(data, '\n')
+
+	w.Header().Set("Content-Type", contentType)
+	if cfg.ErrorGzipThreshold > 0 {
+		// Caches must key on Accept-Encoding whether or not this response
+		// is compressed, or a plain copy could be served to gzip clients
+		// and a compressed one to clients that refused it.
+		w.Header().Add("Vary", "Accept-Encoding")
+	}
+	if cfg.ErrorGzipThreshold <= 0 || len(data) < cfg.ErrorGzipThreshold || !acceptsGzip(r) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write(data)
+		return
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusBadRequest)
+	gz := gzip.NewWriter(w)
+	_, _ = gz.Write(data)
+	_ = gz.Close()
+}
+
+// acceptsGzip reports whether the Accept-Encoding header of r allows gzip
+// with a non-zero quality. An explicit gzip entry wins over "*", so
+// "gzip;q=0, *" refuses gzip.
+func acceptsGzip(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+	gzipQ, starQ := -1.0, -1.0
+	for _, header := range r.Header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(header, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "gzip":
+				gzipQ = max(gzipQ, codingQuality(params))
+			case "*":
+				starQ = max(starQ, codingQuality(params))
+			}
+		}
+	}
+	if gzipQ >= 0 {
+		return gzipQ > 0
+	}
+	return starQ > 0
+}
+
+// codingQuality returns the q parameter of an Accept-Encoding entry: 1 when
+// absent, 0 when malformed.
+func codingQuality(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || q < 0 {
+			return 0
+		}
+		return q
+	}
+	return 1
+}
@@ -0,0 +1,83 @@
+package formparser
+
+import (
+	"net/url"
+	"reflect"
+)
+
+// ParseResult describes the outcome of a single parse. The synchronous entry
+// points also store it in Config.Result (and its Files in Config.Files);
+// ParseAsync hands it back through its ParseJob instead.
+type ParseResult struct {
+	// MediaType is the request's lower-cased media type, e.g.
+	// "application/json", and MediaParams its Content-Type parameters, e.g.
+	// {"charset": "UTF-8"}.
+	MediaType   string
+	MediaParams map[string]string
+
+	// Files holds the uploads of a multipart request, keyed by field name.
+	// A field with several files holds the last; see AllFiles.
+	Files map[string]*UploadedFile
+
+	// AllFiles holds every upload of a multipart request in request order,
+	// keyed by field name, for `<input type="file" multiple>` fields.
+	AllFiles map[string][]*UploadedFile
+
+	// FileActions holds whether a form asked to keep, delete or replace the
+	// file of each field it mentioned, keyed by field name; see FileAction.
+	FileActions map[string]FileAction
+
+	// Values holds the fields of a url-encoded or multipart request as
+	// submitted, for LegacyForm.
+	Values url.Values
+
+	// Parts holds every part of a multipart/mixed or multipart/related
+	// request in order, with its headers.
+	Parts []*Part
+
+	// Email holds a message/rfc822 request's headers, bodies and
+	// attachments.
+	Email *Email
+
+	// Body holds an application/octet-stream request body.
+	Body *UploadedFile
+
+	// ValidationSkipped is true when DecodeOnly mode bypassed the validator.
+	ValidationSkipped bool
+
+	// Present holds the lower-cased names of the top-level fields the request
+	// submitted. It is only tracked in Merge mode and by ParseMergePatch.
+	Present map[string]bool
+
+	// Changes lists the submitted fields whose values differ from what dst
+	// held before the parse, in struct field order. It is only tracked in
+	// Merge mode and by ParseMergePatch, where an empty list means nothing
+	// changed.
+	Changes []FieldChange
+
+	// ErrorCodes lists machine-readable codes for failed checks by field,
+	// e.g. {"password": ["password_too_short", "password_breached"]}.
+	ErrorCodes map[string][]string
+
+	// SubmissionID is the ID the Outbox saved the submission under, for
+	// marking it processed once the handler is done.
+	SubmissionID string
+
+	// Warnings holds notes about values the parse changed rather than
+	// rejected, such as text cut to its `maxbytes` limit, keyed by field.
+	Warnings map[string]string
+
+	// Extras holds data attached by hooks during the parse, such as the
+	// AddressNormalizer's geocoding results keyed by address group.
+	Extras map[string]any
+
+	mergeBase    map[int]reflect.Value  // Merge-mode snapshot of dst
+	errorDetails map[string]errorDetail // validator rule details for ErrorFormatV2
+	jsonPayload  []byte                 // the MultipartJSONField part of a multipart request
+	fileRules    map[string]fileRule    // `file` tag constraints by form key
+	stateDecoded bool                   // decodeStateValues verified the `state` fields
+	deferFiles   bool                   // queue persistFile work until the trailer checksum is verified
+	pendingFiles []pendingFile          // files queued by persistFile
+
+	events *eventEmitter
+}
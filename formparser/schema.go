@@ -0,0 +1,255 @@
+package formparser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jsonSchemaDialect is the JSON Schema version Schema documents declare.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is the subset of JSON Schema that Schema derives from a
+// struct's Go types and validate tags. Dependencies carries the struct's
+// cross-field rules, which JSON Schema cannot express, as its
+// DependencyGraph edges.
+type JSONSchema struct {
+	Schema           string                 `json:"$schema,omitempty"`
+	Title            string                 `json:"title,omitempty"`
+	Type             string                 `json:"type,omitempty"`
+	Format           string                 `json:"format,omitempty"`
+	Properties       map[string]*JSONSchema `json:"properties,omitempty"`
+	Required         []string               `json:"required,omitempty"`
+	Items            *JSONSchema            `json:"items,omitempty"`
+	Enum             []any                  `json:"enum,omitempty"`
+	Pattern          string                 `json:"pattern,omitempty"`
+	MinLength        *int                   `json:"minLength,omitempty"`
+	MaxLength        *int                   `json:"maxLength,omitempty"`
+	MinItems         *int                   `json:"minItems,omitempty"`
+	MaxItems         *int                   `json:"maxItems,omitempty"`
+	Minimum          *float64               `json:"minimum,omitempty"`
+	Maximum          *float64               `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64               `json:"exclusiveMaximum,omitempty"`
+	Dependencies     []FieldDependency      `json:"x-dependencies,omitempty"`
+}
+
+// schemaFormats maps validator tags to JSON Schema formats.
+var schemaFormats = map[string]string{
+	"email": "email", "url": "uri", "uri": "uri", "http_url": "uri",
+	"uuid": "uuid", "uuid4": "uuid", "uuid_rfc4122": "uuid", "uuid4_rfc4122": "uuid",
+	"ip": "ip", "ipv4": "ipv4", "ipv6": "ipv6", "hostname": "hostname", "hostname_rfc1123": "hostname",
+}
+
+// schemaPatterns maps validator tags to equivalent regular expressions.
+var schemaPatterns = map[string]string{
+	"alpha":     `^[a-zA-Z]+$`,
+	"alphanum":  `^[a-zA-Z0-9]+$`,
+	"numeric":   `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	"number":    `^[0-9]+$`,
+	"e164":      `^\+[1-9]?[0-9]{7,14}$`,
+	"lowercase": `^[^A-Z]*$`,
+	"uppercase": `^[^a-z]*$`,
+}
+
+// schemaCache maps reflect.Type to the JSONSchema built for it.
+var schemaCache sync.Map
+
+// Schema describes dst, a struct or pointer to struct, as a JSON Schema so
+// frontends can check input with the rules the parser enforces. Properties
+// are named like DependencyGraph fields. Rules with a JSON Schema
+// equivalent (required, min, max, len, gt, gte, lt, lte, oneof, email, url,
+// uuid, alpha, ...) are translated, rules after "dive" apply to items, and
+// the rest, including "|" alternatives and custom validations, are left to
+// the server.
+func Schema(dst interface{}) JSONSchema {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return JSONSchema{Schema: jsonSchemaDialect}
+	}
+	if cached, ok := schemaCache.Load(t); ok {
+		return cached.(JSONSchema)
+	}
+	s := *typeSchema(t, map[reflect.Type]bool{})
+	s.Schema, s.Title = jsonSchemaDialect, t.Name()
+	if t.Kind() == reflect.Struct {
+		s.Dependencies = Dependencies(dst).Dependencies
+	}
+	schemaCache.Store(t, s)
+	return s
+}
+
+// SchemaHandler returns a handler that serves Schema(dst) to GET requests.
+// Responses carry an ETag derived from the schema and must be revalidated,
+// so clients can cache the rules and fetch them again only after they
+// change:
+//
+//	mux.Handle("/schemas/signup", formparser.SchemaHandler(&SignupForm{}))
+func SchemaHandler(dst interface{}) http.Handler {
+	body, _ := json.MarshalIndent(Schema(dst), "", "  ")
+	body = append(body, '\n')
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/schema+json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+	})
+}
+
+// typeSchema describes values of t. seen holds the structs being described
+// so recursive types end in a plain object.
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &JSONSchema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string"}
+		}
+		return &JSONSchema{Type: "array", Items: typeSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return &JSONSchema{Type: "object"}
+	case reflect.Struct:
+		if seen[t] {
+			return &JSONSchema{Type: "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		return structSchema(t, seen)
+	}
+	return &JSONSchema{}
+}
+
+// structSchema describes the exported fields of struct t.
+func structSchema(t reflect.Type, seen map[reflect.Type]bool) *JSONSchema {
+	s := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := echoName(f)
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		prop := typeSchema(f.Type, seen)
+		target := prop
+		for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+			tag, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			switch {
+			case tag == "dive":
+				target = target.Items
+			case strings.Contains(rule, "|"):
+			case tag == "required" && target == prop:
+				s.Required = append(s.Required, name)
+			default:
+				applySchemaRule(target, tag, param)
+			}
+			if target == nil {
+				break // dove into something other than an array
+			}
+		}
+		s.Properties[name] = prop
+	}
+	return s
+}
+
+// applySchemaRule translates one validator rule onto s, ignoring rules
+// without a JSON Schema equivalent.
+func applySchemaRule(s *JSONSchema, tag, param string) {
+	if format, ok := schemaFormats[tag]; ok && s.Type == "string" {
+		s.Format = format
+		return
+	}
+	if pattern, ok := schemaPatterns[tag]; ok && s.Type == "string" {
+		s.Pattern = pattern
+		return
+	}
+	if tag == "oneof" {
+		for _, v := range strings.Fields(param) {
+			s.Enum = append(s.Enum, schemaValue(s.Type, v))
+		}
+		return
+	}
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch s.Type {
+	case "integer", "number":
+		switch tag {
+		case "min", "gte":
+			s.Minimum = &n
+		case "max", "lte":
+			s.Maximum = &n
+		case "len", "eq":
+			s.Minimum, s.Maximum = &n, &n
+		case "gt":
+			s.ExclusiveMinimum = &n
+		case "lt":
+			s.ExclusiveMaximum = &n
+		}
+	case "string":
+		setSchemaBounds(tag, int(n), &s.MinLength, &s.MaxLength)
+	case "array":
+		setSchemaBounds(tag, int(n), &s.MinItems, &s.MaxItems)
+	}
+}
+
+// setSchemaBounds applies a length rule to a minimum and maximum count.
+func setSchemaBounds(tag string, n int, min, max **int) {
+	switch tag {
+	case "min", "gte":
+		*min = &n
+	case "max", "lte":
+		*max = &n
+	case "len":
+		*min, *max = &n, &n
+	case "gt":
+		n++
+		*min = &n
+	case "lt":
+		n--
+		*max = &n
+	}
+}
+
+// schemaValue returns an enum value as JSON of the given schema type.
+func schemaValue(typ, v string) any {
+	if typ == "integer" || typ == "number" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
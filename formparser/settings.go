@@ -0,0 +1,276 @@
+package formparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/form/v4"
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// Settings are the Config limits, allowed types and error format options
+// that can be tuned without code changes. ConfigFromFile reads them from a
+// JSON or YAML file and ConfigFromEnv from FORMPARSER_* variables named
+// after the json keys, e.g. FORMPARSER_MAX_FILE_SIZE=10MB.
+type Settings struct {
+	MaxFileSize             Size              `json:"max_file_size" yaml:"max_file_size"`
+	MaxTextBodySize         Size              `json:"max_text_body_size" yaml:"max_text_body_size"`
+	MaxDecompressedSize     Size              `json:"max_decompressed_size" yaml:"max_decompressed_size"`
+	MinReadRate             Size              `json:"min_read_rate" yaml:"min_read_rate"`
+	PartIdleTimeout         string            `json:"part_idle_timeout" yaml:"part_idle_timeout"` // time.ParseDuration syntax, e.g. "30s"
+	MaxUploadBytesPerSecond Size              `json:"max_upload_bytes_per_second" yaml:"max_upload_bytes_per_second"`
+	CopyBufferSize          Size              `json:"copy_buffer_size" yaml:"copy_buffer_size"`
+	AllowedMIMETypes        []string          `json:"allowed_mime_types" yaml:"allowed_mime_types"`     // comma-separated in the environment
+	TagMode                 string            `json:"tag_mode" yaml:"tag_mode"`                         // as in EffectiveConfig, e.g. "prefer_json"
+	URLEncoding             string            `json:"url_encoding" yaml:"url_encoding"`                 // "default", "strict" or "lenient"
+	MissingContentType      string            `json:"missing_content_type" yaml:"missing_content_type"` // "reject", "sniff", "urlencoded" or "json"
+	TextLimitPolicy         string            `json:"text_limit_policy" yaml:"text_limit_policy"`       // "reject" or "truncate"
+	MultipartJSONField      string            `json:"multipart_json_field" yaml:"multipart_json_field"` // "-" disables
+	NumberLocale            string            `json:"number_locale" yaml:"number_locale"`
+	QueryCacheSize          int               `json:"query_cache_size" yaml:"query_cache_size"`
+	MaxDecodeDepth          int               `json:"max_decode_depth" yaml:"max_decode_depth"`
+	MaxJSONTokens           int               `json:"max_json_tokens" yaml:"max_json_tokens"`
+	MaxImagePixels          int               `json:"max_image_pixels" yaml:"max_image_pixels"`
+	ErrorGzipThreshold      Size              `json:"error_gzip_threshold" yaml:"error_gzip_threshold"`
+	ErrorFormat             string            `json:"error_format" yaml:"error_format"` // "v1" or "v2"
+	Mode                    string            `json:"mode" yaml:"mode"`                 // "release" or "debug"
+	EmptyFileRequired       bool              `json:"empty_file_required" yaml:"empty_file_required"`
+	AllowFileTagMaxSize     bool              `json:"allow_file_tag_max_size" yaml:"allow_file_tag_max_size"`
+	VerifyTrailerChecksum   bool              `json:"verify_trailer_checksum" yaml:"verify_trailer_checksum"`
+	FieldErrorMessages      map[string]string `json:"field_error_messages" yaml:"field_error_messages"` // file only
+	MessageTemplates        map[string]string `json:"message_templates" yaml:"message_templates"`       // file only
+}
+
+// envPrefix starts the name of every variable read by ConfigFromEnv.
+const envPrefix = "FORMPARSER_"
+
+// ConfigFromEnv builds a Config from FORMPARSER_* environment variables,
+// leaving unset ones at their defaults.
+func ConfigFromEnv() (*Config, error) {
+	var s Settings
+	v := reflect.ValueOf(&s).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		name := envPrefix + strings.ToUpper(strings.Split(f.Tag.Get("json"), ",")[0])
+		value, ok := os.LookupEnv(name)
+		if !ok || f.Type.Kind() == reflect.Map {
+			continue
+		}
+		if err := setEnvSetting(v.Field(i), strings.TrimSpace(value)); err != nil {
+			return nil, fmt.Errorf("formparser: %s: %w", name, err)
+		}
+	}
+	return s.Config()
+}
+
+// ConfigFromFile builds a Config from a JSON or YAML settings file, chosen
+// by its .json, .yaml or .yml extension.
+func ConfigFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Settings
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &s)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &s)
+	default:
+		return nil, fmt.Errorf("formparser: unsupported settings file %q", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("formparser: %s: %w", path, err)
+	}
+	return s.Config()
+}
+
+// Config returns a Config with a default Decoder and Validator and the
+// settings applied.
+func (s Settings) Config() (*Config, error) {
+	cfg := &Config{
+		Decoder:                 form.NewDecoder(),
+		Validator:               validator.New(),
+		MaxFileSize:             int64(s.MaxFileSize),
+		MaxTextBodySize:         int64(s.MaxTextBodySize),
+		MaxDecompressedSize:     int64(s.MaxDecompressedSize),
+		MinReadRate:             int64(s.MinReadRate),
+		MaxUploadBytesPerSecond: int64(s.MaxUploadBytesPerSecond),
+		CopyBufferSize:          int(s.CopyBufferSize),
+		AllowedMIMETypes:        s.AllowedMIMETypes,
+		MultipartJSONField:      s.MultipartJSONField,
+		NumberLocale:            s.NumberLocale,
+		QueryCacheSize:          s.QueryCacheSize,
+		MaxDecodeDepth:          s.MaxDecodeDepth,
+		MaxJSONTokens:           s.MaxJSONTokens,
+		MaxImagePixels:          s.MaxImagePixels,
+		ErrorGzipThreshold:      int(s.ErrorGzipThreshold),
+		EmptyFileRequired:       s.EmptyFileRequired,
+		AllowFileTagMaxSize:     s.AllowFileTagMaxSize,
+		VerifyTrailerChecksum:   s.VerifyTrailerChecksum,
+		FieldErrorMessages:      s.FieldErrorMessages,
+		MessageTemplates:        s.MessageTemplates,
+	}
+	if s.PartIdleTimeout != "" {
+		d, err := time.ParseDuration(s.PartIdleTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("formparser: part_idle_timeout: %w", err)
+		}
+		cfg.PartIdleTimeout = d
+	}
+	var ok bool
+	if cfg.TagMode, ok = parseTagMode(s.TagMode); !ok {
+		return nil, fmt.Errorf("formparser: unknown tag_mode %q", s.TagMode)
+	}
+	if cfg.URLEncoding, ok = parseURLEncodingMode(s.URLEncoding); !ok {
+		return nil, fmt.Errorf("formparser: unknown url_encoding %q", s.URLEncoding)
+	}
+	if cfg.MissingContentType, ok = parseContentTypeFallback(s.MissingContentType); !ok {
+		return nil, fmt.Errorf("formparser: unknown missing_content_type %q", s.MissingContentType)
+	}
+	if cfg.TextLimitPolicy, ok = parseTextLimitPolicy(s.TextLimitPolicy); !ok {
+		return nil, fmt.Errorf("formparser: unknown text_limit_policy %q", s.TextLimitPolicy)
+	}
+	if cfg.Mode, ok = parseMode(s.Mode); !ok {
+		return nil, fmt.Errorf("formparser: unknown mode %q", s.Mode)
+	}
+	if cfg.ErrorFormat, ok = parseErrorFormat(s.ErrorFormat); !ok {
+		return nil, fmt.Errorf("formparser: unknown error_format %q", s.ErrorFormat)
+	}
+	return cfg, nil
+}
+
+// setEnvSetting parses an environment value into a Settings field.
+func setEnvSetting(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case Size:
+		n, err := ParseSize(value)
+		field.SetInt(n)
+		return err
+	case []string:
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	case string:
+		field.SetString(value)
+	case int:
+		n, err := strconv.Atoi(value)
+		field.SetInt(int64(n))
+		return err
+	case bool:
+		b, err := strconv.ParseBool(value)
+		field.SetBool(b)
+		return err
+	}
+	return nil
+}
+
+func parseTagMode(name string) (TagMode, bool) {
+	for _, m := range []TagMode{TagModeDefault, TagModePreferJSON, TagModePreferForm, TagModeStrict} {
+		if name == "" || strings.EqualFold(name, m.String()) {
+			return m, true
+		}
+	}
+	return TagModeDefault, false
+}
+
+func parseURLEncodingMode(name string) (URLEncodingMode, bool) {
+	for _, m := range []URLEncodingMode{URLEncodingDefault, URLEncodingStrict, URLEncodingLenient} {
+		if name == "" || strings.EqualFold(name, m.String()) {
+			return m, true
+		}
+	}
+	return URLEncodingDefault, false
+}
+
+func parseContentTypeFallback(name string) (ContentTypeFallback, bool) {
+	for _, f := range []ContentTypeFallback{FallbackReject, FallbackSniff, FallbackURLEncoded, FallbackJSON} {
+		if name == "" || strings.EqualFold(name, f.String()) {
+			return f, true
+		}
+	}
+	return FallbackReject, false
+}
+
+func parseTextLimitPolicy(name string) (TextLimitPolicy, bool) {
+	for _, p := range []TextLimitPolicy{TextLimitReject, TextLimitTruncate} {
+		if name == "" || strings.EqualFold(name, p.String()) {
+			return p, true
+		}
+	}
+	return TextLimitReject, false
+}
+
+// Size is a byte count that settings files may give as a number or as a
+// human-readable string accepted by ParseSize.
+type Size int64
+
+// UnmarshalJSON accepts a number or a ParseSize string.
+func (s *Size) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		text = string(data)
+	}
+	n, err := ParseSize(text)
+	*s = Size(n)
+	return err
+}
+
+// UnmarshalYAML accepts a number or a ParseSize string.
+func (s *Size) UnmarshalYAML(node *yaml.Node) error {
+	n, err := ParseSize(node.Value)
+	*s = Size(n)
+	return err
+}
+
+// sizeUnits are the suffixes ParseSize understands, longest first.
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+	{"B", 1},
+}
+
+// ParseSize parses a byte count such as "512", "64KB", "1.5MB" or "2GiB".
+// Units are case-insensitive and binary, so "5MB" is 5<<20 bytes, matching
+// the defaults documented on Config.
+func ParseSize(s string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(text, u.suffix) {
+			text, multiplier = strings.TrimSpace(strings.TrimSuffix(text, u.suffix)), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil || n < 0 || math.IsNaN(n) || math.IsInf(n, 0) || n*float64(multiplier) > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// MustParseSize is like ParseSize but panics on invalid input, for sizes
+// written in code such as Config{MaxFileSize: MustParseSize("10MB")}.
+func MustParseSize(s string) int64 {
+	n, err := ParseSize(s)
+	if err != nil {
+		panic("formparser: " + err.Error())
+	}
+	return n
+}
@@ -0,0 +1,68 @@
+package formparser
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// splitFieldsCache maps reflect.Type to the map[string]string of form key to
+// separator for fields carrying a `split` tag.
+var splitFieldsCache sync.Map
+
+// splitFields returns the form keys of dst's top-level fields tagged with
+// `split:"<sep>"`, mapped to their separator.
+func splitFields(dst interface{}) map[string]string {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := splitFieldsCache.Load(t); ok {
+		return cached.(map[string]string)
+	}
+
+	fields := make(map[string]string)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		sep, ok := f.Tag.Lookup("split")
+		if !ok || sep == "" || !f.IsExported() {
+			continue
+		}
+		fields[formKey(f)] = sep
+	}
+
+	splitFieldsCache.Store(t, fields)
+	return fields
+}
+
+// splitValues expands separator-joined values ("a, b,,c") for fields tagged
+// with `split`, trimming whitespace and dropping empty elements.
+func splitValues(dst interface{}, values url.Values) {
+	for key, sep := range splitFields(dst) {
+		raw, ok := values[key]
+		if !ok {
+			continue
+		}
+		var out []string
+		for _, v := range raw {
+			for _, elem := range strings.Split(v, sep) {
+				if elem = strings.TrimSpace(elem); elem != "" {
+					out = append(out, elem)
+				}
+			}
+		}
+		values[key] = out
+	}
+}
+
+// formKey returns the key a struct field binds to in form-encoded data.
+func formKey(f reflect.StructField) string {
+	if name := tagName(f.Tag.Get("form")); name != "" {
+		return name
+	}
+	return f.Name
+}
@@ -0,0 +1,136 @@
+package formparser
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Parse kinds counted in Stats.Parses.
+const (
+	kindJSON = iota
+	kindXML
+	kindYAML
+	kindMsgpack
+	kindTOML
+	kindCBOR
+	kindProtobuf
+	kindNDJSON
+	kindCSV
+	kindOctetStream
+	kindText
+	kindURLEncoded
+	kindMultipart
+	kindEmail
+	kindQuery
+	kindCustom
+	kindUnsupported
+	numKinds
+)
+
+var parseKindNames = [numKinds]string{"json", "xml", "yaml", "msgpack", "toml", "cbor", "protobuf", "ndjson", "csv", "octet-stream", "text", "urlencoded", "multipart", "email", "query", "custom", "unsupported"}
+
+// Failure classes counted in Stats.Failures.
+const (
+	failValidation = iota
+	failTooLarge
+	failUnsupportedType
+	failClientError
+	failServerError
+	failQueueFull
+	numFailureClasses
+)
+
+var failureClassNames = [numFailureClasses]string{
+	"validation", "too_large", "unsupported_type", "client_error", "server_error", "queue_full",
+}
+
+// Stats is a snapshot of a Config's cumulative counters.
+type Stats struct {
+	Parses      map[string]int64 `json:"parses"`       // by body kind: json, xml, yaml, msgpack, toml, cbor, protobuf, ndjson, csv, octet-stream, text, urlencoded, multipart, email, query, custom, unsupported
+	Failures    map[string]int64 `json:"failures"`     // by class: validation, too_large, unsupported_type, client_error, server_error, queue_full
+	BytesRead   int64            `json:"bytes_read"`   // request body bytes consumed
+	FilesStored int64            `json:"files_stored"` // files and variants saved by the FileStore
+}
+
+// statsCounters are the atomic counters behind Stats.
+type statsCounters struct {
+	parses      [numKinds]atomic.Int64
+	failures    [numFailureClasses]atomic.Int64
+	bytesRead   atomic.Int64
+	filesStored atomic.Int64
+}
+
+// Stats returns the counters accumulated since the Config was created. It
+// is safe to call while parses are running, e.g. from a debug handler.
+func (cfg *Config) Stats() Stats {
+	s := Stats{
+		Parses:      make(map[string]int64, numKinds),
+		Failures:    make(map[string]int64, numFailureClasses),
+		BytesRead:   cfg.stats.bytesRead.Load(),
+		FilesStored: cfg.stats.filesStored.Load(),
+	}
+	for i, name := range parseKindNames {
+		s.Parses[name] = cfg.stats.parses[i].Load()
+	}
+	for i, name := range failureClassNames {
+		s.Failures[name] = cfg.stats.failures[i].Load()
+	}
+	return s
+}
+
+// recordFailure counts a failed parse by the error returned and the status
+// that was written for it.
+func (s *statsCounters) recordFailure(err error, status int) {
+	switch {
+	case err == nil:
+		return
+	case isValidationError(err):
+		s.failures[failValidation].Add(1)
+	case errors.Is(err, ErrParseQueueFull):
+		s.failures[failQueueFull].Add(1)
+	case status == http.StatusRequestEntityTooLarge:
+		s.failures[failTooLarge].Add(1)
+	case status == http.StatusUnsupportedMediaType:
+		s.failures[failUnsupportedType].Add(1)
+	case status >= http.StatusInternalServerError:
+		s.failures[failServerError].Add(1)
+	default:
+		s.failures[failClientError].Add(1)
+	}
+}
+
+// isValidationError reports whether err rejected the submission's fields.
+func isValidationError(err error) bool {
+	var fieldErrs FieldErrors
+	var validationErrs validator.ValidationErrors
+	var recordErrs RecordErrors
+	return errors.As(err, &fieldErrs) || errors.As(err, &validationErrs) || errors.As(err, &recordErrs)
+}
+
+// statusWriter remembers the status written through it.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the wrapped writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,96 @@
+package formparser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore persists uploaded files outside of memory. When Config.FileStore
+// is set, every accepted upload and its variants are stored through it under
+// the key produced by Config.KeyFunc, recorded on UploadedFile.StorageKey.
+type FileStore interface {
+	Store(ctx context.Context, key string, file *UploadedFile) error
+}
+
+// DiskStore is a FileStore that writes files below a root directory.
+type DiskStore struct {
+	Root string
+}
+
+// Store writes file to Root/key, creating parent directories as needed.
+func (s DiskStore) Store(ctx context.Context, key string, file *UploadedFile) error {
+	rel := filepath.FromSlash(key)
+	if !filepath.IsLocal(rel) {
+		return fmt.Errorf("invalid storage key: %s", key)
+	}
+	dst := filepath.Join(s.Root, rel)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, file.Content, 0o644)
+}
+
+// storeFile saves file through the configured FileStore, if any.
+func (cfg *Config) storeFile(ctx context.Context, field string, file *UploadedFile) error {
+	if cfg.FileStore == nil {
+		return nil
+	}
+	keyFunc := cfg.KeyFunc
+	if keyFunc == nil {
+		keyFunc = DefaultKey
+	}
+	key := strings.TrimPrefix(keyFunc(field, file), "/")
+	err := cfg.callHook(ctx, "FileStore", func(ctx context.Context) error {
+		return cfg.FileStore.Store(ctx, key, file)
+	})
+	if errors.Is(err, errHookSkipped) {
+		return nil // kept in memory only
+	}
+	if err != nil {
+		return err
+	}
+	file.StorageKey = key
+	cfg.stats.filesStored.Add(1)
+	return nil
+}
+
+// pendingFile is an accepted upload whose persisting waits for the trailer
+// checksum.
+type pendingFile struct {
+	field string
+	file  *UploadedFile
+}
+
+// persistFile stores file and its variants through the FileStore and
+// records it in the DedupStore. While a trailer checksum is pending the work
+// is queued on res instead and done by flushFiles once the checksum
+// matched, so a body that fails verification leaves nothing stored.
+func (cfg *Config) persistFile(ctx context.Context, res *ParseResult, field string, file *UploadedFile) error {
+	if res.deferFiles {
+		res.pendingFiles = append(res.pendingFiles, pendingFile{field, file})
+		return nil
+	}
+	for _, f := range append([]*UploadedFile{file}, file.Variants...) {
+		if err := cfg.storeFile(ctx, field, f); err != nil {
+			return err
+		}
+	}
+	return cfg.rememberFile(ctx, file)
+}
+
+// flushFiles persists the files persistFile queued on res.
+func (cfg *Config) flushFiles(ctx context.Context, res *ParseResult) error {
+	res.deferFiles = false
+	pending := res.pendingFiles
+	res.pendingFiles = nil
+	for _, p := range pending {
+		if err := cfg.persistFile(ctx, res, p.field, p.file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package formparser
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// StreamResult is the outcome of ParseStream and ParseRawRequest.
+type StreamResult struct {
+	Request *http.Request // the request the body was parsed as
+	Result  *ParseResult  // the details an HTTP parse would have recorded
+	Status  int           // the status an HTTP handler would have answered with
+	Header  http.Header   // the response headers it would have set, e.g. Accept-Encoding on a 415
+	Body    []byte        // the error response it would have written
+}
+
+// ParseStream parses body into dst as the body of a POST carrying header,
+// for callers that hold a request's parts rather than an *http.Request:
+// proxies, request replayers and packet-capture tools. Header is used as
+// is, so it needs a Content-Type and may carry Content-Length,
+// Content-Encoding and the other headers a handler would see; ctx plays
+// the request context for enrichers and other hooks.
+//
+// As with ValidatePayload, the error is the one the HTTP parse would have
+// returned and the StreamResult describes the response it would have
+// written. Parses are counted in cfg's Stats.
+func (cfg *Config) ParseStream(ctx context.Context, header http.Header, body io.Reader, dst interface{}) (*StreamResult, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", body)
+	if err != nil {
+		return nil, err
+	}
+	r.Header = header.Clone()
+	r.ContentLength = -1
+	if n, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil && n >= 0 {
+		r.ContentLength = n
+	}
+	return cfg.parseDetached(r, dst)
+}
+
+// ParseRawRequest reads one HTTP/1.x request from br, as written on the
+// wire, and parses its body into dst like ParseStream. Chunked bodies and
+// trailers are handled as by a server. Whether or not the parse succeeds,
+// the rest of the body is consumed, so br is left at the start of the next
+// request of a keep-alive stream.
+func (cfg *Config) ParseRawRequest(ctx context.Context, br *bufio.Reader, dst interface{}) (*StreamResult, error) {
+	r, err := http.ReadRequest(br)
+	if err != nil {
+		return nil, err
+	}
+	r = r.WithContext(ctx)
+	body := r.Body
+	defer body.Close() // drains what the parse left unread
+	return cfg.parseDetached(r, dst)
+}
+
+// parseDetached parses r into dst, recording the response in memory.
+func (cfg *Config) parseDetached(r *http.Request, dst interface{}) (*StreamResult, error) {
+	w := &payloadWriter{header: make(http.Header)}
+	out := &StreamResult{Request: r}
+	var err error
+	out.Result, err = cfg.parse(w, r, dst)
+	out.Status, out.Header, out.Body = w.status, w.header, w.body.Bytes()
+	if out.Status == 0 {
+		out.Status = http.StatusOK
+	}
+	return out, err
+}
@@ -0,0 +1,241 @@
+package formparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Kinds of TagFinding.
+const (
+	FindingUnknownRule   = "unknown_rule"   // a validate rule the validator does not know
+	FindingUnknownTag    = "unknown_tag"    // a struct tag key one typo away from one formparser reads
+	FindingTagMismatch   = "tag_mismatch"   // json and form tags naming different keys
+	FindingUnexportedTag = "unexported_tag" // tags on an unexported field, which is never bound
+	FindingInvalidTag    = "invalid_tag"    // a formparser tag whose value cannot be used
+	FindingMissingConfig = "missing_config" // a tag that needs Config the parser lacks
+)
+
+// TagFinding is one struct tag mistake found by CheckStruct.
+type TagFinding struct {
+	Field   string // dotted Go field path, e.g. "Address.Zip"
+	Tag     string // the struct tag key at fault, e.g. "validate"
+	Kind    string
+	Message string
+}
+
+func (f TagFinding) String() string {
+	return fmt.Sprintf("%s: %s", f.Field, f.Message)
+}
+
+// structTagKeys are the struct tag keys formparser and its decoders read.
+var structTagKeys = []string{
+	"json", "form", "validate", "xml", "yaml", "toml", "msgpack", "cbor", "csv",
+	"confirm", "address", "body", "ctx", "cc", "readonly", "sensitive",
+	"file", "file_required_if", "maxbytes", "encoding", "split", "unit", "state", "iso",
+}
+
+// foreignTagKeys are common tag keys of other libraries that are one typo
+// away from a key in structTagKeys.
+var foreignTagKeys = map[string]bool{"bson": true}
+
+// CheckStruct reports common struct tag mistakes in dst, a struct or
+// pointer to struct, and the structs nested in it: validate rules the
+// validator does not know, misspelled tag keys, json and form tags that
+// disagree, tags on unexported fields, and tags that need configuration
+// cfg lacks (file_required_if without AllowedMIMETypes, state without
+// FormStateSecret, ctx without an Enricher, address without an
+// AddressNormalizer). It is meant to be called from tests:
+//
+//	for _, f := range formparser.CheckStruct(&SignupForm{}, cfg) {
+//		t.Error(f)
+//	}
+//
+// cfg may be nil, in which case rules are checked against a fresh
+// validator and configuration is not checked.
+func CheckStruct(dst interface{}, cfg *Config) []TagFinding {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	checker := cfg
+	if checker == nil || checker.Validator == nil {
+		checker = &Config{Validator: validator.New()}
+	}
+
+	var findings []TagFinding
+	seen := map[reflect.Type]bool{}
+	var walk func(t reflect.Type, path string)
+	walk = func(t reflect.Type, path string) {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || seen[t] {
+			return
+		}
+		seen[t] = true
+
+		mismatches := map[string]TagMismatch{}
+		for _, m := range TagMismatches(reflect.New(t).Interface()) {
+			mismatches[m.Field] = m
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := path + f.Name
+			report := func(tag, kind, format string, args ...any) {
+				findings = append(findings, TagFinding{Field: name, Tag: tag, Kind: kind, Message: fmt.Sprintf(format, args...)})
+			}
+
+			for _, key := range tagKeys(f.Tag) {
+				if suggestion := misspelledTagKey(key); suggestion != "" {
+					report(key, FindingUnknownTag, "unknown tag %q, did you mean %q?", key, suggestion)
+				}
+			}
+			if !f.IsExported() {
+				if keys := tagKeys(f.Tag); len(keys) > 0 && !f.Anonymous {
+					report(keys[0], FindingUnexportedTag, "unexported field has %s tags and is never bound", strings.Join(keys, ", "))
+				}
+				continue
+			}
+			for _, rule := range strings.FieldsFunc(f.Tag.Get("validate"), func(r rune) bool { return r == ',' || r == '|' }) {
+				tag, _, _ := strings.Cut(rule, "=")
+				if !validatorKeywords[tag] && !checker.knowsValidateTag(f.Type, rule) {
+					report("validate", FindingUnknownRule, "unknown validate rule %q", tag)
+				}
+			}
+			if m, ok := mismatches[f.Name]; ok {
+				report("form", FindingTagMismatch, "json tag %q and form tag %q name different keys", m.JSON, m.Form)
+			}
+			if params := strings.Fields(f.Tag.Get("file_required_if")); len(params) > 0 {
+				if len(params)%2 != 0 {
+					report("file_required_if", FindingInvalidTag, "file_required_if needs field/value pairs")
+				}
+				for p := 0; p+1 < len(params); p += 2 {
+					if fieldIndexByName(t, params[p]) < 0 {
+						report("file_required_if", FindingInvalidTag, "file_required_if names unknown field %q", params[p])
+					}
+				}
+			}
+			if tag, ok := f.Tag.Lookup("file"); ok {
+				if _, err := parseFileRule(tag); err != nil {
+					report("file", FindingInvalidTag, "file tag: %v", err)
+				}
+			}
+			if tag, ok := f.Tag.Lookup("maxbytes"); ok {
+				if _, err := parseTextLimit(tag); err != nil {
+					report("maxbytes", FindingInvalidTag, "maxbytes tag: %v", err)
+				} else if !isStringField(f.Type) {
+					report("maxbytes", FindingInvalidTag, "maxbytes tag on a non-string field")
+				}
+			}
+			if path == "" && cfg != nil {
+				checkTagConfig(cfg, f, report)
+			}
+			walk(f.Type, name+".")
+		}
+	}
+	walk(t, "")
+	return findings
+}
+
+// checkTagConfig reports the tags of top-level field f that need
+// configuration cfg lacks.
+func checkTagConfig(cfg *Config, f reflect.StructField, report func(tag, kind, format string, args ...any)) {
+	if _, ok := f.Tag.Lookup("file_required_if"); ok && len(cfg.AllowedMIMETypes) == 0 {
+		report("file_required_if", FindingMissingConfig, "file field but AllowedMIMETypes is empty, so every upload is rejected")
+	}
+	if tag, ok := f.Tag.Lookup("file"); ok && len(cfg.AllowedMIMETypes) == 0 {
+		if rule, err := parseFileRule(tag); err == nil && rule.accept == nil {
+			report("file", FindingMissingConfig, "file tag without accept but AllowedMIMETypes is empty, so every upload is rejected")
+		}
+	}
+	if tag, ok := f.Tag.Lookup("file"); ok && !cfg.AllowFileTagMaxSize {
+		if rule, err := parseFileRule(tag); err == nil && rule.maxSize > cfg.maxFileSize() {
+			report("file", FindingMissingConfig, "file tag maxsize=%s exceeds MaxFileSize and is capped without AllowFileTagMaxSize", rule.size)
+		}
+	}
+	if f.Tag.Get("state") != "" && len(cfg.FormStateSecret) == 0 {
+		report("state", FindingMissingConfig, "state tag without FormStateSecret")
+	}
+	if _, ok := f.Tag.Lookup("ctx"); ok && cfg.Enricher == nil {
+		report("ctx", FindingMissingConfig, "ctx tag without Enricher, so the field is always zeroed")
+	}
+	if f.Tag.Get("address") != "" && cfg.AddressNormalizer == nil {
+		report("address", FindingMissingConfig, "address tag without AddressNormalizer")
+	}
+}
+
+// tagKeys returns the keys of a conventionally formatted struct tag.
+func tagKeys(tag reflect.StructTag) []string {
+	var keys []string
+	s := string(tag)
+	for {
+		s = strings.TrimLeft(s, " ")
+		key, rest, ok := strings.Cut(s, `:"`)
+		if !ok || key == "" || strings.ContainsAny(key, " \"") {
+			return keys
+		}
+		keys = append(keys, key)
+		end := 0
+		for end < len(rest) && rest[end] != '"' {
+			if rest[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(rest) {
+			return keys
+		}
+		s = rest[end+1:]
+	}
+}
+
+// misspelledTagKey returns the formparser tag key that key is one edit or
+// transposition away from, or "" when key is known or not a near miss.
+func misspelledTagKey(key string) string {
+	if len(key) < 3 || foreignTagKeys[key] {
+		return ""
+	}
+	for _, known := range structTagKeys {
+		if key == known {
+			return ""
+		}
+	}
+	for _, known := range structTagKeys {
+		if isOneEditAway(key, known) {
+			return known
+		}
+	}
+	return ""
+}
+
+// isOneEditAway reports whether a becomes b by one insertion, deletion,
+// substitution or transposition of adjacent characters.
+func isOneEditAway(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	switch len(b) - len(a) {
+	case 0:
+		var diff []int
+		for i := range a {
+			if a[i] != b[i] {
+				diff = append(diff, i)
+			}
+		}
+		return len(diff) == 1 ||
+			len(diff) == 2 && diff[1] == diff[0]+1 && a[diff[0]] == b[diff[1]] && a[diff[1]] == b[diff[0]]
+	case 1:
+		i := 0
+		for i < len(a) && a[i] == b[i] {
+			i++
+		}
+		return a[i:] == b[i+1:]
+	}
+	return false
+}
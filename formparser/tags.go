@@ -0,0 +1,155 @@
+package formparser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TagMode controls how disagreeing `json` and `form` struct tags are reconciled.
+type TagMode int
+
+const (
+	// TagModeDefault binds each content type by its own tag (json for JSON, form otherwise).
+	TagModeDefault TagMode = iota
+	// TagModePreferJSON makes form-encoded and multipart bodies bind by the json name.
+	TagModePreferJSON
+	// TagModePreferForm makes JSON bodies bind by the form name.
+	TagModePreferForm
+	// TagModeStrict makes Precompile fail when a destination type has conflicting tags.
+	TagModeStrict
+)
+
+func (m TagMode) String() string {
+	switch m {
+	case TagModeDefault:
+		return "default"
+	case TagModePreferJSON:
+		return "prefer_json"
+	case TagModePreferForm:
+		return "prefer_form"
+	case TagModeStrict:
+		return "strict"
+	}
+	return fmt.Sprintf("TagMode(%d)", int(m))
+}
+
+// TagMismatch describes a struct field whose json and form tags disagree.
+type TagMismatch struct {
+	Field string // Go field name
+	JSON  string // name from the json tag
+	Form  string // name from the form tag
+}
+
+func (m TagMismatch) String() string {
+	return fmt.Sprintf("%s: json %q != form %q", m.Field, m.JSON, m.Form)
+}
+
+// tagMismatchCache maps reflect.Type to the []TagMismatch found for it.
+var tagMismatchCache sync.Map
+
+// TagMismatches reports the top-level fields of dst (a struct or pointer to
+// struct) whose explicit json and form tags name different keys.
+func TagMismatches(dst interface{}) []TagMismatch {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := tagMismatchCache.Load(t); ok {
+		return cached.([]TagMismatch)
+	}
+
+	var mismatches []TagMismatch
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		jsonName := tagName(f.Tag.Get("json"))
+		formName := tagName(f.Tag.Get("form"))
+		if jsonName == "" || formName == "" || jsonName == "-" || formName == "-" {
+			continue
+		}
+		if jsonName != formName {
+			mismatches = append(mismatches, TagMismatch{Field: f.Name, JSON: jsonName, Form: formName})
+		}
+	}
+
+	tagMismatchCache.Store(t, mismatches)
+	return mismatches
+}
+
+// Precompile inspects the given destination types ahead of the first request
+// so configuration mistakes surface at startup instead of at request time.
+func (cfg *Config) Precompile(dsts ...interface{}) error {
+	var errs []error
+	for _, dst := range dsts {
+		if cfg.Validator != nil {
+			if unknown := cfg.unknownValidateTags(reflect.TypeOf(dst)); len(unknown) > 0 {
+				errs = append(errs, fmt.Errorf("%T: unknown validate tags: %s", dst, strings.Join(unknown, "; ")))
+			}
+		}
+
+		mismatches := TagMismatches(dst)
+		if cfg.TagMode == TagModeStrict && len(mismatches) > 0 {
+			details := make([]string, len(mismatches))
+			for i, m := range mismatches {
+				details[i] = m.String()
+			}
+			errs = append(errs, fmt.Errorf("%T: conflicting json/form tags: %s", dst, strings.Join(details, "; ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reconcileFormValues copies values submitted under a field's json name onto
+// its form name when TagModePreferJSON is set.
+func (cfg *Config) reconcileFormValues(dst interface{}, values url.Values) {
+	if cfg.TagMode != TagModePreferJSON {
+		return
+	}
+	for _, m := range TagMismatches(dst) {
+		if v, ok := values[m.JSON]; ok {
+			values[m.Form] = v
+		}
+	}
+}
+
+// decodeJSONBody decodes r into dst, renaming form-named keys to their json
+// names first when TagModePreferForm is set and dst has conflicting tags.
+func (cfg *Config) decodeJSONBody(r io.Reader, dst interface{}) error {
+	mismatches := TagMismatches(dst)
+	if cfg.TagMode != TagModePreferForm || len(mismatches) == 0 {
+		return json.NewDecoder(r).Decode(dst)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return err
+	}
+	for _, m := range mismatches {
+		if v, ok := raw[m.Form]; ok {
+			raw[m.JSON] = v
+			delete(raw, m.Form)
+		}
+	}
+	body, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, dst)
+}
+
+// tagName returns the name portion of a struct tag value.
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	return name
+}
@@ -0,0 +1,87 @@
+package formparser
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// defaultMessageTemplates render validation failures by rule tag. Templates
+// may use {field}, {tag}, {param} and {value}, where {value} is the rule
+// parameter followed by the field's `unit` tag, e.g. "5 MB".
+var defaultMessageTemplates = map[string]string{
+	"required": "{field} is required",
+	"email":    "{field} must be a valid email address",
+	"url":      "{field} must be a valid URL",
+	"min":      "{field} must be at least {value}",
+	"max":      "{field} must be at most {value}",
+	"len":      "{field} must be exactly {value}",
+	"gt":       "{field} must be greater than {value}",
+	"gte":      "{field} must be at least {value}",
+	"lt":       "{field} must be smaller than {value}",
+	"lte":      "{field} must be at most {value}",
+	"eq":       "{field} must equal {value}",
+	"ne":       "{field} must not equal {value}",
+	"oneof":    "{field} must be one of {param}",
+	"ip":       "{field} must be a valid IP address",
+	"ipv4":     "{field} must be a valid IPv4 address",
+	"ipv6":     "{field} must be a valid IPv6 address",
+	"cidr":     "{field} must be a valid CIDR prefix",
+	"cidrv4":   "{field} must be a valid IPv4 CIDR prefix",
+	"cidrv6":   "{field} must be a valid IPv6 CIDR prefix",
+	"mac":      "{field} must be a valid MAC address",
+	"port":     "{field} must be a valid port",
+}
+
+// fallbackMessageTemplate is used for rules without a template.
+const fallbackMessageTemplate = "{field} is {tag}"
+
+// defaultMessage builds the message for a failed rule when no custom message
+// exists, preferring Config.MessageTemplates over the built-in templates.
+func (cfg *Config) defaultMessage(dst interface{}, field string, ve validator.FieldError) string {
+	tmpl, ok := cfg.MessageTemplates[ve.Tag()]
+	if !ok {
+		tmpl, ok = defaultMessageTemplates[ve.Tag()]
+	}
+	if !ok {
+		tmpl = fallbackMessageTemplate
+	}
+
+	value := ve.Param()
+	if sf, ok := structFieldFor(dst, ve.StructNamespace()); ok {
+		if unit := sf.Tag.Get("unit"); unit != "" && value != "" {
+			value += " " + unit
+		}
+	}
+
+	return strings.NewReplacer(
+		"{field}", field,
+		"{tag}", ve.Tag(),
+		"{param}", ve.Param(),
+		"{value}", value,
+	).Replace(tmpl)
+}
+
+// structFieldFor resolves a validator struct namespace such as
+// "Form.Address.Zip" to the struct field it names within dst.
+func structFieldFor(dst interface{}, namespace string) (reflect.StructField, bool) {
+	t := reflect.TypeOf(dst)
+	parts := strings.Split(namespace, ".")
+	var sf reflect.StructField
+	for _, part := range parts[1:] {
+		for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			return reflect.StructField{}, false
+		}
+		name, _, _ := strings.Cut(part, "[")
+		var ok bool
+		if sf, ok = t.FieldByName(name); !ok {
+			return reflect.StructField{}, false
+		}
+		t = sf.Type
+	}
+	return sf, len(parts) > 1
+}
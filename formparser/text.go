@@ -0,0 +1,116 @@
+package formparser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// TextRule normalizes text uploads (text/plain, text/csv, ...) for a single
+// form field to UTF-8 without a byte order mark, so handlers and CSV
+// importers only ever see one encoding.
+type TextRule struct {
+	Fallback string // Optional: charset assumed for non-UTF-8 content without a BOM or declared charset (default "windows-1252")
+}
+
+const defaultTextFallback = "windows-1252"
+
+var byteOrderMarks = []struct {
+	bom     []byte
+	charset string
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, "utf-8"},
+	{[]byte{0xFF, 0xFE}, "utf-16le"},
+	{[]byte{0xFE, 0xFF}, "utf-16be"},
+}
+
+// normalizeText converts a text upload covered by a TextRule to UTF-8. The
+// source charset is taken from a byte order mark, else the part's charset
+// parameter, else UTF-8 when the content is valid UTF-8, else the rule's
+// Fallback. When the bytes change, the returned file keeps the upload in
+// its Original field.
+func (cfg *Config) normalizeText(field string, file *UploadedFile) (*UploadedFile, string) {
+	rule, ok := cfg.TextRules[field]
+	if !ok || !strings.HasPrefix(file.ContentType, "text/") {
+		return file, ""
+	}
+
+	content, charset := stripBOM(file.Content)
+	if charset == "" {
+		charset = declaredCharset(file.ContentType)
+	}
+	if charset == "" && utf8.Valid(content) {
+		charset = "utf-8"
+	}
+	if charset == "" {
+		charset = rule.Fallback
+		if charset == "" {
+			charset = defaultTextFallback
+		}
+	}
+
+	enc, err := textEncoding(charset)
+	if err != nil {
+		return file, fmt.Sprintf("%s has an unsupported charset", field)
+	}
+	if enc == encoding.Nop {
+		if !utf8.Valid(content) {
+			return file, fmt.Sprintf("%s is not valid UTF-8 text", field)
+		}
+	} else if content, err = enc.NewDecoder().Bytes(content); err != nil {
+		return file, fmt.Sprintf("%s is not valid %s text", field, charset)
+	}
+
+	if bytes.Equal(content, file.Content) {
+		file.Charset = charset
+		return file, ""
+	}
+	normalized := *file
+	normalized.Content = content
+	normalized.Size = int64(len(content))
+	normalized.Hash = fmt.Sprintf("%x", sha256.Sum256(content))
+	normalized.Charset = charset
+	normalized.Original = file
+	return &normalized, ""
+}
+
+// stripBOM removes a leading byte order mark and returns the charset it
+// announces, or "" when there is none.
+func stripBOM(content []byte) ([]byte, string) {
+	for _, m := range byteOrderMarks {
+		if bytes.HasPrefix(content, m.bom) {
+			return content[len(m.bom):], m.charset
+		}
+	}
+	return content, ""
+}
+
+// declaredCharset returns the charset parameter of contentType, if any.
+func declaredCharset(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(params["charset"])
+}
+
+// textEncoding looks up charset by its WHATWG label. UTF-8 maps to
+// encoding.Nop and UTF-16 labels decode without expecting a BOM.
+func textEncoding(charset string) (encoding.Encoding, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8":
+		return encoding.Nop, nil
+	case "utf-16le", "utf-16":
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), nil
+	case "utf-16be":
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), nil
+	}
+	return htmlindex.Get(charset)
+}
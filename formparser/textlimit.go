@@ -0,0 +1,200 @@
+package formparser
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// TextLimitPolicy selects what happens to a string field longer than its
+// `maxbytes` tag allows.
+type TextLimitPolicy int
+
+const (
+	// TextLimitReject reports a field error for the oversized value. This
+	// is the default.
+	TextLimitReject TextLimitPolicy = iota
+	// TextLimitTruncate cuts the value to the limit, never inside a UTF-8
+	// character, and records a warning in ParseResult.Warnings.
+	TextLimitTruncate
+)
+
+// String returns the policy's name.
+func (p TextLimitPolicy) String() string {
+	switch p {
+	case TextLimitReject:
+		return "reject"
+	case TextLimitTruncate:
+		return "truncate"
+	default:
+		return fmt.Sprintf("TextLimitPolicy(%d)", int(p))
+	}
+}
+
+// textLimit is a string field tagged
+// `maxbytes:"<n>[,runes][,reject|truncate]"`.
+type textLimit struct {
+	index  int
+	name   string // field error key
+	max    int
+	runes  bool             // count characters instead of bytes
+	policy *TextLimitPolicy // nil uses Config.TextLimitPolicy
+}
+
+// unit names what a limit counts, for messages.
+func (l textLimit) unit() string {
+	if l.runes {
+		return "characters"
+	}
+	return "bytes"
+}
+
+// textLimitsCache maps reflect.Type to []textLimit.
+var textLimitsCache sync.Map
+
+// textLimits returns the top-level string and *string fields of dst tagged
+// `maxbytes`. Tags that fail to parse are skipped; CheckStruct reports
+// them.
+func textLimits(dst interface{}) []textLimit {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := textLimitsCache.Load(t); ok {
+		return cached.([]textLimit)
+	}
+
+	var limits []textLimit
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("maxbytes")
+		if !ok || !f.IsExported() || !isStringField(f.Type) {
+			continue
+		}
+		limit, err := parseTextLimit(tag)
+		if err != nil {
+			continue
+		}
+		limit.index, limit.name = i, strings.ToLower(f.Name)
+		limits = append(limits, limit)
+	}
+
+	textLimitsCache.Store(t, limits)
+	return limits
+}
+
+// isStringField reports whether t is string or *string.
+func isStringField(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String
+}
+
+// parseTextLimit parses a `maxbytes` tag.
+func parseTextLimit(tag string) (textLimit, error) {
+	opts := strings.Split(tag, ",")
+	n, err := strconv.Atoi(strings.TrimSpace(opts[0]))
+	if err != nil || n < 1 {
+		return textLimit{}, fmt.Errorf("invalid limit %q", opts[0])
+	}
+	limit := textLimit{max: n}
+	for _, opt := range opts[1:] {
+		switch strings.TrimSpace(opt) {
+		case "bytes":
+			limit.runes = false
+		case "runes":
+			limit.runes = true
+		case "reject":
+			policy := TextLimitReject
+			limit.policy = &policy
+		case "truncate":
+			policy := TextLimitTruncate
+			limit.policy = &policy
+		default:
+			return textLimit{}, fmt.Errorf("unknown option %q", opt)
+		}
+	}
+	return limit, nil
+}
+
+// limitText applies the `maxbytes` tags of dst. Oversized values become
+// field errors or, under TextLimitTruncate, are cut to the limit with a
+// warning in res.Warnings.
+func (cfg *Config) limitText(r *http.Request, dst interface{}, res *ParseResult) FieldErrors {
+	limits := textLimits(dst)
+	if len(limits) == 0 {
+		return nil
+	}
+	fieldErrors := make(FieldErrors)
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, l := range limits {
+		field := v.Field(l.index)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		s := field.String()
+		if l.length(s) <= l.max {
+			continue
+		}
+		policy := cfg.TextLimitPolicy
+		if l.policy != nil {
+			policy = *l.policy
+		}
+		if policy == TextLimitTruncate {
+			field.SetString(l.truncate(s))
+			res.addWarning(l.name, fmt.Sprintf("%s was truncated to %d %s", l.name, l.max, l.unit()))
+			continue
+		}
+		if msg, ok := cfg.lookupMessage(l.name, "maxbytes", requestLang(r)); ok {
+			fieldErrors[l.name] = msg
+		} else {
+			fieldErrors[l.name] = fmt.Sprintf("%s must be at most %d %s", l.name, l.max, l.unit())
+		}
+	}
+	return fieldErrors
+}
+
+// length returns the size of s in the limit's unit.
+func (l textLimit) length(s string) int {
+	if l.runes {
+		return utf8.RuneCountInString(s)
+	}
+	return len(s)
+}
+
+// truncate cuts s to the limit, keeping whole UTF-8 characters.
+func (l textLimit) truncate(s string) string {
+	if l.runes {
+		i, n := 0, 0
+		for i < len(s) && n < l.max {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+			n++
+		}
+		return s[:i]
+	}
+	end := l.max
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
+
+// addWarning records a note about a change the parse made to field.
+func (res *ParseResult) addWarning(field, msg string) {
+	if res.Warnings == nil {
+		res.Warnings = make(map[string]string)
+	}
+	res.Warnings[field] = msg
+}
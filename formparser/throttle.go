@@ -0,0 +1,56 @@
+package formparser
+
+import (
+	"io"
+	"time"
+)
+
+// throttledBody limits how fast a request body is read with a token bucket
+// holding a tenth of a second's worth of bytes, so uploads proceed in
+// small, evenly spaced bursts instead of saturating memory and disk. It
+// measures real elapsed time, not Config.Clock, since it sleeps for real.
+type throttledBody struct {
+	io.ReadCloser
+	rate   float64 // bytes per second
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+func newThrottledBody(body io.ReadCloser, bytesPerSecond int64) *throttledBody {
+	burst := int(bytesPerSecond / 10)
+	if burst < 1 {
+		burst = 1
+	}
+	return &throttledBody{
+		ReadCloser: body,
+		rate:       float64(bytesPerSecond),
+		burst:      burst,
+		tokens:     float64(burst),
+		last:       time.Now(),
+	}
+}
+
+func (b *throttledBody) Read(p []byte) (int, error) {
+	if len(p) > b.burst {
+		p = p[:b.burst]
+	}
+	b.refill()
+	if missing := float64(len(p)) - b.tokens; missing > 0 {
+		time.Sleep(time.Duration(missing / b.rate * float64(time.Second)))
+		b.refill()
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.tokens -= float64(n)
+	return n, err
+}
+
+// refill adds the tokens earned since the last call, up to the burst size.
+func (b *throttledBody) refill() {
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > float64(b.burst) {
+		b.tokens = float64(b.burst)
+	}
+	b.last = now
+}
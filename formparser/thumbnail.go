@@ -0,0 +1,96 @@
+package formparser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	_ "image/gif" // register GIF decoding for thumbnail sources
+	"image/jpeg"
+	"image/png"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+const defaultMaxImagePixels = 50_000_000 // 50 megapixels
+
+// ThumbnailSize is a bounding box for a generated image variant. The image is
+// scaled down to fit inside it while keeping its aspect ratio.
+type ThumbnailSize struct {
+	Width  int
+	Height int
+}
+
+// generateThumbnails builds the variants configured for field from an image
+// upload. It returns a field error message or "".
+func (cfg *Config) generateThumbnails(field string, file *UploadedFile) string {
+	sizes := cfg.Thumbnails[field]
+	if len(sizes) == 0 || !strings.HasPrefix(file.ContentType, "image/") {
+		return ""
+	}
+
+	// The header alone gives the dimensions; check them before decoding
+	// allocates width×height pixels.
+	header, _, err := image.DecodeConfig(bytes.NewReader(file.Content))
+	if err != nil {
+		return fmt.Sprintf("%s could not be read as an image", field)
+	}
+	if header.Width <= 0 || header.Height <= 0 || header.Width > cfg.maxImagePixels()/header.Height {
+		return fmt.Sprintf("%s has too many pixels (max %d)", field, cfg.maxImagePixels())
+	}
+	src, format, err := image.Decode(bytes.NewReader(file.Content))
+	if err != nil {
+		return fmt.Sprintf("%s could not be read as an image", field)
+	}
+
+	base := strings.TrimSuffix(file.Filename, filepath.Ext(file.Filename))
+	for _, size := range sizes {
+		var buf bytes.Buffer
+		contentType, ext := "image/png", ".png"
+		thumb := scaleToFit(src, size)
+		if format == "jpeg" {
+			contentType, ext = "image/jpeg", ".jpg"
+			err = jpeg.Encode(&buf, thumb, nil)
+		} else {
+			err = png.Encode(&buf, thumb)
+		}
+		if err != nil {
+			return fmt.Sprintf("%s could not be resized", field)
+		}
+
+		file.Variants = append(file.Variants, &UploadedFile{
+			Filename:    fmt.Sprintf("%s_%dx%d%s", base, size.Width, size.Height, ext),
+			ContentType: contentType,
+			Content:     buf.Bytes(),
+			Hash:        fmt.Sprintf("%x", sha256.Sum256(buf.Bytes())),
+		})
+	}
+	return ""
+}
+
+// maxImagePixels returns MaxImagePixels or the default when unset.
+func (cfg *Config) maxImagePixels() int {
+	if cfg.MaxImagePixels > 0 {
+		return cfg.MaxImagePixels
+	}
+	return defaultMaxImagePixels
+}
+
+// scaleToFit downscales src with a bilinear kernel so it fits inside size.
+// Images already within the box are returned unchanged.
+func scaleToFit(src image.Image, size ThumbnailSize) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size.Width && h <= size.Height {
+		return src
+	}
+
+	scale := min(float64(size.Width)/float64(w), float64(size.Height)/float64(h))
+	dw, dh := max(1, int(float64(w)*scale)), max(1, int(float64(h)*scale))
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	draw.BiLinear.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
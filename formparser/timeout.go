@@ -0,0 +1,147 @@
+package formparser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrReadTimeout is returned when a multipart or compressed body stops
+// arriving for longer than PartIdleTimeout or arrives slower than
+// MinReadRate.
+var ErrReadTimeout = errors.New("formparser: request body read timed out")
+
+// readRateGrace is how long a body may take to get going before MinReadRate
+// is enforced, so connection setup and slow starts are not penalized.
+const readRateGrace = time.Second
+
+// guardBody wraps body with the upload throttle and the idle and rate
+// limits, or returns it as is when none is configured. The throttle sits
+// inside the limits, so MinReadRate must stay below MaxUploadBytesPerSecond.
+// Bodies it already guards are returned as is.
+func (cfg *Config) guardBody(body io.ReadCloser) io.ReadCloser {
+	switch body.(type) {
+	case *throttledBody, *timeoutBody, guardedBody:
+		return body
+	}
+	if cfg.MaxUploadBytesPerSecond > 0 {
+		body = newThrottledBody(body, cfg.MaxUploadBytesPerSecond)
+	}
+	if cfg.PartIdleTimeout <= 0 && cfg.MinReadRate <= 0 {
+		return body
+	}
+	return &timeoutBody{
+		ReadCloser: body,
+		idle:       cfg.PartIdleTimeout,
+		minRate:    cfg.MinReadRate,
+		start:      time.Now(),
+	}
+}
+
+// guardedBody is a body read from the wire through guardBody and buffered,
+// such as a decompressed body, which must not be throttled again.
+type guardedBody struct {
+	io.ReadCloser
+}
+
+// timeoutBody enforces read deadlines on a request body. Reads run on a
+// helper goroutine so a stalled client cannot block the handler; after a
+// timeout that goroutine is released when the server closes the body.
+// Deadlines are wall-clock timers, so Config.Clock does not apply.
+type timeoutBody struct {
+	io.ReadCloser
+	idle    time.Duration
+	minRate int64
+	start   time.Time
+	read    int64
+
+	pending chan readResult // in-flight read, nil when none
+	buf     []byte          // data read ahead of the caller
+	err     error           // sticky error once the body failed
+}
+
+type readResult struct {
+	data []byte
+	err  error
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	if len(b.buf) > 0 {
+		n := copy(p, b.buf)
+		b.buf = b.buf[n:]
+		return n, nil
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.pending == nil {
+		b.pending = make(chan readResult, 1)
+		go func(ch chan<- readResult, size int) {
+			data := make([]byte, size)
+			n, err := b.ReadCloser.Read(data)
+			ch <- readResult{data[:n], err}
+		}(b.pending, len(p))
+	}
+
+	wait, reason := b.deadline()
+	if wait <= 0 {
+		b.err = fmt.Errorf("%w: %s", ErrReadTimeout, reason)
+		return 0, b.err
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case res := <-b.pending:
+		b.pending = nil
+		b.read += int64(len(res.data))
+		n := copy(p, res.data)
+		b.buf = res.data[n:]
+		if res.err != nil {
+			b.err = res.err
+		}
+		if n == 0 && res.err != nil {
+			return 0, res.err
+		}
+		return n, nil
+	case <-timer.C:
+		b.err = fmt.Errorf("%w: %s", ErrReadTimeout, reason)
+		return 0, b.err
+	}
+}
+
+// deadline returns how long the next read may take and the limit that
+// bounds it.
+func (b *timeoutBody) deadline() (time.Duration, string) {
+	wait, reason := time.Duration(-1), ""
+	if b.idle > 0 {
+		wait, reason = b.idle, fmt.Sprintf("no data for %s", b.idle)
+	}
+	if b.minRate > 0 {
+		// The next byte must arrive before the average drops below minRate.
+		due := b.start.Add(readRateGrace + time.Duration(float64(b.read+1)/float64(b.minRate)*float64(time.Second)))
+		rateWait := time.Until(due)
+		if wait < 0 || rateWait < wait {
+			wait, reason = rateWait, fmt.Sprintf("slower than %d bytes/s", b.minRate)
+		}
+	}
+	return wait, reason
+}
+
+// readFailed responds to an error reading body: 408 with the connection
+// closed when body timed out, else status with msg. The timeout is taken
+// from body itself because mime/multipart reports some read errors as
+// malformed input.
+func readFailed(w http.ResponseWriter, body io.Reader, err error, msg string, status int) error {
+	if tb, ok := body.(*timeoutBody); ok && errors.Is(tb.err, ErrReadTimeout) {
+		err = tb.err
+	}
+	if errors.Is(err, ErrReadTimeout) {
+		w.Header().Set("Connection", "close")
+		http.Error(w, "Request body read timed out", http.StatusRequestTimeout)
+		return err
+	}
+	http.Error(w, msg, status)
+	return err
+}
@@ -0,0 +1,95 @@
+package formparser
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ChecksumMismatchError is returned when a checksum sent in the request
+// trailers does not match the SHA-256 of the body actually received.
+type ChecksumMismatchError struct {
+	Trailer  string // trailer that carried the checksum
+	Expected string // hex digest claimed by the client
+	Actual   string // hex digest of the received body
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch in %s trailer: expected %s, got %s", e.Trailer, e.Expected, e.Actual)
+}
+
+// checksumBody hashes a request body as it is consumed.
+type checksumBody struct {
+	io.ReadCloser
+	hash hash.Hash
+}
+
+func (b *checksumBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.hash.Write(p[:n])
+	return n, err
+}
+
+// wrapChecksumBody starts hashing r.Body when trailer checksums are enabled.
+func (cfg *Config) wrapChecksumBody(r *http.Request) *checksumBody {
+	if !cfg.VerifyTrailerChecksum {
+		return nil
+	}
+	body := &checksumBody{ReadCloser: r.Body, hash: sha256.New()}
+	r.Body = body
+	return body
+}
+
+// verifyTrailerChecksum drains the body so trailers become available, then
+// compares a Content-Digest/Repr-Digest (sha-256=:base64:) or
+// X-Content-SHA256 (hex) trailer with the computed digest. Requests without
+// a checksum trailer pass, as do digest trailers naming only algorithms
+// other than sha-256, which cannot be checked.
+func verifyTrailerChecksum(r *http.Request, body *checksumBody) error {
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return err
+	}
+	actual := hex.EncodeToString(body.hash.Sum(nil))
+
+	for _, name := range []string{"Content-Digest", "Repr-Digest", "X-Content-SHA256"} {
+		value := r.Trailer.Get(name)
+		if value == "" {
+			continue
+		}
+		expected, ok := trailerDigest(name, value)
+		if !ok {
+			continue
+		}
+		if expected != actual {
+			return &ChecksumMismatchError{Trailer: name, Expected: expected, Actual: actual}
+		}
+	}
+	return nil
+}
+
+// trailerDigest extracts a lower-case hex SHA-256 digest from a trailer
+// value, or returns false when it has none. A malformed sha-256 member
+// yields a digest no body matches.
+func trailerDigest(name, value string) (string, bool) {
+	if name == "X-Content-SHA256" {
+		return strings.ToLower(strings.TrimSpace(value)), true
+	}
+	for _, member := range strings.Split(value, ",") {
+		algo, raw, ok := strings.Cut(strings.TrimSpace(member), "=")
+		if !ok || !strings.EqualFold(algo, "sha-256") {
+			continue
+		}
+		encoded := strings.Trim(raw, ":")
+		sum, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return encoded, true
+		}
+		return hex.EncodeToString(sum), true
+	}
+	return "", false
+}
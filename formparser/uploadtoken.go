@@ -0,0 +1,93 @@
+package formparser
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UploadGrant is the content of a signed upload token: it authorizes one
+// file with an exact name, size and MIME type for a single form field.
+type UploadGrant struct {
+	Field       string    `json:"field"`
+	Filename    string    `json:"filename"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	Expires     time.Time `json:"expires"`
+}
+
+var (
+	// ErrInvalidUploadToken is returned for malformed or tampered tokens.
+	ErrInvalidUploadToken = errors.New("invalid upload token")
+	// ErrExpiredUploadToken is returned for tokens past their expiry.
+	ErrExpiredUploadToken = errors.New("expired upload token")
+)
+
+// SignUploadToken issues a token for grant using UploadTokenSecret. The app
+// hands it to the client, which posts it in the field's token field.
+func (cfg *Config) SignUploadToken(grant UploadGrant) (string, error) {
+	if len(cfg.UploadTokenSecret) == 0 {
+		return "", errors.New("formparser: UploadTokenSecret is not set")
+	}
+	payload, err := json.Marshal(grant)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(cfg.signUploadPayload(payload)), nil
+}
+
+// VerifyUploadToken checks the token's signature and expiry and returns its grant.
+func (cfg *Config) VerifyUploadToken(token string) (*UploadGrant, error) {
+	enc := base64.RawURLEncoding
+	rawPayload, rawSig, ok := strings.Cut(token, ".")
+	if !ok || len(cfg.UploadTokenSecret) == 0 {
+		return nil, ErrInvalidUploadToken
+	}
+	payload, err := enc.DecodeString(rawPayload)
+	if err != nil {
+		return nil, ErrInvalidUploadToken
+	}
+	sig, err := enc.DecodeString(rawSig)
+	if err != nil || !hmac.Equal(sig, cfg.signUploadPayload(payload)) {
+		return nil, ErrInvalidUploadToken
+	}
+
+	var grant UploadGrant
+	if err := json.Unmarshal(payload, &grant); err != nil {
+		return nil, ErrInvalidUploadToken
+	}
+	if !grant.Expires.IsZero() && cfg.now().After(grant.Expires) {
+		return nil, ErrExpiredUploadToken
+	}
+	return &grant, nil
+}
+
+// signUploadPayload returns the HMAC-SHA256 of payload.
+func (cfg *Config) signUploadPayload(payload []byte) []byte {
+	mac := hmac.New(sha256.New, cfg.UploadTokenSecret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// uploadGrantFor verifies the token required for a file field, if any, taking
+// it from the already-parsed sibling field. It returns the grant (nil when the
+// field needs no token) or a field error message.
+func (cfg *Config) uploadGrantFor(h FileHeader, token string) (*UploadGrant, string) {
+	if _, required := cfg.UploadTokenFields[h.Field]; !required {
+		return nil, ""
+	}
+	grant, err := cfg.VerifyUploadToken(token)
+	if err != nil {
+		return nil, fmt.Sprintf("%s has an %s", h.Field, err)
+	}
+	if grant.Field != h.Field || grant.Filename != h.Filename || grant.ContentType != h.ContentType {
+		return nil, fmt.Sprintf("%s does not match its upload token", h.Field)
+	}
+	return grant, ""
+}
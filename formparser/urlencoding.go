@@ -0,0 +1,150 @@
+package formparser
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxURLEncodedBody matches the body limit net/http applies in ParseForm.
+const maxURLEncodedBody = 10 << 20
+
+// URLEncodingMode selects how application/x-www-form-urlencoded bodies and
+// query strings are split and unescaped. In every mode '+' decodes to a
+// space, as the spec requires; a literal plus must be sent as %2B.
+type URLEncodingMode int
+
+const (
+	// URLEncodingDefault leaves parsing to net/http: a body with a ';'
+	// separator is rejected, while a query string silently drops the pairs
+	// it cannot parse.
+	URLEncodingDefault URLEncodingMode = iota
+	// URLEncodingStrict rejects ';' separators, empty keys and malformed
+	// percent escapes in both bodies and query strings.
+	URLEncodingStrict
+	// URLEncodingLenient accepts ';' as a separator, keeps malformed escapes
+	// literally and drops pairs with empty keys, logging each correction.
+	URLEncodingLenient
+)
+
+// String returns the mode's name.
+func (m URLEncodingMode) String() string {
+	switch m {
+	case URLEncodingDefault:
+		return "default"
+	case URLEncodingStrict:
+		return "strict"
+	case URLEncodingLenient:
+		return "lenient"
+	default:
+		return fmt.Sprintf("URLEncodingMode(%d)", int(m))
+	}
+}
+
+// URLEncodingError describes a pair rejected by URLEncodingStrict.
+type URLEncodingError struct {
+	Pair   string
+	Reason string
+}
+
+func (e *URLEncodingError) Error() string {
+	return fmt.Sprintf("invalid form encoding in %q: %s", redactPANs(e.Pair), e.Reason)
+}
+
+// readPostForm parses a url-encoded request body according to
+// URLEncodingMode.
+func (cfg *Config) readPostForm(r *http.Request) (url.Values, error) {
+	if cfg.URLEncoding == URLEncodingDefault {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		return r.PostForm, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxURLEncodedBody+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxURLEncodedBody {
+		return nil, fmt.Errorf("form body larger than %d bytes", maxURLEncodedBody)
+	}
+	values, err := cfg.parseURLEncoding(string(body))
+	if err != nil {
+		return nil, err
+	}
+	r.PostForm = values
+	return values, nil
+}
+
+// queryValues parses the request's query string according to
+// URLEncodingMode.
+func (cfg *Config) queryValues(r *http.Request) (url.Values, error) {
+	if cfg.URLEncoding == URLEncodingDefault {
+		return r.URL.Query(), nil
+	}
+	return cfg.parseURLEncoding(r.URL.RawQuery)
+}
+
+// parseURLEncoding splits and unescapes raw in strict or lenient mode.
+func (cfg *Config) parseURLEncoding(raw string) (url.Values, error) {
+	lenient := cfg.URLEncoding == URLEncodingLenient
+	separators := "&"
+	if lenient {
+		separators = "&;"
+	}
+
+	if lenient && strings.Contains(raw, ";") {
+		cfg.logCorrection("treated semicolon as separator", "")
+	}
+
+	values := make(url.Values)
+	for _, pair := range strings.FieldsFunc(raw, func(c rune) bool { return strings.ContainsRune(separators, c) }) {
+		if !lenient && strings.Contains(pair, ";") {
+			return nil, &URLEncodingError{Pair: pair, Reason: "semicolon separator"}
+		}
+
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			if !lenient {
+				return nil, &URLEncodingError{Pair: pair, Reason: "malformed escape in key"}
+			}
+			key = strings.ReplaceAll(rawKey, "+", " ")
+			cfg.logCorrection("kept malformed escape literally in key", key)
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			if !lenient {
+				return nil, &URLEncodingError{Pair: pair, Reason: "malformed escape in value"}
+			}
+			value = strings.ReplaceAll(rawValue, "+", " ")
+			cfg.logCorrection("kept malformed escape literally in value", key)
+		}
+		if key == "" {
+			if !lenient {
+				return nil, &URLEncodingError{Pair: pair, Reason: "empty key"}
+			}
+			cfg.logCorrection("dropped pair with empty key", "")
+			continue
+		}
+		values.Add(key, value)
+	}
+	return values, nil
+}
+
+// logCorrection reports a lenient-mode fix to Logger (default slog.Default).
+// Only the kind of correction and the key it applied to are logged; values
+// may hold secrets and the raw input is never written to the log.
+func (cfg *Config) logCorrection(correction, key string) {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if key == "" {
+		logger.Warn("formparser: corrected form encoding", "correction", correction)
+		return
+	}
+	logger.Warn("formparser: corrected form encoding", "correction", correction, "key", redactPANs(key))
+}
@@ -0,0 +1,202 @@
+package formparser
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var (
+	stringValueType = reflect.TypeFor[wrapperspb.StringValue]()
+	bytesValueType  = reflect.TypeFor[wrapperspb.BytesValue]()
+	boolValueType   = reflect.TypeFor[wrapperspb.BoolValue]()
+	int32ValueType  = reflect.TypeFor[wrapperspb.Int32Value]()
+	int64ValueType  = reflect.TypeFor[wrapperspb.Int64Value]()
+	uint32ValueType = reflect.TypeFor[wrapperspb.UInt32Value]()
+	uint64ValueType = reflect.TypeFor[wrapperspb.UInt64Value]()
+	floatValueType  = reflect.TypeFor[wrapperspb.FloatValue]()
+	doubleValueType = reflect.TypeFor[wrapperspb.DoubleValue]()
+	timestampType   = reflect.TypeFor[timestamppb.Timestamp]()
+)
+
+// isWrapperType reports whether t is a wrapperspb type or Timestamp.
+func isWrapperType(t reflect.Type) bool {
+	switch t {
+	case stringValueType, bytesValueType, boolValueType, int32ValueType, int64ValueType,
+		uint32ValueType, uint64ValueType, floatValueType, doubleValueType, timestampType:
+		return true
+	}
+	return false
+}
+
+// wrapperField is a top-level *wrapperspb.XxxValue or *timestamppb.Timestamp
+// field, as protoc-gen-go generates for wrapper and Timestamp fields.
+type wrapperField struct {
+	index int
+	key   string // form key
+	name  string // field error key
+	typ   reflect.Type
+}
+
+// wrapperFieldsCache maps reflect.Type to []wrapperField.
+var wrapperFieldsCache sync.Map
+
+// wrapperFields returns the top-level wrapper fields of dst.
+func wrapperFields(dst interface{}) []wrapperField {
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := wrapperFieldsCache.Load(t); ok {
+		return cached.([]wrapperField)
+	}
+
+	var fields []wrapperField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Type.Kind() != reflect.Ptr || !isWrapperType(f.Type.Elem()) {
+			continue
+		}
+		fields = append(fields, wrapperField{index: i, key: formKey(f), name: strings.ToLower(f.Name), typ: f.Type.Elem()})
+	}
+
+	wrapperFieldsCache.Store(t, fields)
+	return fields
+}
+
+// decodeWrapperValues parses values for wrapper and Timestamp fields straight
+// into dst and removes them from values, keeping proto presence semantics:
+// an absent key leaves the field nil, and a submitted StringValue or
+// BytesValue is set even when empty. Empty values of the other types leave
+// the field nil, as an unparsable value reports a field error.
+func decodeWrapperValues(dst interface{}, values url.Values, fieldErrors FieldErrors) {
+	fields := wrapperFields(dst)
+	if len(fields) == 0 {
+		return
+	}
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, f := range fields {
+		raw, ok := values[f.key]
+		if !ok {
+			continue
+		}
+		delete(values, f.key)
+		s := ""
+		if len(raw) > 0 {
+			s = raw[0]
+		}
+		if f.typ != stringValueType {
+			s = strings.TrimSpace(s)
+		}
+		if s == "" && f.typ != stringValueType && f.typ != bytesValueType {
+			continue
+		}
+
+		parsed, msg := parseWrapperValue(f.typ, s)
+		if msg != "" {
+			fieldErrors[f.name] = f.name + " " + msg
+			continue
+		}
+		v.Field(f.index).Set(reflect.ValueOf(parsed))
+	}
+}
+
+// parseWrapperValue parses s into a new message of type typ, returning a
+// message fragment that explains the problem when it fails.
+func parseWrapperValue(typ reflect.Type, s string) (any, string) {
+	switch typ {
+	case stringValueType:
+		return wrapperspb.String(s), ""
+	case bytesValueType:
+		data, err := decodeBinary("base64", s)
+		if err != nil {
+			return nil, "must be base64-encoded"
+		}
+		return wrapperspb.Bytes(data), ""
+	case boolValueType:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, "must be true or false"
+		}
+		return wrapperspb.Bool(b), ""
+	case int32ValueType:
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return nil, "must be a 32-bit integer"
+		}
+		return wrapperspb.Int32(int32(n)), ""
+	case int64ValueType:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, "must be a 64-bit integer"
+		}
+		return wrapperspb.Int64(n), ""
+	case uint32ValueType:
+		n, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return nil, "must be an unsigned 32-bit integer"
+		}
+		return wrapperspb.UInt32(uint32(n)), ""
+	case uint64ValueType:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, "must be an unsigned 64-bit integer"
+		}
+		return wrapperspb.UInt64(n), ""
+	case floatValueType:
+		f, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return nil, "must be a number"
+		}
+		return wrapperspb.Float(float32(f)), ""
+	case doubleValueType:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, "must be a number"
+		}
+		return wrapperspb.Double(f), ""
+	case timestampType:
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, "must be an RFC 3339 timestamp, e.g. 2024-01-02T15:04:05Z"
+		}
+		return timestamppb.New(t), ""
+	}
+	return nil, "has an unsupported type"
+}
+
+// RegisterProtoValidators lets validate tags check wrapperspb fields through
+// their wrapped value and Timestamp fields as time.Time, so rules such as
+// `validate:"omitempty,min=3"` on a *wrapperspb.StringValue or
+// `validate:"omitnil,gt=0"` on a *wrapperspb.Int32Value apply to the value.
+// Nil fields keep their presence semantics: `required` fails on them.
+func RegisterProtoValidators(v *validator.Validate) {
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if field.Type() == timestampType {
+			return time.Unix(field.FieldByName("Seconds").Int(), field.FieldByName("Nanos").Int()).UTC()
+		}
+		return field.FieldByName("Value").Interface()
+	}, protoWrapperSamples()...)
+}
+
+// protoWrapperSamples returns a value of each type RegisterProtoValidators
+// handles, as RegisterCustomTypeFunc expects.
+func protoWrapperSamples() []interface{} {
+	types := []reflect.Type{stringValueType, bytesValueType, boolValueType, int32ValueType, int64ValueType,
+		uint32ValueType, uint64ValueType, floatValueType, doubleValueType, timestampType}
+	samples := make([]interface{}, len(types))
+	for i, t := range types {
+		samples[i] = reflect.New(t).Elem().Interface()
+	}
+	return samples
+}
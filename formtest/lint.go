@@ -0,0 +1,86 @@
+package formtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jinn091/go-form-parser/formparser"
+)
+
+// exampleContentTypes maps fixture extensions to the Content-Type they are posted with.
+var exampleContentTypes = map[string]string{
+	".json":       "application/json",
+	".form":       "application/x-www-form-urlencoded",
+	".urlencoded": "application/x-www-form-urlencoded",
+}
+
+// LintFailure reports an example payload whose outcome did not match its name.
+type LintFailure struct {
+	File   string            // path of the fixture
+	Err    error             // parse error, nil when an invalid example was accepted
+	Fields map[string]string // field errors from the response, if any
+}
+
+func (f LintFailure) String() string {
+	if f.Err == nil {
+		return f.File + ": expected to be rejected but was accepted"
+	}
+	return fmt.Sprintf("%s: %v %v", f.File, f.Err, f.Fields)
+}
+
+// LintExamples posts every *.json, *.form and *.urlencoded fixture in dir
+// through cfg into a fresh T and reports the ones that fail. Fixtures whose
+// name contains ".invalid." (e.g. missing-email.invalid.json) document
+// rejected payloads and are reported when they are accepted instead.
+func LintExamples[T any](cfg *formparser.Config, dir string) ([]LintFailure, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && exampleContentTypes[filepath.Ext(e.Name())] != "" {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var failures []LintFailure
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if filepath.Ext(name) != ".json" {
+			body = bytes.TrimSpace(body)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", exampleContentTypes[filepath.Ext(name)])
+		w := httptest.NewRecorder()
+
+		dst := new(T)
+		parseErr := cfg.ParseFormBasedOnContentType(w, req, dst)
+		wantInvalid := strings.Contains(name, ".invalid.")
+
+		switch {
+		case parseErr != nil && !wantInvalid:
+			var resp struct {
+				Fields map[string]string `json:"fields"`
+			}
+			_ = json.Unmarshal(w.Body.Bytes(), &resp)
+			failures = append(failures, LintFailure{File: path, Err: parseErr, Fields: resp.Fields})
+		case parseErr == nil && wantInvalid:
+			failures = append(failures, LintFailure{File: path})
+		}
+	}
+	return failures, nil
+}
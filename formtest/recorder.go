@@ -0,0 +1,213 @@
+// Package formtest provides helpers for contract-testing handlers built on formparser.
+package formtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Example is an anonymized request/response pair. Only the shape is kept:
+// field names, file field names and the error fields, never the values.
+type Example struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	ContentType string   `json:"content_type"`
+	Fields      []string `json:"fields"`
+	Files       []string `json:"files,omitempty"`
+	Status      int      `json:"status"`
+	Message     string   `json:"message,omitempty"`
+	ErrorFields []string `json:"error_fields,omitempty"`
+}
+
+// Recorder is middleware that writes an Example golden file to Dir for
+// every request passing through it. One file is kept per method, path,
+// content type and status, so re-running a suite refreshes the goldens.
+type Recorder struct {
+	Dir string
+
+	mu  sync.Mutex
+	err error
+}
+
+// Err returns the first error hit while writing golden files.
+func (rec *Recorder) Err() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.err
+}
+
+// Wrap returns next instrumented to record examples.
+func (rec *Recorder) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		cw := &captureWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(cw, r)
+
+		ex := Example{Method: r.Method, Path: r.URL.Path, Status: cw.status}
+		ex.ContentType, _, _ = mime.ParseMediaType(r.Header.Get("Content-Type"))
+		ex.Fields, ex.Files = requestShape(ex.ContentType, r.Header.Get("Content-Type"), body)
+
+		var resp struct {
+			Message string         `json:"message"`
+			Fields  map[string]any `json:"fields"`
+		}
+		if json.Unmarshal(cw.body.Bytes(), &resp) == nil {
+			ex.Message = resp.Message
+			ex.ErrorFields = sortedKeys(resp.Fields)
+		}
+
+		rec.write(ex)
+	})
+}
+
+// write stores ex as indented JSON in the recorder directory.
+func (rec *Recorder) write(ex Example) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	data, err := json.MarshalIndent(ex, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(rec.Dir, 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(filepath.Join(rec.Dir, exampleName(ex)), append(data, '\n'), 0o644)
+	}
+	if err != nil && rec.err == nil {
+		rec.err = fmt.Errorf("formtest: recording example: %w", err)
+	}
+}
+
+// LoadExamples reads every golden file written by a Recorder in dir.
+func LoadExamples(dir string) ([]Example, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	examples := make([]Example, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var ex Example
+		if err := json.Unmarshal(data, &ex); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		examples = append(examples, ex)
+	}
+	return examples, nil
+}
+
+// Diff describes how got differs from the golden want, or returns nil when
+// both have the same shape.
+func Diff(want, got Example) []string {
+	var diffs []string
+	check := func(name string, w, g any) {
+		if !reflect.DeepEqual(w, g) {
+			diffs = append(diffs, fmt.Sprintf("%s: want %v, got %v", name, w, g))
+		}
+	}
+	check("method", want.Method, got.Method)
+	check("path", want.Path, got.Path)
+	check("content type", want.ContentType, got.ContentType)
+	check("fields", want.Fields, got.Fields)
+	check("files", want.Files, got.Files)
+	check("status", want.Status, got.Status)
+	check("message", want.Message, got.Message)
+	check("error fields", want.ErrorFields, got.ErrorFields)
+	return diffs
+}
+
+// requestShape extracts the sorted field and file names from a request body.
+func requestShape(mediaType, contentType string, body []byte) (fields, files []string) {
+	switch {
+	case mediaType == "application/json":
+		var obj map[string]any
+		_ = json.Unmarshal(body, &obj)
+		return sortedKeys(obj), nil
+	case mediaType == "application/x-www-form-urlencoded":
+		values, _ := url.ParseQuery(string(body))
+		return sortedKeys(values), nil
+	case strings.HasPrefix(mediaType, "multipart/"):
+		req := &http.Request{Header: http.Header{"Content-Type": {contentType}}, Body: io.NopCloser(bytes.NewReader(body))}
+		mr, err := req.MultipartReader()
+		if err != nil {
+			return nil, nil
+		}
+		seen := map[string]bool{}
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			name := part.FormName()
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if part.FileName() != "" {
+				files = append(files, name)
+			} else {
+				fields = append(fields, name)
+			}
+		}
+		sort.Strings(fields)
+		sort.Strings(files)
+		return fields, files
+	}
+	return nil, nil
+}
+
+// exampleName builds a stable file name for ex.
+func exampleName(ex Example) string {
+	name := fmt.Sprintf("%s_%s_%s_%d", ex.Method, ex.Path, ex.ContentType, ex.Status)
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, name) + ".json"
+}
+
+// sortedKeys returns the keys of a string-keyed map in sorted order.
+func sortedKeys[M ~map[string]V, V any](m M) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// captureWriter records the status and body written by a handler.
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (cw *captureWriter) WriteHeader(status int) {
+	cw.status = status
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *captureWriter) Write(p []byte) (int, error) {
+	cw.body.Write(p)
+	return cw.ResponseWriter.Write(p)
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type ShippingForm struct {
+	Street  string `json:"street" address:"shipping,street"`
+	City    string `json:"city" address:"shipping,city" validate:"required"`
+	Zip     string `json:"zip" address:"shipping,postal_code"`
+	BillZip string `json:"bill_zip" address:"billing,postal_code"`
+}
+
+type geoPoint struct{ Lat, Lng float64 }
+
+func postShipping(t *testing.T, cfg *formparser.Config, payload string) (*httptest.ResponseRecorder, ShippingForm, error) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	var form ShippingForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	return w, form, err
+}
+
+func TestAddressNormalizer(t *testing.T) {
+	var groups []string
+	cfg := setupParser()
+	cfg.AddressNormalizer = formparser.AddressNormalizerFunc(func(ctx context.Context, group string, a formparser.Address) (formparser.NormalizedAddress, error) {
+		groups = append(groups, group)
+		return formparser.NormalizedAddress{
+			Address: formparser.Address{"street": strings.ToUpper(a["street"]), "city": "BERLIN"},
+			Extra:   geoPoint{Lat: 52.52, Lng: 13.40},
+		}, nil
+	})
+
+	_, form, err := postShipping(t, cfg, `{"street":"Unter den Linden 1","city":"berlin","zip":"10117"}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"shipping"}, groups) // billing has no parts set
+	assert.Equal(t, ShippingForm{Street: "UNTER DEN LINDEN 1", City: "BERLIN", Zip: "10117"}, form)
+	assert.Equal(t, map[string]any{"shipping": geoPoint{Lat: 52.52, Lng: 13.40}}, cfg.Result.Extras)
+}
+
+func TestAddressNormalizerErrors(t *testing.T) {
+	cfg := setupParser()
+	cfg.AddressNormalizer = formparser.AddressNormalizerFunc(func(ctx context.Context, group string, a formparser.Address) (formparser.NormalizedAddress, error) {
+		return formparser.NormalizedAddress{}, formparser.FieldErrors{"zip": "zip does not exist in " + a["city"]}
+	})
+
+	w, _, err := postShipping(t, cfg, `{"city":"Berlin","zip":"99999"}`)
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "zip does not exist in Berlin")
+
+	cfg.AddressNormalizer = formparser.AddressNormalizerFunc(func(ctx context.Context, group string, a formparser.Address) (formparser.NormalizedAddress, error) {
+		return formparser.NormalizedAddress{}, errors.New("geo service down")
+	})
+	w, _, err = postShipping(t, cfg, `{"city":"Berlin"}`)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+}
@@ -0,0 +1,44 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedMIMETypes(t *testing.T) {
+	tests := []struct {
+		allowed     []string
+		contentType string
+		ok          bool
+	}{
+		{[]string{"image/png"}, "image/png", true},
+		{[]string{"image/png"}, "IMAGE/PNG; name=a.png", true},
+		{[]string{"image/png"}, "image/gif", false},
+		{[]string{"image/*"}, "image/gif", true},
+		{[]string{"image/*"}, "application/pdf", false},
+		{[]string{formparser.AllowAllMIMETypes}, "application/zip", true},
+		{nil, "image/png", false},
+	}
+	for _, tt := range tests {
+		cfg := setupParser()
+		cfg.AllowedMIMETypes = tt.allowed
+		req := newMultipartRequest(t, map[string]string{"name": "Ann", "email": "ann@example.com"},
+			testFile{Field: "upload", Filename: "upload.bin", ContentType: tt.contentType, Content: []byte("data")})
+		rec := httptest.NewRecorder()
+		err := cfg.ParseFormBasedOnContentType(rec, req, &TestForm{})
+		if tt.ok {
+			assert.NoError(t, err, "%v %s", tt.allowed, tt.contentType)
+		} else {
+			assert.Equal(t, http.StatusBadRequest, rec.Code, "%v %s", tt.allowed, tt.contentType)
+		}
+	}
+}
+
+func TestMustParseSize(t *testing.T) {
+	assert.Equal(t, int64(10<<20), formparser.MustParseSize("10MB"))
+	assert.Panics(t, func() { formparser.MustParseSize("ten megs") })
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAsync(t *testing.T) {
+	cfg := setupParser()
+	cfg.AsyncWorkers = 4
+	cfg.AsyncQueueSize = 16
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+				testFile{Field: "avatar", Filename: "avatar.png", ContentType: "image/png", Content: []byte("PNG")})
+			var form TestForm
+			job, err := cfg.ParseAsync(httptest.NewRecorder(), req, &form)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			res, err := job.Wait()
+			assert.NoError(t, err)
+			assert.Equal(t, "Alice", form.Name)
+			assert.Equal(t, []byte("PNG"), res.Files["avatar"].Content)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestParseAsyncQueueFull(t *testing.T) {
+	cfg := setupParser()
+	cfg.AsyncWorkers = 1
+	cfg.AsyncQueueSize = 1
+
+	// Block the only worker inside the computer hook, then fill the queue.
+	release := make(chan struct{})
+	started := make(chan struct{})
+	formparser.RegisterComputer(cfg, func(f *TestForm) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	newReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","email":"john@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	first, err := cfg.ParseAsync(httptest.NewRecorder(), newReq(), &TestForm{})
+	assert.NoError(t, err)
+	<-started
+	second, err := cfg.ParseAsync(httptest.NewRecorder(), newReq(), &TestForm{})
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	_, err = cfg.ParseAsync(w, newReq(), &TestForm{})
+	assert.ErrorIs(t, err, formparser.ErrParseQueueFull)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Result().StatusCode)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	close(release)
+	go func() { <-started }()
+	_, err = first.Wait()
+	assert.NoError(t, err)
+	_, err = second.Wait()
+	assert.NoError(t, err)
+}
+
+func TestCloseDrainsAsyncJobs(t *testing.T) {
+	cfg := setupParser()
+	cfg.AsyncWorkers = 1
+	cfg.AsyncQueueSize = 4
+
+	release := make(chan struct{})
+	formparser.RegisterComputer(cfg, func(f *TestForm) error {
+		<-release
+		return nil
+	})
+	newReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","email":"john@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	var jobs []*formparser.ParseJob
+	for i := 0; i < 3; i++ {
+		job, err := cfg.ParseAsync(httptest.NewRecorder(), newReq(), &TestForm{})
+		assert.NoError(t, err)
+		jobs = append(jobs, job)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, cfg.Close(ctx), context.DeadlineExceeded)
+
+	w := httptest.NewRecorder()
+	_, err := cfg.ParseAsync(w, newReq(), &TestForm{})
+	assert.ErrorIs(t, err, formparser.ErrClosed)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	close(release)
+	assert.NoError(t, cfg.Close(context.Background()))
+	for _, job := range jobs {
+		select {
+		case <-job.Done():
+			_, err := job.Wait()
+			assert.NoError(t, err)
+		default:
+			t.Fatal("job not finished after Close")
+		}
+	}
+}
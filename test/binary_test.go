@@ -0,0 +1,59 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type KeyForm struct {
+	Signature []byte  `form:"signature" encoding:"base64" validate:"required,len=4" unit:"bytes"`
+	Token     []byte  `form:"token" encoding:"base64url"`
+	Key       [4]byte `form:"key" encoding:"hex"`
+}
+
+func postKeyForm(t *testing.T, values url.Values) (*httptest.ResponseRecorder, KeyForm, error) {
+	t.Helper()
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	var form KeyForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	return w, form, err
+}
+
+func TestBinaryEncodings(t *testing.T) {
+	_, form, err := postKeyForm(t, url.Values{
+		"signature": {"3q2+7w=="},
+		"token":     {"3q2-7w"},
+		"key":       {"DEADBEEF"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, form.Signature)
+	assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, form.Token)
+	assert.Equal(t, [4]byte{0xde, 0xad, 0xbe, 0xef}, form.Key)
+}
+
+func TestBinaryEncodingErrors(t *testing.T) {
+	w, _, err := postKeyForm(t, url.Values{
+		"signature": {"3q2+"},
+		"token":     {"not base64!"},
+		"key":       {"DEAD"},
+	})
+	assert.Error(t, err)
+
+	var resp validationResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]string{
+		"signature": "signature must be exactly 4 bytes",
+		"token":     "token must be base64url-encoded",
+		"key":       "key must be 4 bytes",
+	}, resp.Fields)
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyStore fails while down is set and counts the calls that reach it.
+type flakyStore struct {
+	down  bool
+	calls int
+}
+
+func (s *flakyStore) Store(ctx context.Context, key string, file *formparser.UploadedFile) error {
+	s.calls++
+	if s.down {
+		return errors.New("storage unavailable")
+	}
+	return nil
+}
+
+func uploadAvatar(t *testing.T, cfg *formparser.Config) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png", Content: []byte("\x89PNG\r\n\x1a\n")})
+	w := httptest.NewRecorder()
+	var form TestForm
+	return w, cfg.ParseFormBasedOnContentType(w, req, &form)
+}
+
+func TestCircuitBreakerFailClosed(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &flakyStore{down: true}
+	breaker := &formparser.CircuitBreaker{Threshold: 2, Cooldown: time.Minute}
+	cfg := setupParser()
+	cfg.Clock = func() time.Time { return now }
+	cfg.FileStore = store
+	cfg.Breakers = map[string]*formparser.CircuitBreaker{"FileStore": breaker}
+
+	for i := 0; i < 2; i++ {
+		_, err := uploadAvatar(t, cfg)
+		assert.EqualError(t, err, "storage unavailable")
+	}
+	assert.True(t, breaker.Open())
+
+	w, err := uploadAvatar(t, cfg)
+	assert.ErrorIs(t, err, formparser.ErrCircuitOpen)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, 2, store.calls, "open circuit must not call the store")
+
+	// After the cooldown one trial call goes through and closes the circuit.
+	now = now.Add(time.Minute)
+	store.down = false
+	_, err = uploadAvatar(t, cfg)
+	assert.NoError(t, err)
+	assert.False(t, breaker.Open())
+	assert.Equal(t, 3, store.calls)
+}
+
+func TestCircuitBreakerFailOpen(t *testing.T) {
+	store := &flakyStore{down: true}
+	cfg := setupParser()
+	cfg.FileStore = store
+	cfg.Breakers = map[string]*formparser.CircuitBreaker{
+		"FileStore": {Threshold: 1, Policy: formparser.FailOpen},
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := uploadAvatar(t, cfg)
+		assert.NoError(t, err)
+		assert.Empty(t, cfg.Files["avatar"].StorageKey)
+		assert.NotEmpty(t, cfg.Files["avatar"].Content)
+	}
+	assert.Equal(t, 1, store.calls)
+}
+
+// slowBreachChecker blocks until its ctx is done.
+type slowBreachChecker struct{}
+
+func (slowBreachChecker) BreachedSuffixes(ctx context.Context, prefix string) (map[string]bool, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestCircuitBreakerTimeout(t *testing.T) {
+	cfg := setupPasswordParser()
+	cfg.BreachChecker = slowBreachChecker{}
+	cfg.Breakers = map[string]*formparser.CircuitBreaker{
+		"BreachChecker": {Timeout: 20 * time.Millisecond, Policy: formparser.FailOpen},
+	}
+
+	start := time.Now()
+	_, err := postSignup(t, cfg, "jdoe", "c0rrect-H0rse-battery")
+
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,42 @@
+package test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyBufferSize(t *testing.T) {
+	cfg := setupParser()
+	cfg.CopyBufferSize = 7
+
+	content := bytes.Repeat([]byte("x"), 1000)
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "avatar", Filename: "avatar.png", ContentType: "image/png", Content: content})
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.NoError(t, err)
+	assert.Equal(t, content, cfg.Files["avatar"].Content)
+}
+
+func TestCopyBufferSizeFileTooLarge(t *testing.T) {
+	cfg := setupParser()
+	cfg.CopyBufferSize = 64
+	cfg.MaxFileSize = 100
+
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "avatar", Filename: "avatar.png", ContentType: "image/png", Content: bytes.Repeat([]byte("x"), 101)})
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Result().StatusCode)
+}
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func newJSONRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestJSONArrayBody(t *testing.T) {
+	var forms []TestForm
+	err := setupParser().ParseFormBasedOnContentType(httptest.NewRecorder(),
+		newJSONRequest(`[{"name":"Ann","email":"ann@example.com"},{"name":"Bo","email":"bo@example.com"}]`), &forms)
+	assert.NoError(t, err)
+	assert.Equal(t, []TestForm{{Name: "Ann", Email: "ann@example.com"}, {Name: "Bo", Email: "bo@example.com"}}, forms)
+}
+
+func TestJSONArrayBodyIndexedErrors(t *testing.T) {
+	var forms []*TestForm
+	rec := httptest.NewRecorder()
+	err := setupParser().ParseFormBasedOnContentType(rec,
+		newJSONRequest(`[{"name":"Ann","email":"ann@example.com"},{"name":"Bo","email":"nope"},null,{"email":"cy@example.com"}]`), &forms)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp validationResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]string{
+		"items[1].email": "Invalid email address",
+		"items[2]":       "Item is required",
+		"items[3].name":  "Name is required",
+	}, resp.Fields)
+}
+
+func TestJSONArrayBodyV2Paths(t *testing.T) {
+	var forms []TestForm
+	req := newJSONRequest(`[{"name":"Ann","email":"bad"}]`)
+	req.Header.Set(formparser.ErrorFormatHeader, "v2")
+	rec := httptest.NewRecorder()
+	assert.Error(t, setupParser().ParseFormBasedOnContentType(rec, req, &forms))
+	assert.Contains(t, rec.Body.String(), `"path":"items[0].email"`)
+	assert.Contains(t, rec.Body.String(), `"code":"email"`)
+}
+
+func TestJSONArrayBodyNotArray(t *testing.T) {
+	var forms []TestForm
+	rec := httptest.NewRecorder()
+	assert.Error(t, setupParser().ParseFormBasedOnContentType(rec, newJSONRequest(`{"name":"Ann"}`), &forms))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
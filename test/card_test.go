@@ -0,0 +1,102 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type PaymentForm struct {
+	Card      string `json:"card" form:"card" cc:"pan,brand=CardBrand,bin=CardBIN,last4=CardLast4" validate:"required"`
+	CardBrand string `json:"card_brand" form:"-"`
+	CardBIN   string `json:"card_bin" form:"-"`
+	CardLast4 string `json:"card_last4" form:"-"`
+}
+
+func postPayment(cfg *formparser.Config, card string) (*httptest.ResponseRecorder, PaymentForm, error) {
+	payload, _ := json.Marshal(map[string]string{"card": card})
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	var form PaymentForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	return w, form, err
+}
+
+func TestCardField(t *testing.T) {
+	_, form, err := postPayment(setupParser(), "4111 1111-1111 1111")
+
+	assert.NoError(t, err)
+	assert.Equal(t, PaymentForm{Card: "4111111111111111", CardBrand: "visa", CardBIN: "411111", CardLast4: "1111"}, form)
+	assert.Equal(t, "************1111", formparser.Echo(&form)[0].Value)
+}
+
+func TestCardFieldInvalid(t *testing.T) {
+	w, _, err := postPayment(setupParser(), "4111 1111 1111 1112")
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "card is not a valid card number")
+	assert.NotContains(t, w.Body.String(), "1112")
+}
+
+func TestCardBrand(t *testing.T) {
+	cases := map[string]string{
+		"4111111111111111": "visa",
+		"5555555555554444": "mastercard",
+		"2223003122003222": "mastercard",
+		"378282246310005":  "amex",
+		"6011111111111117": "discover",
+		"3530111333300000": "jcb",
+		"30569309025904":   "diners",
+		"6200000000000005": "unionpay",
+		"1234567812345670": "",
+	}
+	for pan, brand := range cases {
+		assert.Equal(t, brand, formparser.CardBrand(pan), pan)
+	}
+}
+
+func TestCardNumberNeverInErrors(t *testing.T) {
+	cfg := setupParser()
+	formparser.RegisterComputer(cfg, func(form *PaymentForm) error {
+		return formparser.FieldErrors{"card": "card 5555 5555 5555 4444 was declined"}
+	})
+
+	w, _, err := postPayment(cfg, "5555555555554444")
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "card ************4444 was declined")
+	assert.NotContains(t, err.Error(), "5555 5555 5555 4444")
+}
+
+func TestCardNumberNeverInLogs(t *testing.T) {
+	cfg := setupParser()
+	cfg.URLEncoding = formparser.URLEncodingLenient
+	var logs bytes.Buffer
+	cfg.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	body := "card=4111111111111111%zz"
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var form PaymentForm
+	_ = cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form)
+
+	assert.Contains(t, logs.String(), "key=card")
+	assert.NotContains(t, logs.String(), "1111")
+
+	cfg.URLEncoding = formparser.URLEncodingStrict
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form)
+	var encErr *formparser.URLEncodingError
+	assert.True(t, errors.As(err, &encErr))
+	assert.NotContains(t, err.Error(), "4111111111111111")
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type SensorReading struct {
+	DeviceID    string  `cbor:"id" json:"device_id" validate:"required"`
+	Temperature float64 `json:"temperature" validate:"gte=-50,lte=100"`
+}
+
+func cborRequest(t *testing.T, payload any) *http.Request {
+	t.Helper()
+	body, err := cbor.Marshal(payload)
+	assert.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/cbor")
+	return req
+}
+
+func TestParseCBOR(t *testing.T) {
+	cfg := setupParser()
+	req := cborRequest(t, map[string]any{"id": "sensor-1", "temperature": 21.5})
+
+	var reading SensorReading
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &reading)
+
+	assert.NoError(t, err)
+	assert.Equal(t, SensorReading{DeviceID: "sensor-1", Temperature: 21.5}, reading)
+}
+
+func TestParseCBORValidation(t *testing.T) {
+	cfg := setupParser()
+	req := cborRequest(t, map[string]any{"temperature": 150.0})
+	w := httptest.NewRecorder()
+
+	var reading SensorReading
+	err := cfg.ParseFormBasedOnContentType(w, req, &reading)
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "deviceid is required")
+	assert.Contains(t, w.Body.String(), "temperature must be at most 100")
+}
+
+func TestParseCBORInvalid(t *testing.T) {
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte{0xff}))
+	req.Header.Set("Content-Type", "application/cbor")
+	w := httptest.NewRecorder()
+
+	var reading SensorReading
+	err := cfg.ParseFormBasedOnContentType(w, req, &reading)
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "Invalid CBOR body")
+}
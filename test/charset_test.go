@@ -0,0 +1,66 @@
+package test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLEncodedCharset(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"iso-8859-1", "application/x-www-form-urlencoded; charset=ISO-8859-1", "name=Jos%E9&email=jose@example.com", "José"},
+		{"windows-1252", "application/x-www-form-urlencoded; charset=windows-1252", "name=%80uro&email=jose@example.com", "€uro"},
+		{"shift_jis", "application/x-www-form-urlencoded; charset=Shift_JIS", "name=%93%FA%96%7B&email=jose@example.com", "日本"},
+		{"_charset_ field", "application/x-www-form-urlencoded", "_charset_=ISO-8859-1&name=Jos%E9&email=jose@example.com", "José"},
+		{"utf-8", "application/x-www-form-urlencoded; charset=UTF-8", "name=Jos%C3%A9&email=jose@example.com", "José"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			var form TestForm
+			assert.NoError(t, setupParser().ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+			assert.Equal(t, tt.want, form.Name)
+		})
+	}
+}
+
+func TestURLEncodedUnsupportedCharset(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=x&email=jose@example.com"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=klingon")
+	rec := httptest.NewRecorder()
+	assert.Error(t, setupParser().ParseFormBasedOnContentType(rec, req, &TestForm{}))
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
+
+func TestMultipartCharset(t *testing.T) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	assert.NoError(t, writer.WriteField("_charset_", "windows-1252"))
+	assert.NoError(t, writer.WriteField("name", "Jos\xe9"))
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="email"`)
+	header.Set("Content-Type", "text/plain; charset=UTF-8")
+	part, err := writer.CreatePart(header)
+	assert.NoError(t, err)
+	_, _ = part.Write([]byte("jose@example.com"))
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	var form TestForm
+	assert.NoError(t, setupParser().ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+	assert.Equal(t, "José", form.Name)
+	assert.Equal(t, "jose@example.com", form.Email)
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockAndRandomSeams(t *testing.T) {
+	fixed := time.Date(2024, 2, 29, 23, 59, 0, 0, time.UTC)
+	clock := func() time.Time { return fixed }
+	file := &formparser.UploadedFile{Filename: "a.png", Hash: "abc"}
+
+	assert.Equal(t, "avatar/2024/02/29/abc.png", formparser.NewDateKey(clock)("avatar", file))
+
+	ulid := formparser.NewULIDKey(clock, bytes.NewReader(make([]byte, 10)))
+	assert.Equal(t, "avatar/01HQVMX6D00000000000000000.png", ulid("avatar", file))
+
+	cfg := setupParser()
+	assert.NotContains(t, cfg.Effective().Hooks, "Clock")
+	cfg.Clock = clock
+	cfg.Random = bytes.NewReader(make([]byte, 10))
+	assert.Subset(t, cfg.Effective().Hooks, []string{"Clock", "Random"})
+}
+
+func TestUploadTokenFakeClock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := setupTokenParser()
+	cfg.Clock = func() time.Time { return now }
+
+	content := []byte("PNG")
+	token, err := cfg.SignUploadToken(formparser.UploadGrant{
+		Field: "avatar", Filename: "a.png", Size: int64(len(content)), ContentType: "image/png",
+		Expires: now.Add(time.Minute),
+	})
+	assert.NoError(t, err)
+
+	_, err = cfg.VerifyUploadToken(token)
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = cfg.VerifyUploadToken(token)
+	assert.ErrorIs(t, err, formparser.ErrExpiredUploadToken)
+
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com", "avatar_token": token},
+		testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png", Content: content})
+	var form TestForm
+	assert.Error(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+}
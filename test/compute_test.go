@@ -0,0 +1,55 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type ArticleForm struct {
+	Title string `json:"title" validate:"required"`
+	Slug  string `json:"-" validate:"required,max=12"`
+}
+
+func postArticle(cfg *formparser.Config, title string) (*httptest.ResponseRecorder, ArticleForm, error) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"title":"`+title+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	var form ArticleForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	return w, form, err
+}
+
+func TestComputer(t *testing.T) {
+	cfg := setupParser()
+	formparser.RegisterComputer(cfg, func(f *ArticleForm) error {
+		f.Slug = strings.ReplaceAll(strings.ToLower(f.Title), " ", "-")
+		return nil
+	})
+
+	_, form, err := postArticle(cfg, "Hello World")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello-world", form.Slug)
+
+	// Validation sees the computed value.
+	w, _, err := postArticle(cfg, "A Much Longer Title")
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), `"slug"`)
+}
+
+func TestComputerFieldErrors(t *testing.T) {
+	cfg := setupParser()
+	formparser.RegisterComputer(cfg, func(f *ArticleForm) error {
+		return formparser.FieldErrors{"title": "title cannot be turned into a slug"}
+	})
+
+	w, _, err := postArticle(cfg, "???")
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	assert.Contains(t, w.Body.String(), "title cannot be turned into a slug")
+}
@@ -0,0 +1,42 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type PasswordForm struct {
+	Password             string `form:"password" validate:"required,min=8"`
+	PasswordConfirmation string `form:"password_confirmation" confirm:"password"`
+}
+
+func postPassword(password, confirmation string) (*httptest.ResponseRecorder, PasswordForm, error) {
+	cfg := setupParser()
+	data := url.Values{"password": {password}, "password_confirmation": {confirmation}}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	var form PasswordForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	return w, form, err
+}
+
+func TestConfirmStripped(t *testing.T) {
+	_, form, err := postPassword("s3cret-pass", "s3cret-pass")
+
+	assert.NoError(t, err)
+	assert.Equal(t, PasswordForm{Password: "s3cret-pass"}, form)
+}
+
+func TestConfirmMismatch(t *testing.T) {
+	w, _, err := postPassword("s3cret-pass", "other-pass")
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "passwordconfirmation does not match password")
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConverter(t *testing.T) {
+	cfg := setupParser()
+	cfg.AllowedMIMETypes = []string{"image/heic"}
+	cfg.Converters = map[string]formparser.Converter{
+		"image/heic": formparser.ConverterFunc(func(ctx context.Context, file *formparser.UploadedFile) (*formparser.UploadedFile, error) {
+			return &formparser.UploadedFile{Filename: "photo.jpg", ContentType: "image/jpeg", Content: []byte("JPEG")}, nil
+		}),
+	}
+
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "photo", Filename: "photo.heic", ContentType: "image/heic", Content: []byte("HEIC")})
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.NoError(t, err)
+	file := cfg.Files["photo"]
+	assert.Equal(t, "image/jpeg", file.ContentType)
+	assert.NotEmpty(t, file.Hash)
+	assert.Equal(t, "photo.heic", file.Original.Filename)
+	assert.Equal(t, []byte("HEIC"), file.Original.Content)
+}
+
+func TestConverterFailure(t *testing.T) {
+	cfg := setupParser()
+	cfg.AllowedMIMETypes = []string{"image/heic"}
+	cfg.Converters = map[string]formparser.Converter{
+		"image/heic": formparser.ConverterFunc(func(ctx context.Context, file *formparser.UploadedFile) (*formparser.UploadedFile, error) {
+			return nil, errors.New("decoder unavailable")
+		}),
+	}
+
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "photo", Filename: "photo.heic", ContentType: "image/heic", Content: []byte("HEIC")})
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.IsType(t, formparser.FieldErrors{}, err)
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type ImportRow struct {
+	SKU      string `csv:"sku" form:"sku" validate:"required"`
+	Quantity int    `csv:"qty" form:"quantity" validate:"gte=0"`
+	Note     string
+}
+
+func csvRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	return req
+}
+
+func TestParseCSV(t *testing.T) {
+	cfg := setupParser()
+	body := "\uFEFFsku,qty,note,ignored\nA-1,3,first,x\nB-2,0,,y\n"
+
+	var rows []ImportRow
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), csvRequest(body), &rows)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ImportRow{{SKU: "A-1", Quantity: 3, Note: "first"}, {SKU: "B-2"}}, rows)
+}
+
+func TestParseCSVPointerRows(t *testing.T) {
+	cfg := setupParser()
+
+	var rows []*ImportRow
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), csvRequest("qty,sku\n7,C-3\n"), &rows)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*ImportRow{{SKU: "C-3", Quantity: 7}}, rows)
+}
+
+func TestParseCSVRowErrors(t *testing.T) {
+	cfg := setupParser()
+	body := "sku,qty\nA-1,3\n,2\nB-2,-1,extra\nC-3,-4\n"
+	w := httptest.NewRecorder()
+
+	var rows []ImportRow
+	err := cfg.ParseFormBasedOnContentType(w, csvRequest(body), &rows)
+
+	var recordErrs formparser.RecordErrors
+	assert.True(t, errors.As(err, &recordErrs))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp struct {
+		Records formparser.RecordErrors `json:"records"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, formparser.RecordErrors{
+		{Line: 3, Fields: formparser.FieldErrors{"sku": "sku is required"}},
+		{Line: 4, Message: "CSV row has the wrong number of fields"},
+		{Line: 5, Fields: formparser.FieldErrors{"quantity": "quantity must be at least 0"}},
+	}, resp.Records)
+	assert.Len(t, rows, 3)
+}
+
+func TestParseCSVRequiresSlice(t *testing.T) {
+	cfg := setupParser()
+	w := httptest.NewRecorder()
+
+	var row ImportRow
+	err := cfg.ParseFormBasedOnContentType(w, csvRequest("sku\nA-1\n"), &row)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
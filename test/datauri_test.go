@@ -0,0 +1,66 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type ProfilePhotoForm struct {
+	Name   string                   `json:"name" validate:"required"`
+	Avatar *formparser.UploadedFile `json:"avatar" validate:"required"`
+}
+
+func TestJSONDataURI(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	body := `{"name":"Ann","avatar":"data:image/png;name=me.png;base64,` + base64.StdEncoding.EncodeToString(png) + `"}`
+	cfg := setupParser()
+	var form ProfilePhotoForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), newJSONRequest(body), &form))
+
+	assert.Equal(t, "Ann", form.Name)
+	assert.Equal(t, png, form.Avatar.Content)
+	assert.Equal(t, "image/png", form.Avatar.ContentType)
+	assert.Equal(t, "me.png", form.Avatar.Filename)
+	assert.Equal(t, int64(len(png)), form.Avatar.Size)
+	assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(png)), form.Avatar.Hash)
+	assert.Same(t, form.Avatar, cfg.Files["avatar"])
+}
+
+func TestJSONDataURIRejected(t *testing.T) {
+	tests := map[string]struct {
+		avatar string
+		status int
+	}{
+		"disallowed type": {`"data:application/zip;base64,UEsDBA=="`, http.StatusBadRequest},
+		"too large":       {`"data:image/png;base64,` + base64.StdEncoding.EncodeToString(make([]byte, 2048)) + `"`, http.StatusRequestEntityTooLarge},
+		"not a data URI":  {`"https://example.com/me.png"`, http.StatusBadRequest},
+		"bad base64":      {`"data:image/png;base64,@@@"`, http.StatusBadRequest},
+		"object":          {`{"Content":"UE5H","Hash":"forged"}`, http.StatusBadRequest},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := setupParser()
+			cfg.MaxFileSize = 1024
+			var form ProfilePhotoForm
+			rec := httptest.NewRecorder()
+			err := cfg.ParseFormBasedOnContentType(rec, newJSONRequest(`{"name":"Ann","avatar":`+tt.avatar+`}`), &form)
+			assert.Error(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Nil(t, form.Avatar)
+		})
+	}
+}
+
+func TestJSONDataURIMissing(t *testing.T) {
+	var form ProfilePhotoForm
+	rec := httptest.NewRecorder()
+	assert.Error(t, setupParser().ParseFormBasedOnContentType(rec, newJSONRequest(`{"name":"Ann","avatar":null}`), &form))
+	assert.Contains(t, rec.Body.String(), `"avatar"`)
+}
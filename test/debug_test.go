@@ -0,0 +1,54 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type SlugForm struct {
+	Slug string `json:"slug" validate:"slug"`
+}
+
+func TestDebugHandler(t *testing.T) {
+	cfg := setupParser()
+	cfg.JSONAPI = true
+	assert.NoError(t, cfg.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return !strings.ContainsAny(fl.Field().String(), " /")
+	}))
+	cfg.RegisterStructValidation(func(sl validator.StructLevel) {}, SlugForm{})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"slug":"not a slug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	assert.Error(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &SlugForm{}))
+
+	w := httptest.NewRecorder()
+	cfg.DebugHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/formparser", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var info formparser.DebugInfo
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+	assert.Equal(t, int64(1<<20), info.Config.MaxFileSize)
+	assert.Contains(t, info.ContentTypes, "multipart/form-data")
+	assert.Contains(t, info.ContentTypes, "application/vnd.api+json")
+	assert.Equal(t, []string{"slug"}, info.Validators)
+	assert.Equal(t, []string{"test.SlugForm"}, info.StructValidators)
+	assert.Equal(t, int64(1), info.Stats.Parses["json"])
+	assert.Equal(t, int64(1), info.Stats.Failures["validation"])
+}
+
+func TestDebugHandlerMethod(t *testing.T) {
+	cfg := setupParser()
+	w := httptest.NewRecorder()
+	cfg.DebugHandler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/debug/formparser", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, HEAD", w.Header().Get("Allow"))
+}
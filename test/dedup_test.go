@@ -0,0 +1,59 @@
+package test
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryDedup struct {
+	refs map[string]formparser.FileRef
+}
+
+func (d *memoryDedup) Lookup(ctx context.Context, hash string) (formparser.FileRef, bool, error) {
+	ref, ok := d.refs[hash]
+	return ref, ok, nil
+}
+
+func (d *memoryDedup) Save(ctx context.Context, ref formparser.FileRef) error {
+	d.refs[ref.Hash] = ref
+	return nil
+}
+
+func TestDedupStore(t *testing.T) {
+	content := []byte("PNG IMAGE CONTENT")
+	hash := fmt.Sprintf("%x", sha256.Sum256(content))
+	dedup := &memoryDedup{refs: map[string]formparser.FileRef{}}
+	store := &memoryStore{files: map[string]*formparser.UploadedFile{}}
+
+	upload := func(declared string) (*formparser.Config, error) {
+		cfg := setupParser()
+		cfg.DedupStore = dedup
+		cfg.FileStore = store
+		req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+			testFile{Field: "avatar", Filename: "avatar.png", ContentType: "image/png", Content: content, Header: map[string]string{"X-Content-SHA256": declared}})
+		var form TestForm
+		return cfg, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form)
+	}
+
+	cfg, err := upload(hash)
+	assert.NoError(t, err)
+	assert.False(t, cfg.Files["avatar"].Existing)
+	assert.Len(t, store.files, 1)
+
+	cfg, err = upload(hash)
+	assert.NoError(t, err)
+	file := cfg.Files["avatar"]
+	assert.True(t, file.Existing)
+	assert.Nil(t, file.Content)
+	assert.Equal(t, int64(len(content)), file.Ref().Size)
+	assert.Len(t, store.files, 1)
+
+	_, err = upload("deadbeef")
+	assert.IsType(t, formparser.FieldErrors{}, err)
+}
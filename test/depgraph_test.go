@@ -0,0 +1,45 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type DeliveryForm struct {
+	Country     string `json:"country" validate:"required"`
+	State       string `json:"state" validate:"required_if=Country US IsGift true,excluded_with=Province"`
+	Province    string `json:"province"`
+	Password    string `json:"password"`
+	Confirm     string `json:"confirm" validate:"eqfield=Password"`
+	GiftMessage string `json:"gift_message" validate:"required_with=IsGift"`
+	IsGift      bool   `json:"is_gift"`
+	Billing     struct {
+		SameAsShipping bool   `json:"same_as_shipping"`
+		Street         string `json:"street" validate:"required_unless=SameAsShipping true"`
+	} `json:"billing"`
+	Tags []string `json:"tags" validate:"dive,required_with=Country"`
+}
+
+func TestDependencies(t *testing.T) {
+	g := formparser.Dependencies(&DeliveryForm{})
+	assert.Contains(t, g.Fields, "billing.street")
+	assert.Equal(t, []formparser.FieldDependency{
+		{Field: "state", On: "country", Rule: "required_if", Value: "US", Effect: formparser.EffectRequire},
+		{Field: "state", On: "is_gift", Rule: "required_if", Value: "true", Effect: formparser.EffectRequire},
+		{Field: "state", On: "province", Rule: "excluded_with", Effect: formparser.EffectExclude},
+		{Field: "confirm", On: "password", Rule: "eqfield", Effect: formparser.EffectCompare},
+		{Field: "gift_message", On: "is_gift", Rule: "required_with", Effect: formparser.EffectRequire},
+		{Field: "billing.street", On: "billing.same_as_shipping", Rule: "required_unless", Value: "true", Effect: formparser.EffectRequire},
+	}, g.Dependencies)
+
+	assert.Equal(t, []formparser.FieldDependency{g.Dependencies[0]}, g.Dependents("country"))
+	assert.Empty(t, g.Dependents("tags"))
+}
+
+func TestDependenciesNotStruct(t *testing.T) {
+	g := formparser.Dependencies("x")
+	assert.Empty(t, g.Fields)
+	assert.Empty(t, g.Dependencies)
+}
@@ -0,0 +1,101 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type Category struct {
+	Name     string      `json:"name" form:"name"`
+	Children []*Category `json:"children" form:"children"`
+}
+
+func nestedCategoryJSON(depth int) string {
+	return strings.Repeat(`{"name":"c","children":[`, depth) + strings.Repeat("]}", depth)
+}
+
+func TestMaxDecodeDepthJSON(t *testing.T) {
+	cfg := setupParser()
+	cfg.MaxDecodeDepth = 10
+
+	parse := func(payload string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		var c Category
+		return w, cfg.ParseFormBasedOnContentType(w, req, &c)
+	}
+
+	_, err := parse(nestedCategoryJSON(5))
+	assert.NoError(t, err)
+
+	w, err := parse(nestedCategoryJSON(1000))
+	var depthErr *formparser.MaxDepthError
+	assert.ErrorAs(t, err, &depthErr)
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+
+	// Brackets inside strings do not count towards depth.
+	_, err = parse(`{"name":"[[[[[[[[[[[[{{{{{{{{\"{{{{{"}`)
+	assert.NoError(t, err)
+}
+
+func TestMaxDecodeDepthForm(t *testing.T) {
+	cfg := setupParser()
+	cfg.MaxDecodeDepth = 4
+
+	key := strings.Repeat("children[0].", 3) + "name"
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(url.Values{key: {"deep"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var c Category
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &c)
+
+	var depthErr *formparser.MaxDepthError
+	assert.ErrorAs(t, err, &depthErr)
+	assert.Equal(t, key, depthErr.Key)
+}
+
+type Scores struct {
+	Name   string    `json:"name"`
+	Values []float64 `json:"values"`
+}
+
+func TestMaxJSONTokens(t *testing.T) {
+	cfg := setupParser()
+	cfg.DecodeOnly = true
+	cfg.MaxJSONTokens = 12
+
+	parse := func(payload string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		var s Scores
+		return w, cfg.ParseFormBasedOnContentType(w, req, &s)
+	}
+
+	// { "name" "a" "values" [ -2.5e3 10 ] } is 9 tokens.
+	_, err := parse(`{"name": "a", "values": [-2.5e3, 10]}`)
+	assert.NoError(t, err)
+
+	cfg.MaxJSONTokens = 8
+	_, err = parse(`{"name": "a", "values": [-2.5e3, 10]}`)
+	assert.Error(t, err)
+	cfg.MaxJSONTokens = 12
+
+	// Delimiters inside strings are not tokens.
+	_, err = parse(`{"name":"[1,2,3,4,5,6,7,8,9]","values":[1,2,3,4,5]}`)
+	assert.NoError(t, err)
+
+	w, err := parse(`{"name":"a","values":[` + strings.Repeat("0,", 10000) + `0]}`)
+	var tokensErr *formparser.MaxTokensError
+	assert.ErrorAs(t, err, &tokensErr)
+	assert.Equal(t, 12, tokensErr.Limit)
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	assert.Contains(t, w.Body.String(), "too many elements")
+}
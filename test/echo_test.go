@@ -0,0 +1,81 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type PaymentAddress struct {
+	Street string `form:"street"`
+	Secret string `form:"secret" sensitive:"true"`
+}
+
+type CheckoutForm struct {
+	Name     string          `form:"name" json:"full_name"`
+	Card     string          `form:"card" sensitive:"last4"`
+	CVC      string          `form:"cvc" sensitive:"true"`
+	PIN      int             `form:"pin" sensitive:"mask"`
+	Price    float64         `form:"price" readonly:"true"`
+	Internal string          `json:"-"`
+	Address  PaymentAddress  `form:"address"`
+	Billing  *PaymentAddress `form:"billing"`
+	Date     time.Time       `form:"date"`
+}
+
+func TestEcho(t *testing.T) {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	form := CheckoutForm{
+		Name:     "John",
+		Card:     "4242424242424242",
+		CVC:      "123",
+		PIN:      1234,
+		Price:    9.5,
+		Internal: "x",
+		Address:  PaymentAddress{Street: "Main St", Secret: "s"},
+		Date:     date,
+	}
+
+	assert.Equal(t, []formparser.EchoField{
+		{Name: "full_name", Value: "John"},
+		{Name: "card", Value: "************4242", Masked: true},
+		{Name: "pin", Value: "****", Masked: true},
+		{Name: "price", Value: 9.5, ReadOnly: true},
+		{Name: "address", Value: []formparser.EchoField{{Name: "street", Value: "Main St"}}},
+		{Name: "billing", Value: nil},
+		{Name: "date", Value: date},
+	}, formparser.Echo(&form))
+}
+
+func TestEchoShortAndEmptyMasks(t *testing.T) {
+	fields := formparser.Echo(CheckoutForm{Card: "42"})
+	assert.Equal(t, formparser.EchoField{Name: "card", Value: "**", Masked: true}, fields[1])
+	assert.Equal(t, formparser.EchoField{Name: "pin", Value: "", Masked: true}, fields[2])
+
+	_, err := json.Marshal(fields)
+	assert.NoError(t, err)
+}
+
+type EchoContactsForm struct {
+	Tags      []string                   `form:"tags"`
+	Addresses []PaymentAddress           `form:"addresses"`
+	ByName    map[string]*PaymentAddress `form:"by_name"`
+}
+
+func TestEchoNestedCollections(t *testing.T) {
+	form := EchoContactsForm{
+		Tags:      []string{"a"},
+		Addresses: []PaymentAddress{{Street: "Main St", Secret: "s1"}},
+		ByName:    map[string]*PaymentAddress{"home": {Street: "Elm St", Secret: "s2"}},
+	}
+	street := func(s string) []formparser.EchoField { return []formparser.EchoField{{Name: "street", Value: s}} }
+
+	assert.Equal(t, []formparser.EchoField{
+		{Name: "tags", Value: []string{"a"}},
+		{Name: "addresses", Value: []any{street("Main St")}},
+		{Name: "by_name", Value: map[string]any{"home": street("Elm St")}},
+	}, formparser.Echo(form))
+}
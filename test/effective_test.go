@@ -0,0 +1,29 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffective(t *testing.T) {
+	cfg := setupParser()
+	cfg.MaxFileSize = 0
+	cfg.UploadTokenSecret = []byte("do-not-leak")
+	cfg.FileStore = formparser.DiskStore{Root: t.TempDir()}
+	formparser.RegisterDstPool[TestForm](cfg, nil)
+
+	eff := cfg.Effective()
+
+	assert.Equal(t, int64(5<<20), eff.MaxFileSize)
+	assert.Equal(t, []string{"image/png"}, eff.AllowedMIMETypes)
+	assert.Equal(t, "default", eff.TagMode)
+	assert.Equal(t, []string{"Decoder", "FileStore", "Validator"}, eff.Hooks)
+	assert.Equal(t, []string{"test.TestForm"}, eff.DstPools)
+
+	data, err := json.Marshal(eff)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "do-not-leak")
+}
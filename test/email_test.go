@@ -0,0 +1,108 @@
+package test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type InboundEmail struct {
+	From        string `form:"from" validate:"required"`
+	To          string `form:"to"`
+	Subject     string `form:"subject" validate:"required"`
+	Text        string `form:"text"`
+	HTML        string `form:"html"`
+	Attachments int    `form:"attachments"`
+	Attachment1 string `form:"attachment1"`
+}
+
+const inboundEmailBody = "From: Ann <ann@example.com>\r\n" +
+	"To: support@example.com\r\n" +
+	"Subject: =?ISO-8859-1?Q?Caf=E9_order?=\r\n" +
+	"Message-ID: <1@example.com>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=ISO-8859-1\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"One caf=E9 please.\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=UTF-8\r\n" +
+	"\r\n" +
+	"<p>One caf\xc3\xa9 please.</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: image/png; name=\"menu.png\"\r\n" +
+	"Content-Disposition: attachment; filename=\"menu.png\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"%s\r\n" +
+	"--outer--\r\n"
+
+func newEmailRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/inbound", strings.NewReader(body))
+	req.Header.Set("Content-Type", "message/rfc822")
+	return req
+}
+
+func TestParseEmail(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	body := strings.Replace(inboundEmailBody, "%s", base64.StdEncoding.EncodeToString(png), 1)
+	cfg := setupParser()
+	var email InboundEmail
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), newEmailRequest(body), &email))
+
+	assert.Equal(t, "Ann <ann@example.com>", email.From)
+	assert.Equal(t, "support@example.com", email.To)
+	assert.Equal(t, "Café order", email.Subject)
+	assert.Equal(t, "One café please.", email.Text)
+	assert.Equal(t, "<p>One café please.</p>", email.HTML)
+	assert.Equal(t, 1, email.Attachments)
+
+	file := cfg.Files["attachment1"]
+	assert.Equal(t, "menu.png", file.Filename)
+	assert.Equal(t, "image/png", file.ContentType)
+	assert.Equal(t, png, file.Content)
+	assert.Equal(t, file.Hash, email.Attachment1)
+	assert.Equal(t, "<1@example.com>", cfg.Result.Email.Header.Get("Message-ID"))
+	assert.Len(t, cfg.Result.Email.Attachments, 1)
+}
+
+func TestParseEmailRejectsAttachments(t *testing.T) {
+	t.Run("type", func(t *testing.T) {
+		body := strings.Replace(inboundEmailBody, "image/png", "application/zip", 1)
+		body = strings.Replace(body, "%s", "UEsDBA==", 1)
+		rec := httptest.NewRecorder()
+		assert.Error(t, setupParser().ParseFormBasedOnContentType(rec, newEmailRequest(body), &InboundEmail{}))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Unsupported file type")
+	})
+	t.Run("size", func(t *testing.T) {
+		body := strings.Replace(inboundEmailBody, "%s", base64.StdEncoding.EncodeToString(make([]byte, 2048)), 1)
+		cfg := setupParser()
+		cfg.MaxFileSize = 1024
+		rec := httptest.NewRecorder()
+		assert.Error(t, cfg.ParseFormBasedOnContentType(rec, newEmailRequest(body), &InboundEmail{}))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+}
+
+func TestParseEmailValidates(t *testing.T) {
+	body := "From: ann@example.com\r\n\r\nNo subject here.\r\n"
+	cfg := setupParser()
+	var email InboundEmail
+	rec := httptest.NewRecorder()
+	assert.Error(t, cfg.ParseFormBasedOnContentType(rec, newEmailRequest(body), &email))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"subject"`)
+	assert.Equal(t, "No subject here.\r\n", email.Text)
+}
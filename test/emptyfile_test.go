@@ -0,0 +1,57 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type OptionalAvatarForm struct {
+	Name   string `form:"name" validate:"required"`
+	Avatar string `form:"avatar"`
+}
+
+func TestEmptyFilePartSkipped(t *testing.T) {
+	cfg := setupParser()
+	// Browsers send an empty file input as filename="" with a generic type.
+	req := newMultipartRequest(t, map[string]string{"name": "John"},
+		testFile{Field: "avatar", Filename: "", ContentType: "application/octet-stream"})
+	w := httptest.NewRecorder()
+
+	var form OptionalAvatarForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Files)
+	assert.Empty(t, form.Avatar)
+}
+
+func TestEmptyNamedFilePartSkipped(t *testing.T) {
+	cfg := setupParser()
+	req := newMultipartRequest(t, map[string]string{"name": "John"},
+		testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png"})
+	w := httptest.NewRecorder()
+
+	var form OptionalAvatarForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(w, req, &form))
+	assert.Nil(t, cfg.Files["avatar"])
+}
+
+func TestEmptyFilePartRequired(t *testing.T) {
+	cfg := setupParser()
+	cfg.EmptyFileRequired = true
+	cfg.FieldErrorMessages["avatar"] = "Please choose an avatar"
+	req := newMultipartRequest(t, map[string]string{"name": "John"},
+		testFile{Field: "avatar", Filename: "", ContentType: "application/octet-stream"})
+	w := httptest.NewRecorder()
+
+	var form OptionalAvatarForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	assert.Error(t, err)
+
+	var resp validationResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Please choose an avatar", resp.Fields["avatar"])
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func compress(t *testing.T, newWriter func(io.Writer) io.WriteCloser, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := newWriter(&buf)
+	_, err := zw.Write([]byte(data))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func gzipWriter(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }
+
+func brotliWriter(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }
+
+func zstdWriter(w io.Writer) io.WriteCloser { zw, _ := zstd.NewWriter(w); return zw }
+
+func TestContentEncoding(t *testing.T) {
+	payload := `{"name":"John","email":"john@example.com"}`
+	tests := []struct {
+		encoding string
+		body     []byte
+	}{
+		{"gzip", compress(t, gzipWriter, payload)},
+		{"deflate", compress(t, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }, payload)},
+		{"deflate", compress(t, func(w io.Writer) io.WriteCloser { zw, _ := flate.NewWriter(w, flate.DefaultCompression); return zw }, payload)},
+		{"br", compress(t, brotliWriter, payload)},
+		{"zstd", compress(t, zstdWriter, payload)},
+		{"br, zstd", compress(t, zstdWriter, string(compress(t, brotliWriter, payload)))},
+		{"gzip, gzip", compress(t, gzipWriter, string(compress(t, gzipWriter, payload)))},
+		{"identity", []byte(payload)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Content-Encoding", tt.encoding)
+			var form TestForm
+			assert.NoError(t, setupParser().ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+			assert.Equal(t, "john@example.com", form.Email)
+		})
+	}
+}
+
+func TestContentEncodingCustom(t *testing.T) {
+	cfg := setupParser()
+	cfg.Decompressors = map[string]formparser.Decompressor{
+		"rot": func(body io.Reader) (io.ReadCloser, error) { return io.NopCloser(body), nil },
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=John&email=john@example.com"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Content-Encoding", "rot")
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TestForm{}))
+	assert.Contains(t, cfg.Effective().ContentEncodings, "rot")
+}
+
+func TestContentEncodingRejected(t *testing.T) {
+	bomb := compress(t, gzipWriter, `{"name":"`+strings.Repeat("a", 1<<20)+`"}`)
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		status   int
+	}{
+		{"bomb", "gzip", bomb, http.StatusRequestEntityTooLarge},
+		{"br bomb", "br", compress(t, brotliWriter, `{"name":"`+strings.Repeat("a", 1<<20)+`"}`), http.StatusRequestEntityTooLarge},
+		{"zstd bomb", "zstd", compress(t, zstdWriter, `{"name":"`+strings.Repeat("a", 1<<20)+`"}`), http.StatusRequestEntityTooLarge},
+		{"unsupported", "compress", []byte("x"), http.StatusUnsupportedMediaType},
+		{"corrupt", "gzip", []byte("not gzip"), http.StatusBadRequest},
+		{"corrupt zstd", "zstd", []byte("not zstd"), http.StatusBadRequest},
+		{"trailing data", "deflate", append(compress(t, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }, `{}`), make([]byte, 1<<20)...), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := setupParser()
+			cfg.MaxDecompressedSize = 64 << 10
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Content-Encoding", tt.encoding)
+			rec := httptest.NewRecorder()
+			assert.Error(t, cfg.ParseFormBasedOnContentType(rec, req, &TestForm{}))
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status == http.StatusUnsupportedMediaType {
+				assert.Equal(t, "br, deflate, gzip, x-gzip, zstd", rec.Header().Get("Accept-Encoding"))
+			}
+		})
+	}
+}
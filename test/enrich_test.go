@@ -0,0 +1,102 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey string
+
+type CommentForm struct {
+	Body     string `json:"body" validate:"required"`
+	UserID   int64  `json:"user_id" ctx:"user_id" validate:"required"`
+	TenantID string `json:"tenant_id" ctx:"tenant_id"`
+}
+
+func TestEnricher(t *testing.T) {
+	cfg := setupParser()
+	cfg.Enricher = formparser.EnricherFunc(func(ctx context.Context, key string) (any, bool) {
+		v := ctx.Value(ctxKey(key))
+		return v, v != nil
+	})
+
+	// The client tries to spoof both identity fields.
+	payload := `{"body":"hi","user_id":1,"tenant_id":"other"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), ctxKey("user_id"), 42))
+
+	var form CommentForm
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form)
+
+	assert.NoError(t, err)
+	assert.Equal(t, CommentForm{Body: "hi", UserID: 42}, form)
+}
+
+func TestEnricherMissingIdentity(t *testing.T) {
+	cfg := setupParser()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"body":"hi","user_id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	var form CommentForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), `"userid"`)
+}
+
+func TestEnricherConversions(t *testing.T) {
+	tests := []struct {
+		value any
+		want  int64
+		ok    bool
+	}{
+		{int64(42), 42, true},
+		{42, 42, true},
+		{uint8(7), 7, true},
+		{float64(3), 3, true},
+		{3.5, 0, false},
+		{uint64(1 << 63), 0, false},
+		{"42", 0, false},
+	}
+	for _, tt := range tests {
+		cfg := setupParser()
+		cfg.Enricher = formparser.EnricherFunc(func(ctx context.Context, key string) (any, bool) {
+			return tt.value, key == "user_id"
+		})
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"body":"hi"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		var form CommentForm
+		err := cfg.ParseFormBasedOnContentType(w, req, &form)
+		if tt.ok {
+			assert.NoError(t, err, "%T %v", tt.value, tt.value)
+			assert.Equal(t, tt.want, form.UserID)
+		} else {
+			assert.Equal(t, http.StatusBadRequest, w.Code, "%T %v", tt.value, tt.value)
+			assert.Contains(t, w.Body.String(), "userid has an invalid value")
+		}
+	}
+}
+
+type HandleForm struct {
+	Handle string `json:"handle" ctx:"handle"`
+}
+
+func TestEnricherRejectsIntForString(t *testing.T) {
+	cfg := setupParser()
+	cfg.Enricher = formparser.EnricherFunc(func(ctx context.Context, key string) (any, bool) { return 65, true })
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	var form HandleForm
+	assert.Error(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+	assert.Empty(t, form.Handle)
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type EnrollForm struct {
+	Name string `json:"name" validate:"required,min=3"`
+	Age  int    `json:"age" validate:"gte=18"`
+}
+
+func enrollRequest(format string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"Al","age":12}`))
+	req.Header.Set("Content-Type", "application/json")
+	if format != "" {
+		req.Header.Set(formparser.ErrorFormatHeader, format)
+	}
+	return req
+}
+
+func TestErrorFormatV1(t *testing.T) {
+	cfg := setupParser()
+	w := httptest.NewRecorder()
+	assert.Error(t, cfg.ParseFormBasedOnContentType(w, enrollRequest(""), &EnrollForm{}))
+
+	assert.Equal(t, "v1", w.Header().Get(formparser.ErrorFormatHeader))
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Validation failed", body["message"])
+	assert.Contains(t, body, "fields")
+	assert.NotContains(t, body, "errors")
+}
+
+func TestErrorFormatV2(t *testing.T) {
+	cfg := setupParser()
+	cfg.ErrorFormat = formparser.ErrorFormatV2
+	w := httptest.NewRecorder()
+	assert.Error(t, cfg.ParseFormBasedOnContentType(w, enrollRequest(""), &EnrollForm{}))
+
+	assert.Equal(t, "v2", w.Header().Get(formparser.ErrorFormatHeader))
+	var body struct {
+		Message string                    `json:"message"`
+		Version int                       `json:"version"`
+		Errors  []formparser.FieldErrorV2 `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 2, body.Version)
+	assert.Equal(t, []formparser.FieldErrorV2{
+		{Field: "age", Path: "age", Code: "gte", Message: body.Errors[0].Message, Params: map[string]string{"param": "18"}},
+		{Field: "name", Path: "name", Code: "min", Message: "Name is required", Params: map[string]string{"param": "3"}},
+	}, body.Errors)
+}
+
+func TestErrorFormatHeaderOverride(t *testing.T) {
+	cfg := setupParser()
+	cfg.ErrorFormat = formparser.ErrorFormatV2
+
+	w := httptest.NewRecorder()
+	assert.Error(t, cfg.ParseFormBasedOnContentType(w, enrollRequest("v1"), &EnrollForm{}))
+	assert.Equal(t, "v1", w.Header().Get(formparser.ErrorFormatHeader))
+	assert.Contains(t, w.Body.String(), `"fields"`)
+
+	cfg.ErrorFormat = formparser.ErrorFormatV1
+	w = httptest.NewRecorder()
+	assert.Error(t, cfg.ParseFormBasedOnContentType(w, enrollRequest("2"), &EnrollForm{}))
+	assert.Contains(t, w.Body.String(), `"version":2`)
+
+	w = httptest.NewRecorder()
+	assert.Error(t, cfg.ParseFormBasedOnContentType(w, enrollRequest("v9"), &EnrollForm{}))
+	assert.Equal(t, "v1", w.Header().Get(formparser.ErrorFormatHeader))
+}
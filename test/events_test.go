@@ -0,0 +1,123 @@
+package test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func collectEvents(events <-chan formparser.ParseEvent) []formparser.ParseEvent {
+	var out []formparser.ParseEvent
+	for {
+		select {
+		case ev := <-events:
+			out = append(out, ev)
+		default:
+			return out
+		}
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	cfg := setupParser()
+	cfg.Events = formparser.NewEventStream()
+	events, cancel := cfg.Events.Subscribe("abc")
+	defer cancel()
+
+	req := newMultipartRequest(t, map[string]string{"name": "John", "email": "john@example.com"},
+		testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png", Content: []byte("PNG data")})
+	req.Header.Set("X-Upload-ID", "abc")
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	assert.NoError(t, err)
+
+	got := collectEvents(events)
+	var types []formparser.EventType
+	for _, ev := range got {
+		assert.Equal(t, "abc", ev.UploadID)
+		if ev.Type != formparser.EventFileProgress {
+			types = append(types, ev.Type)
+		}
+	}
+	assert.Equal(t, []formparser.EventType{
+		formparser.EventStarted, formparser.EventField, formparser.EventField, formparser.EventFileDone, formparser.EventCompleted,
+	}, types)
+
+	last := got[len(got)-1]
+	assert.Equal(t, req.ContentLength, last.Total)
+	assert.Equal(t, 100, last.Percent)
+}
+
+func TestParseEventsValidationFailed(t *testing.T) {
+	cfg := setupParser()
+	cfg.Events = formparser.NewEventStream()
+	events, cancel := cfg.Events.Subscribe("abc")
+	defer cancel()
+	other, cancelOther := cfg.Events.Subscribe("other")
+	defer cancelOther()
+
+	req := httptest.NewRequest(http.MethodPost, "/?upload_id=abc", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	assert.Error(t, cfg.ParseFormBasedOnContentType(w, req, &form))
+
+	got := collectEvents(events)
+	assert.Len(t, got, 2)
+	assert.Equal(t, formparser.EventValidationFailed, got[1].Type)
+	assert.Equal(t, "Validation failed", got[1].Message)
+	assert.Empty(t, collectEvents(other))
+}
+
+func TestEventStreamServeSSE(t *testing.T) {
+	stream := formparser.NewEventStream()
+	stream.Authorize = func(r *http.Request, uploadID string) error { return nil }
+	srv := httptest.NewServer(stream)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?upload_id=abc")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	cfg := setupParser()
+	cfg.Events = stream
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","email":"john@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Upload-ID", "abc")
+	var form TestForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "event: ") {
+			lines = append(lines, scanner.Text())
+		}
+	}
+	assert.Equal(t, []string{"event: started", "event: completed"}, lines)
+}
+
+func TestEventStreamAuthorize(t *testing.T) {
+	stream := formparser.NewEventStream()
+	rec := httptest.NewRecorder()
+	stream.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?upload_id=abc", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	stream.Authorize = func(r *http.Request, uploadID string) error {
+		return &formparser.RejectError{Status: http.StatusUnauthorized, Message: "Sign in to follow uploads"}
+	}
+	rec = httptest.NewRecorder()
+	stream.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?upload_id=abc", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Sign in to follow uploads\n", rec.Body.String())
+	assert.NotEqual(t, "text/event-stream", rec.Header().Get("Content-Type"))
+}
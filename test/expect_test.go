@@ -0,0 +1,61 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+// untouchableBody fails the test if the parser reads the request body.
+type untouchableBody struct{ t *testing.T }
+
+func (b untouchableBody) Read(p []byte) (int, error) {
+	b.t.Error("body was read despite the pre-check rejection")
+	return 0, errors.New("unexpected read")
+}
+
+func (b untouchableBody) Close() error { return nil }
+
+func TestBeforeBodyRejectsExpectContinue(t *testing.T) {
+	cfg := setupParser()
+	cfg.BeforeBody = func(r *http.Request) error {
+		if r.ContentLength > 1024 {
+			return &formparser.RejectError{Status: http.StatusRequestEntityTooLarge, Message: "Upload too large"}
+		}
+		return nil
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+	req.Header.Set("Expect", "100-continue")
+	req.ContentLength = 10 << 20
+	req.Body = untouchableBody{t}
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Result().StatusCode)
+	assert.Equal(t, "close", w.Header().Get("Connection"))
+}
+
+func TestBeforeBodyDefaultStatus(t *testing.T) {
+	cfg := setupParser()
+	cfg.BeforeBody = func(r *http.Request) error { return errors.New("quota exceeded") }
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Expect", "100-continue")
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	assert.Error(t, cfg.ParseFormBasedOnContentType(w, req, &form))
+	assert.Equal(t, http.StatusExpectationFailed, w.Result().StatusCode)
+	assert.NotContains(t, w.Body.String(), "quota exceeded")
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingContentType(t *testing.T) {
+	jsonBody := `{"name":"John","email":"john@example.com"}`
+	formBody := "name=John&email=john@example.com"
+
+	tests := []struct {
+		name     string
+		fallback formparser.ContentTypeFallback
+		body     string
+		status   int
+	}{
+		{"reject", formparser.FallbackReject, jsonBody, http.StatusUnsupportedMediaType},
+		{"json", formparser.FallbackJSON, jsonBody, http.StatusOK},
+		{"json mismatch", formparser.FallbackJSON, formBody, http.StatusBadRequest},
+		{"urlencoded", formparser.FallbackURLEncoded, formBody, http.StatusOK},
+		{"sniff json", formparser.FallbackSniff, "\n  " + jsonBody, http.StatusOK},
+		{"sniff urlencoded", formparser.FallbackSniff, formBody, http.StatusOK},
+		{"sniff xml", formparser.FallbackSniff, "<TestForm><Name>John</Name><Email>john@example.com</Email></TestForm>", http.StatusOK},
+		{"sniff unknown", formparser.FallbackSniff, "hello world", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := setupParser()
+			cfg.MissingContentType = tt.fallback
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			var form TestForm
+			err := cfg.ParseFormBasedOnContentType(rec, req, &form)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status == http.StatusOK {
+				assert.NoError(t, err)
+				assert.Equal(t, "john@example.com", form.Email)
+			}
+		})
+	}
+}
+
+func TestMissingContentTypeExplicitWins(t *testing.T) {
+	cfg := setupParser()
+	cfg.MissingContentType = formparser.FallbackJSON
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=John&email=john@example.com"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TestForm{}))
+	assert.Equal(t, "json", cfg.Effective().MissingContentType)
+}
@@ -0,0 +1,47 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type EditProfileForm struct {
+	Name   string `form:"name"`
+	Avatar string `form:"avatar"`
+	Banner string `form:"banner"`
+	Resume string `form:"resume"`
+}
+
+func TestFileActions(t *testing.T) {
+	cfg := setupParser()
+	req := newMultipartRequest(t,
+		map[string]string{"name": "Ann", "banner_delete": "on", "resume_delete": "false"},
+		testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png", Content: []byte("png")},
+		testFile{Field: "resume", Filename: "", ContentType: "image/png"},
+	)
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &EditProfileForm{}))
+	res := cfg.Result
+	assert.Equal(t, formparser.FileReplace, res.FileAction("avatar"))
+	assert.Equal(t, formparser.FileDelete, res.FileAction("banner"))
+	assert.Equal(t, formparser.FileKeep, res.FileAction("resume"))
+	assert.Equal(t, formparser.FileKeep, res.FileAction("name"))
+
+	// A new file wins over a delete flag.
+	req = newMultipartRequest(t, map[string]string{"avatar_delete": "1"},
+		testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png", Content: []byte("png")})
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &EditProfileForm{}))
+	res = cfg.Result
+	assert.Equal(t, formparser.FileReplace, res.FileAction("avatar"))
+	assert.Equal(t, "replace", res.FileAction("avatar").String())
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=Ann&avatar_delete=true"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &EditProfileForm{}))
+	res = cfg.Result
+	assert.Equal(t, formparser.FileDelete, res.FileAction("avatar"))
+}
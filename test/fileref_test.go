@@ -0,0 +1,31 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadedFileMarshalJSON(t *testing.T) {
+	file := &formparser.UploadedFile{
+		Filename:    "avatar.png",
+		ContentType: "image/png",
+		Content:     []byte("PNG IMAGE CONTENT"),
+		Hash:        "abc",
+		StorageKey:  "avatar/abc/avatar.png",
+		Variants: []*formparser.UploadedFile{
+			{Filename: "avatar_50x50.png", ContentType: "image/png", Content: []byte("small"), Hash: "def"},
+		},
+	}
+
+	data, err := json.Marshal(map[string]*formparser.UploadedFile{"avatar": file})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"avatar": {
+		"filename": "avatar.png", "content_type": "image/png", "size": 17, "hash": "abc",
+		"storage_key": "avatar/abc/avatar.png",
+		"variants": [{"filename": "avatar_50x50.png", "content_type": "image/png", "size": 5, "hash": "def"}]
+	}}`, string(data))
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type GalleryForm struct {
+	Title  string `form:"title"`
+	Photos string `form:"photos" file:"required,accept=image/png image/jpeg,maxsize=8B,maxcount=2"`
+	Notes  string `form:"notes" file:"accept=text/*"`
+}
+
+func TestFileTagConstraints(t *testing.T) {
+	png := func(content string) testFile {
+		return testFile{Field: "photos", Filename: "a.png", ContentType: "image/png", Content: []byte(content)}
+	}
+	tests := []struct {
+		name  string
+		files []testFile
+		field string
+		msg   string
+	}{
+		{"ok", []testFile{png("tiny"), {Field: "notes", Filename: "n.txt", ContentType: "text/plain", Content: []byte("hi")}}, "", ""},
+		{"missing", nil, "photos", "photos is required"},
+		{"accept", []testFile{{Field: "photos", Filename: "a.gif", ContentType: "image/gif", Content: []byte("gif")}}, "photos", "photos must be one of image/png, image/jpeg"},
+		{"maxsize", []testFile{png("far too large")}, "photos", "photos must be at most 8B"},
+		{"maxcount", []testFile{png("1"), png("2"), png("3")}, "photos", "photos accepts at most 2 files"},
+		{"accept overrides AllowedMIMETypes", []testFile{png("ok"), {Field: "notes", Filename: "n.pdf", ContentType: "application/pdf", Content: []byte("%PDF")}}, "notes", "notes must be one of text/*"},
+	}
+	for _, tt := range tests {
+		cfg := setupParser()
+		rec := httptest.NewRecorder()
+		err := cfg.ParseFormBasedOnContentType(rec, newMultipartRequest(t, map[string]string{"title": "Trip"}, tt.files...), &GalleryForm{})
+		if tt.field == "" {
+			assert.NoError(t, err, tt.name)
+			continue
+		}
+		assert.Error(t, err, tt.name)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, tt.name)
+		var body validationResponse
+		assert.NoError(t, json.NewDecoder(bytes.NewReader(rec.Body.Bytes())).Decode(&body), tt.name)
+		assert.Equal(t, tt.msg, body.Fields[tt.field], tt.name)
+	}
+}
+
+func TestFileTagCustomMessage(t *testing.T) {
+	cfg := setupParser()
+	cfg.FieldErrorMessages["photos"] = "Please upload a PNG or JPEG"
+	rec := httptest.NewRecorder()
+	req := newMultipartRequest(t, nil, testFile{Field: "photos", Filename: "a.gif", ContentType: "image/gif", Content: []byte("gif")})
+	assert.Error(t, cfg.ParseFormBasedOnContentType(rec, req, &GalleryForm{}))
+	assert.Contains(t, rec.Body.String(), "Please upload a PNG or JPEG")
+}
+
+func TestCheckStructFileTag(t *testing.T) {
+	type badUpload struct {
+		Scan string `form:"scan" file:"maxsize=lots"`
+	}
+	findings := formparser.CheckStruct(&badUpload{}, nil)
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, formparser.FindingInvalidTag, findings[0].Kind)
+		assert.Equal(t, "file", findings[0].Tag)
+	}
+}
+
+type DataURIScanForm struct {
+	Scan *formparser.UploadedFile `json:"scan" form:"scan_file" file:"required,accept=image/png,maxsize=4B"`
+}
+
+func TestFileTagDataURI(t *testing.T) {
+	uri := func(mediaType, content string) string {
+		return `{"scan":"data:` + mediaType + `;base64,` + base64.StdEncoding.EncodeToString([]byte(content)) + `"}`
+	}
+	tests := []struct {
+		name string
+		body string
+		msg  string
+	}{
+		{"ok", uri("image/png", "png"), ""},
+		{"missing", `{}`, "scan is required"},
+		{"accept", uri("image/gif", "gif"), "scan must be one of image/png"},
+		{"maxsize", uri("image/png", "too large"), "scan must be at most 4B"},
+	}
+	for _, tt := range tests {
+		cfg := setupParser()
+		rec := httptest.NewRecorder()
+		err := cfg.ParseFormBasedOnContentType(rec, newJSONRequest(tt.body), &DataURIScanForm{})
+		if tt.msg == "" {
+			assert.NoError(t, err, tt.name)
+			continue
+		}
+		assert.Error(t, err, tt.name)
+		var body validationResponse
+		assert.NoError(t, json.NewDecoder(bytes.NewReader(rec.Body.Bytes())).Decode(&body), tt.name)
+		assert.Equal(t, tt.msg, body.Fields["scan"], tt.name)
+	}
+}
+
+type LargeScanForm struct {
+	Scan string `form:"scan" file:"maxsize=16B"`
+}
+
+func TestFileTagMaxSizeCappedByMaxFileSize(t *testing.T) {
+	req := func() *http.Request {
+		return newMultipartRequest(t, nil, testFile{Field: "scan", Filename: "s.png", ContentType: "image/png", Content: []byte("twelve bytes")})
+	}
+	cfg := setupParser()
+	cfg.MaxFileSize = 8
+	rec := httptest.NewRecorder()
+	assert.Error(t, cfg.ParseFormBasedOnContentType(rec, req(), &LargeScanForm{}))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	if findings := formparser.CheckStruct(&LargeScanForm{}, cfg); assert.Len(t, findings, 1) {
+		assert.Equal(t, formparser.FindingMissingConfig, findings[0].Kind)
+	}
+
+	cfg.AllowFileTagMaxSize = true
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req(), &LargeScanForm{}))
+	assert.Empty(t, formparser.CheckStruct(&LargeScanForm{}, cfg))
+}
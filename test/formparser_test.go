@@ -52,6 +52,20 @@ func TestParseJSON(t *testing.T) {
 	assert.Equal(t, "john@example.com", form.Email)
 }
 
+func TestParseJSONStructuredSuffix(t *testing.T) {
+	for _, contentType := range []string{"application/vnd.api+json", "application/problem+json; charset=utf-8", "application/HAL+JSON"} {
+		cfg := setupParser()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","email":"john@example.com"}`))
+		req.Header.Set("Content-Type", contentType)
+
+		var form TestForm
+		err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form)
+
+		assert.NoError(t, err, contentType)
+		assert.Equal(t, "John", form.Name)
+	}
+}
+
 func TestParseURLEncoded(t *testing.T) {
 	cfg := setupParser()
 	data := url.Values{}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type OfferState struct {
+	Version int `json:"version"`
+	Price   int `json:"price"`
+}
+
+type OfferForm struct {
+	Name  string     `form:"name" validate:"required"`
+	Offer OfferState `form:"offer" state:"_offer"`
+}
+
+func stateParser() *formparser.Config {
+	cfg := setupParser()
+	cfg.FormStateSecret = []byte("state-secret")
+	return cfg
+}
+
+func postOffer(t *testing.T, cfg *formparser.Config, values url.Values) (*OfferForm, *httptest.ResponseRecorder, error) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	var form OfferForm
+	err := cfg.ParseFormBasedOnContentType(rec, req, &form)
+	return &form, rec, err
+}
+
+func TestFormState(t *testing.T) {
+	cfg := stateParser()
+	token, err := cfg.SignFormState("_offer", OfferState{Version: 3, Price: 1999})
+	assert.NoError(t, err)
+
+	form, _, err := postOffer(t, cfg, url.Values{"name": {"John"}, "_offer": {token}, "offer.Price": {"1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, OfferState{Version: 3, Price: 1999}, form.Offer)
+
+	html, err := cfg.FormStateField("_offer", OfferState{Version: 3})
+	assert.NoError(t, err)
+	assert.Contains(t, string(html), `<input type="hidden" name="_offer" value="`)
+}
+
+func TestFormStateRejected(t *testing.T) {
+	cfg := stateParser()
+	token, _ := cfg.SignFormState("_offer", OfferState{Version: 3, Price: 1999})
+	forged, _ := (&formparser.Config{FormStateSecret: []byte("other")}).SignFormState("_offer", OfferState{Version: 3, Price: 1})
+	otherKey, _ := cfg.SignFormState("_version", OfferState{Version: 3, Price: 1})
+	payload, sig, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"missing", "", "offer is missing"},
+		{"forged", forged, "offer has been modified"},
+		{"tampered", payload + "x." + sig, "offer has been modified"},
+		{"other field", otherKey, "offer has been modified"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form, rec, err := postOffer(t, cfg, url.Values{"name": {"John"}, "_offer": {tt.token}})
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.want)
+			assert.Zero(t, form.Offer)
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","offer":{"price":1}}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		var form OfferForm
+		assert.Error(t, cfg.ParseFormBasedOnContentType(rec, req, &form))
+		assert.Contains(t, rec.Body.String(), "offer is missing")
+		assert.Zero(t, form.Offer)
+	})
+
+	_, err := (&formparser.Config{}).SignFormState("_offer", OfferState{})
+	assert.Error(t, err)
+	assert.ErrorIs(t, cfg.VerifyFormState("_offer", "nope", &OfferState{}), formparser.ErrInvalidFormState)
+}
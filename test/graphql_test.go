@@ -0,0 +1,91 @@
+package test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type UploadMutation struct {
+	Query     string `json:"query" validate:"required"`
+	Variables struct {
+		File  *string   `json:"file"`
+		Files []*string `json:"files"`
+	} `json:"variables"`
+}
+
+func TestParseGraphQLMultipart(t *testing.T) {
+	cfg := setupParser()
+	req := newMultipartRequest(t, map[string]string{
+		"operations": `{"query":"mutation ($file: Upload!, $files: [Upload!]!) { upload(file: $file, files: $files) }","variables":{"file":null,"files":[null]}}`,
+		"map":        `{"0":["variables.file","variables.files.0"]}`,
+	}, testFile{Field: "0", Filename: "a.png", ContentType: "image/png", Content: []byte("PNG")})
+
+	rec := httptest.NewRecorder()
+	var op UploadMutation
+	assert.NoError(t, cfg.ParseGraphQLMultipart(rec, req, &op))
+	assert.Contains(t, op.Query, "mutation")
+	assert.Nil(t, op.Variables.File)
+	if assert.Contains(t, cfg.Files, "variables.file") {
+		assert.Equal(t, "a.png", cfg.Files["variables.file"].Filename)
+		assert.Same(t, cfg.Files["variables.file"], cfg.Files["variables.files.0"])
+	}
+}
+
+func TestParseGraphQLMultipartInvalid(t *testing.T) {
+	cfg := setupParser()
+	operations := `{"query":"mutation { upload }","variables":{"file":null}}`
+	png := testFile{Field: "0", Filename: "a.png", ContentType: "image/png", Content: []byte("PNG")}
+
+	tests := []struct {
+		name   string
+		fields map[string]string
+		files  []testFile
+		status int
+	}{
+		{"missing operations", map[string]string{"map": `{}`}, nil, http.StatusBadRequest},
+		{"missing map", map[string]string{"operations": operations}, nil, http.StatusBadRequest},
+		{"unmapped file", map[string]string{"operations": operations, "map": `{}`}, []testFile{png}, http.StatusBadRequest},
+		{"missing file part", map[string]string{"operations": operations, "map": `{"0":["variables.file"]}`}, nil, http.StatusBadRequest},
+		{"batched", map[string]string{"operations": "[" + operations + "]", "map": `{}`}, nil, http.StatusBadRequest},
+		{"invalid operations", map[string]string{"operations": `{"query":`, "map": `{}`}, nil, http.StatusBadRequest},
+		{"validation", map[string]string{"operations": `{"variables":{}}`, "map": `{}`}, nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			err := cfg.ParseGraphQLMultipart(rec, newMultipartRequest(t, tt.fields, tt.files...), &UploadMutation{})
+			assert.Error(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestParseGraphQLMultipartFileBeforeMap(t *testing.T) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("operations", `{"query":"mutation { upload }"}`)
+	part, _ := writer.CreateFormFile("0", "a.png")
+	_, _ = part.Write([]byte("PNG"))
+	_ = writer.WriteField("map", `{"0":["variables.file"]}`)
+	assert.NoError(t, writer.Close())
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	assert.Error(t, setupParser().ParseGraphQLMultipart(rec, req, &UploadMutation{}))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "Can't parse multipart\n", rec.Body.String())
+}
+
+func TestParseGraphQLMultipartUnsupportedType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	assert.Error(t, setupParser().ParseGraphQLMultipart(rec, req, &UploadMutation{}))
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testFile describes a file part for newMultipartRequest.
+type testFile struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Content     []byte
+	Header      map[string]string // Extra part headers
+}
+
+// newMultipartRequest builds a multipart/form-data POST with the given fields and files.
+func newMultipartRequest(t *testing.T, fields map[string]string, files ...testFile) *http.Request {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for name, value := range fields {
+		assert.NoError(t, writer.WriteField(name, value))
+	}
+
+	for _, f := range files {
+		partHeaders := textproto.MIMEHeader{}
+		partHeaders.Set("Content-Disposition", `form-data; name="`+f.Field+`"; filename="`+f.Filename+`"`)
+		partHeaders.Set("Content-Type", f.ContentType)
+		for k, v := range f.Header {
+			partHeaders.Set(k, v)
+		}
+
+		fileWriter, err := writer.CreatePart(partHeaders)
+		assert.NoError(t, err)
+		_, _ = fileWriter.Write(f.Content)
+	}
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+// validationResponse mirrors the JSON body written for validation failures.
+type validationResponse struct {
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields"`
+}
@@ -0,0 +1,40 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type CategoryForm struct {
+	Category string `form:"category" validate:"required"`
+}
+
+func TestStreamingHooks(t *testing.T) {
+	cfg := setupParser()
+	var category string
+	var seen []string
+	cfg.OnField = func(name, value string) {
+		if name == "category" {
+			category = value
+		}
+	}
+	cfg.OnFileStart = func(h formparser.FileHeader) bool {
+		seen = append(seen, h.Field+":"+category)
+		return category == "images"
+	}
+
+	req := newMultipartRequest(t, map[string]string{"category": "documents"},
+		testFile{Field: "upload", Filename: "a.png", ContentType: "image/png", Content: []byte("PNG")})
+	w := httptest.NewRecorder()
+
+	var form CategoryForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.Error(t, err)
+	assert.Equal(t, []string{"upload:documents"}, seen)
+	assert.Nil(t, cfg.Files["upload"])
+	assert.Contains(t, w.Body.String(), "upload was rejected")
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type LocaleForm struct {
+	Country  string `form:"country" json:"country" iso:"country" validate:"required"`
+	Language string `form:"language" json:"language" iso:"language"`
+	Currency string `form:"currency" json:"currency" iso:"currency"`
+}
+
+func TestISOCodesCanonicalized(t *testing.T) {
+	cfg := setupParser()
+	body := url.Values{"country": {" usa "}, "language": {"ENG"}, "currency": {"eur"}}.Encode()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var form LocaleForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+	assert.Equal(t, LocaleForm{Country: "US", Language: "en", Currency: "EUR"}, form)
+}
+
+func TestISOCodesJSON(t *testing.T) {
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"country":"de","currency":"chf"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	var form LocaleForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+	assert.Equal(t, LocaleForm{Country: "DE", Currency: "CHF"}, form)
+}
+
+func TestISOCodesSuggestions(t *testing.T) {
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"country":"UK","language":"xx","currency":"USX"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	var form LocaleForm
+	assert.Error(t, cfg.ParseFormBasedOnContentType(w, req, &form))
+
+	var resp validationResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "country must be an ISO 3166 country code; did you mean UA, UG or UM?", resp.Fields["country"])
+	assert.Equal(t, "language must be an ISO 639 language code; did you mean xh?", resp.Fields["language"])
+	assert.Equal(t, "currency must be an ISO 4217 currency code; did you mean USD, USN or UGX?", resp.Fields["currency"])
+}
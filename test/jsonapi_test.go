@@ -0,0 +1,84 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type ArticleResource struct {
+	ID     string   `json:"id"`
+	Title  string   `json:"title" validate:"required"`
+	Author string   `json:"author" validate:"required"`
+	Tags   []string `json:"tags"`
+}
+
+func jsonAPIRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/vnd.api+json")
+	return req
+}
+
+func TestParseJSONAPI(t *testing.T) {
+	cfg := setupParser()
+	cfg.JSONAPI = true
+	req := jsonAPIRequest(`{"data":{"type":"articles","id":"1",
+		"attributes":{"title":"Hello"},
+		"relationships":{"author":{"data":{"type":"people","id":"9"}},"tags":{"data":[{"type":"tags","id":"go"},{"type":"tags","id":"api"}]}}}}`)
+
+	var article ArticleResource
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &article)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ArticleResource{ID: "1", Title: "Hello", Author: "9", Tags: []string{"go", "api"}}, article)
+}
+
+func TestParseJSONAPIErrors(t *testing.T) {
+	cfg := setupParser()
+	cfg.JSONAPI = true
+	req := jsonAPIRequest(`{"data":{"type":"articles","attributes":{"title":""},"relationships":{"author":{"data":null}}}}`)
+	w := httptest.NewRecorder()
+
+	var article ArticleResource
+	err := cfg.ParseFormBasedOnContentType(w, req, &article)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/vnd.api+json", w.Header().Get("Content-Type"))
+	var body struct {
+		Errors []formparser.JSONAPIError `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	if assert.Len(t, body.Errors, 2) {
+		assert.Equal(t, "400", body.Errors[0].Status)
+		assert.Equal(t, "/data/relationships/author", body.Errors[0].Source.Pointer)
+		assert.Equal(t, "/data/attributes/title", body.Errors[1].Source.Pointer)
+	}
+}
+
+func TestParseJSONAPIInvalidDocument(t *testing.T) {
+	cfg := setupParser()
+	cfg.JSONAPI = true
+	for _, payload := range []string{`{"meta":{}}`, `{"data":`, `{"data":{"relationships":{"author":{"data":"9"}}}}`} {
+		w := httptest.NewRecorder()
+		err := cfg.ParseFormBasedOnContentType(w, jsonAPIRequest(payload), &ArticleResource{})
+
+		assert.Error(t, err, payload)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"title":"Invalid JSON:API document"`)
+	}
+}
+
+func TestParseJSONAPIDisabled(t *testing.T) {
+	cfg := setupParser()
+	req := jsonAPIRequest(`{"id":"1","title":"Hello","author":"9"}`)
+
+	var article ArticleResource
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &article))
+	assert.Equal(t, "Hello", article.Title)
+}
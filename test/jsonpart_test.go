@@ -0,0 +1,98 @@
+package test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ListingForm struct {
+	Title string   `json:"title" form:"title" validate:"required"`
+	Tags  []string `json:"tags" form:"tags"`
+	Photo string   `json:"-" form:"photo" validate:"required"`
+}
+
+// newJSONPartRequest builds a multipart/form-data POST whose field part is
+// sent with contentType, followed by a PNG photo part.
+func newJSONPartRequest(t *testing.T, field, contentType, payload string) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="`+field+`"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	assert.NoError(t, err)
+	_, _ = part.Write([]byte(payload))
+	header = textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="photo"; filename="photo.png"`)
+	header.Set("Content-Type", "image/png")
+	part, err = writer.CreatePart(header)
+	assert.NoError(t, err)
+	_, _ = part.Write([]byte("\x89PNG\r\n\x1a\n"))
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestMultipartJSONPart(t *testing.T) {
+	cfg := setupParser()
+	req := newJSONPartRequest(t, "data", "application/json", `{"title":"Bike","tags":["red","used"]}`)
+	var form ListingForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+	assert.Equal(t, "Bike", form.Title)
+	assert.Equal(t, []string{"red", "used"}, form.Tags)
+	assert.Equal(t, []byte("\x89PNG\r\n\x1a\n"), cfg.Files["photo"].Content)
+	assert.NotContains(t, cfg.Result.Values, "data")
+}
+
+func TestMultipartJSONPartInvalid(t *testing.T) {
+	req := newJSONPartRequest(t, "data", "application/json", `{"title":`)
+	rec := httptest.NewRecorder()
+	assert.Error(t, setupParser().ParseFormBasedOnContentType(rec, req, &ListingForm{}))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Invalid JSON body")
+}
+
+func TestMultipartJSONPartNeedsJSONContentType(t *testing.T) {
+	req := newJSONPartRequest(t, "data", "text/plain", `{"title":"Bike"}`)
+	rec := httptest.NewRecorder()
+	err := setupParser().ParseFormBasedOnContentType(rec, req, &ListingForm{})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "title")
+}
+
+func TestMultipartJSONFieldName(t *testing.T) {
+	tests := map[string]struct {
+		field   string
+		part    string
+		wantErr bool
+	}{
+		"custom name":       {field: "listing", part: "listing"},
+		"default with name": {field: "listing", part: "data", wantErr: true},
+		"disabled":          {field: "-", part: "data", wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := setupParser()
+			cfg.MultipartJSONField = tt.field
+			req := newJSONPartRequest(t, tt.part, "application/json", `{"title":"Bike"}`)
+			var form ListingForm
+			err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "Bike", form.Title)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func jsonPatchRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	return req
+}
+
+func TestParseJSONPatch(t *testing.T) {
+	cfg := setupParser()
+	account := loadedAccount()
+	req := jsonPatchRequest(`[
+		{"op":"test","path":"/name","value":"John"},
+		{"op":"remove","path":"/nickname"},
+		{"op":"replace","path":"/address/city","value":"Bergen"},
+		{"op":"add","path":"/tags/1","value":"x"},
+		{"op":"add","path":"/tags/-","value":"z"},
+		{"op":"copy","from":"/address/country","path":"/labels/country"},
+		{"op":"move","from":"/labels/tier","path":"/labels/level"}
+	]`)
+
+	err := cfg.ParseJSONPatch(httptest.NewRecorder(), req, &account)
+
+	assert.NoError(t, err)
+	assert.Equal(t, PatchAccount{
+		Name:    "John",
+		Address: PatchAddress{City: "Bergen", Country: "NO"},
+		Labels:  map[string]string{"team": "core", "level": "gold", "country": "NO"},
+		Tags:    []string{"a", "x", "b", "z"},
+		Secret:  "keep",
+	}, account)
+	assert.Equal(t, map[string]bool{"name": true, "nickname": true, "address": true, "tags": true, "labels": true}, cfg.Result.Present)
+}
+
+func TestParseJSONPatchMalformed(t *testing.T) {
+	cfg := setupParser()
+	account := loadedAccount()
+	w := httptest.NewRecorder()
+	req := jsonPatchRequest(`[
+		{"op":"replace","path":"/name","value":"Jane"},
+		{"op":"frobnicate","path":"/name"},
+		{"op":"add","path":"name","value":1},
+		{"op":"copy","path":"/name"},
+		{"op":"move","from":"/address","path":"/address/city"}
+	]`)
+
+	err := cfg.ParseJSONPatch(w, req, &account)
+
+	var errs formparser.JSONPatchErrors
+	assert.ErrorAs(t, err, &errs)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []int{1, 2, 3, 4}, []int{errs[0].Index, errs[1].Index, errs[2].Index, errs[3].Index})
+	assert.Equal(t, "John", account.Name)
+}
+
+func TestParseJSONPatchUnprocessable(t *testing.T) {
+	for body, index := range map[string]int{
+		`[{"op":"replace","path":"/name","value":"Jane"},{"op":"remove","path":"/missing"}]`:         1,
+		`[{"op":"test","path":"/name","value":"Jane"}]`:                                              0,
+		`[{"op":"add","path":"/tags/9","value":"x"}]`:                                                0,
+		`[{"op":"replace","path":"/name","value":"Jane"},{"op":"replace","path":"/tags","value":7}]`: 1,
+	} {
+		cfg := setupParser()
+		account := loadedAccount()
+		w := httptest.NewRecorder()
+
+		err := cfg.ParseJSONPatch(w, jsonPatchRequest(body), &account)
+
+		assert.Error(t, err, body)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, body)
+		var resp struct {
+			Operations []formparser.JSONPatchError `json:"operations"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		if assert.Len(t, resp.Operations, 1, body) {
+			assert.Equal(t, index, resp.Operations[0].Index, body)
+		}
+		assert.Equal(t, loadedAccount(), account, body)
+	}
+}
+
+func TestParseJSONPatchValidatesResult(t *testing.T) {
+	cfg := setupParser()
+	account := loadedAccount()
+	w := httptest.NewRecorder()
+
+	err := cfg.ParseJSONPatch(w, jsonPatchRequest(`[{"op":"replace","path":"/name","value":""}]`), &account)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Name is required")
+}
+
+func TestParseJSONPatchMisfitDetail(t *testing.T) {
+	for _, mode := range []formparser.Mode{formparser.ModeRelease, formparser.ModeDebug} {
+		t.Run(mode.String(), func(t *testing.T) {
+			cfg := setupParser()
+			cfg.Mode = mode
+			account := loadedAccount()
+			w := httptest.NewRecorder()
+
+			err := cfg.ParseJSONPatch(w, jsonPatchRequest(`[{"op":"replace","path":"/tags","value":7}]`), &account)
+
+			assert.ErrorContains(t, err, "[]string")
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			assert.Contains(t, w.Body.String(), "value does not fit the target")
+			assert.Equal(t, mode == formparser.ModeDebug, strings.Contains(w.Body.String(), "[]string"), w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyFuncs(t *testing.T) {
+	file := &formparser.UploadedFile{Filename: "Photo.PNG", Hash: "abcdef0123"}
+
+	assert.Equal(t, "avatar/abcdef0123/Photo.PNG", formparser.DefaultKey("avatar", file))
+	assert.Equal(t, "ab/cd/abcdef0123.png", formparser.ContentAddressedKey("avatar", file))
+	assert.Equal(t, "avatar/"+time.Now().UTC().Format("2006/01/02")+"/abcdef0123.png", formparser.DateKey("avatar", file))
+	assert.Regexp(t, regexp.MustCompile(`^avatar/[0-9A-HJKMNP-TV-Z]{26}\.png$`), formparser.ULIDKey("avatar", file))
+}
+
+func TestDiskStoreWithKeyFunc(t *testing.T) {
+	root := t.TempDir()
+	cfg := setupParser()
+	cfg.FileStore = formparser.DiskStore{Root: root}
+	cfg.KeyFunc = formparser.ContentAddressedKey
+
+	content := []byte("PNG IMAGE CONTENT")
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "avatar", Filename: "avatar.png", ContentType: "image/png", Content: content})
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	assert.NoError(t, err)
+
+	file := cfg.Files["avatar"]
+	assert.Equal(t, formparser.ContentAddressedKey("avatar", file), file.StorageKey)
+
+	saved, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file.StorageKey)))
+	assert.NoError(t, err)
+	assert.Equal(t, content, saved)
+}
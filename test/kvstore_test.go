@@ -0,0 +1,99 @@
+package test
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	kv := formparser.NewMemoryStore(4)
+	defer kv.Close()
+	kv.Clock = func() time.Time { return now }
+
+	assert.NoError(t, kv.Set(ctx, "short", []byte("a"), time.Minute))
+	assert.NoError(t, kv.Set(ctx, "forever", []byte("b"), 0))
+
+	value, found, err := kv.Get(ctx, "short")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("a"), value)
+
+	value[0] = 'z'
+	value, _, _ = kv.Get(ctx, "short")
+	assert.Equal(t, []byte("a"), value)
+
+	now = now.Add(time.Minute)
+	_, found, _ = kv.Get(ctx, "short")
+	assert.False(t, found)
+	_, found, _ = kv.Get(ctx, "forever")
+	assert.True(t, found)
+
+	assert.NoError(t, kv.Delete(ctx, "forever"))
+	_, found, _ = kv.Get(ctx, "forever")
+	assert.False(t, found)
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	kv := formparser.NewMemoryStore(0)
+	defer kv.Close()
+	kv.Clock = func() time.Time { return now }
+
+	for i := 0; i < 100; i++ {
+		_ = kv.Set(ctx, fmt.Sprintf("key-%d", i), []byte("v"), time.Duration(i%2+1)*time.Second)
+	}
+	assert.Equal(t, 100, kv.Len())
+
+	now = now.Add(time.Second)
+	kv.Sweep()
+	assert.Equal(t, 50, kv.Len())
+}
+
+func TestNewDedupStore(t *testing.T) {
+	content := []byte("PNG IMAGE CONTENT")
+	hash := fmt.Sprintf("%x", sha256.Sum256(content))
+	kv := formparser.NewMemoryStore(0)
+	defer kv.Close()
+	dedup := formparser.NewDedupStore(kv, time.Hour)
+	store := &memoryStore{files: map[string]*formparser.UploadedFile{}}
+
+	upload := func() *formparser.Config {
+		cfg := setupParser()
+		cfg.DedupStore = dedup
+		cfg.FileStore = store
+		req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+			testFile{Field: "avatar", Filename: "avatar.png", ContentType: "image/png", Content: content, Header: map[string]string{"X-Content-SHA256": hash}})
+		var form TestForm
+		assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+		return cfg
+	}
+
+	assert.False(t, upload().Files["avatar"].Existing)
+	file := upload().Files["avatar"]
+	assert.True(t, file.Existing)
+	assert.Equal(t, int64(len(content)), file.Ref().Size)
+	assert.Len(t, store.files, 1)
+}
+
+func TestMemoryStoreZeroValue(t *testing.T) {
+	ctx := context.Background()
+	var kv formparser.MemoryStore
+	defer kv.Close()
+
+	assert.NoError(t, kv.Set(ctx, "key", []byte("value"), 0))
+	value, found, err := kv.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("value"), value)
+	assert.Equal(t, 1, kv.Len())
+}
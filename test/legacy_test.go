@@ -0,0 +1,50 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLegacyFormMultipart(t *testing.T) {
+	cfg := setupParser()
+	req := newMultipartRequest(t, map[string]string{"name": "John", "email": "john@example.com"},
+		testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png", Content: []byte("PNG")})
+	req.URL.RawQuery = "name=Query&page=2"
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TestForm{}))
+
+	form := formparser.NewLegacyForm(req, cfg.Result)
+	assert.Equal(t, "John", form.FormValue("name"))
+	assert.Equal(t, "2", form.FormValue("page"))
+	assert.Equal(t, "", form.PostFormValue("page"))
+	assert.Equal(t, "", form.FormValue("avatar"))
+	assert.Equal(t, []string{"John", "Query"}, form.Form()["name"])
+
+	file, header, err := form.FormFile("avatar")
+	if assert.NoError(t, err) {
+		content, _ := io.ReadAll(file)
+		assert.Equal(t, []byte("PNG"), content)
+		assert.NoError(t, file.Close())
+		assert.Equal(t, "a.png", header.Filename)
+		assert.Equal(t, int64(3), header.Size)
+		assert.Equal(t, "image/png", header.Header.Get("Content-Type"))
+	}
+	_, _, err = form.FormFile("missing")
+	assert.ErrorIs(t, err, http.ErrMissingFile)
+}
+
+func TestLegacyFormURLEncoded(t *testing.T) {
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=John&email=john@example.com"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TestForm{}))
+
+	form := formparser.NewLegacyForm(req, cfg.Result)
+	assert.Equal(t, "john@example.com", form.PostFormValue("email"))
+	assert.Equal(t, "", formparser.NewLegacyForm(req, nil).FormValue("email"))
+}
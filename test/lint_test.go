@@ -0,0 +1,18 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintExamples(t *testing.T) {
+	failures, err := formtest.LintExamples[TestForm](setupParser(), "testdata/examples")
+
+	assert.NoError(t, err)
+	assert.Len(t, failures, 1)
+	assert.Equal(t, filepath.Join("testdata", "examples", "bad-email.json"), failures[0].File)
+	assert.Equal(t, map[string]string{"email": "Invalid email address"}, failures[0].Fields)
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubProber struct {
+	info *formparser.MediaInfo
+}
+
+func (p stubProber) Probe(ctx context.Context, file *formparser.UploadedFile) (*formparser.MediaInfo, error) {
+	return p.info, nil
+}
+
+func TestMediaRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    *formparser.MediaInfo
+		wantErr bool
+	}{
+		{"accepted", &formparser.MediaInfo{Duration: 30 * time.Second, VideoCodec: "h264", AudioCodec: "aac"}, false},
+		{"too long", &formparser.MediaInfo{Duration: 2 * time.Minute, VideoCodec: "h264"}, true},
+		{"bad codec", &formparser.MediaInfo{Duration: time.Second, VideoCodec: "vp9"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := setupParser()
+			cfg.AllowedMIMETypes = []string{"video/mp4"}
+			cfg.MediaProber = stubProber{info: tt.info}
+			cfg.MediaRules = map[string]formparser.MediaRule{
+				"clip": {MaxDuration: time.Minute, AllowedCodecs: []string{"h264", "aac"}},
+			}
+
+			req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+				testFile{Field: "clip", Filename: "clip.mp4", ContentType: "video/mp4", Content: []byte("MP4")})
+			w := httptest.NewRecorder()
+
+			var form TestForm
+			err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+			if tt.wantErr {
+				assert.IsType(t, formparser.FieldErrors{}, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.info, cfg.Files["clip"].Media)
+		})
+	}
+}
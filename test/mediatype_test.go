@@ -0,0 +1,46 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMediaTypeParsing(t *testing.T) {
+	jsonBody := `{"name":"John","email":"john@example.com"}`
+	tests := []struct {
+		contentType string
+		body        string
+		mediaType   string
+		params      map[string]string
+	}{
+		{"Application/JSON; charset=UTF-8", jsonBody, "application/json", map[string]string{"charset": "UTF-8"}},
+		{"  application/json  ", jsonBody, "application/json", map[string]string{}},
+		{"application/json;CHARSET=utf-8", jsonBody, "application/json", map[string]string{"charset": "utf-8"}},
+		{"APPLICATION/X-WWW-FORM-URLENCODED", "name=John&email=john@example.com", "application/x-www-form-urlencoded", map[string]string{}},
+		{"application/json; charset", jsonBody, "application/json", map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			cfg := setupParser()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			var form TestForm
+			assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+			assert.Equal(t, "John", form.Name)
+			assert.Equal(t, tt.mediaType, cfg.Result.MediaType)
+			assert.Equal(t, tt.params, cfg.Result.MediaParams)
+		})
+	}
+}
+
+func TestMediaTypeExactMatch(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/jsonx")
+	rec := httptest.NewRecorder()
+	assert.Error(t, setupParser().ParseFormBasedOnContentType(rec, req, &TestForm{}))
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
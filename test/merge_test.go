@@ -0,0 +1,140 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type ProfileForm struct {
+	Name  string   `form:"name" json:"name" validate:"required"`
+	Email string   `form:"email" json:"email" validate:"required,email"`
+	Tags  []string `form:"tags" json:"tags"`
+}
+
+func loadedProfile() ProfileForm {
+	return ProfileForm{Name: "John", Email: "john@example.com", Tags: []string{"a", "b"}}
+}
+
+func TestMergeURLEncoded(t *testing.T) {
+	cfg := setupParser()
+	cfg.Merge = true
+	body := url.Values{"email": {"new@example.com"}, "tags": {"c"}}.Encode()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	form := loadedProfile()
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ProfileForm{Name: "John", Email: "new@example.com", Tags: []string{"c"}}, form)
+	assert.Equal(t, map[string]bool{"email": true, "tags": true}, cfg.Result.Present)
+}
+
+func TestMergeJSON(t *testing.T) {
+	cfg := setupParser()
+	cfg.Merge = true
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"name":"Jane"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	form := loadedProfile()
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ProfileForm{Name: "Jane", Email: "john@example.com", Tags: []string{"a", "b"}}, form)
+	assert.Equal(t, map[string]bool{"name": true}, cfg.Result.Present)
+}
+
+func TestMergeValidatesMergedStruct(t *testing.T) {
+	cfg := setupParser()
+	cfg.Merge = true
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"email":"not-an-email"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	form := loadedProfile()
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "Invalid email address")
+	assert.NotContains(t, w.Body.String(), "Name is required")
+}
+
+func TestPresentNotTrackedWithoutMerge(t *testing.T) {
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"Jane","email":"jane@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	var form ProfileForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(w, req, &form))
+	assert.Nil(t, cfg.Result.Present)
+}
+
+func TestMergeChanges(t *testing.T) {
+	cfg := setupParser()
+	cfg.Merge = true
+	body := url.Values{"name": {"John"}, "email": {"new@example.com"}, "tags": {"a", "c"}}.Encode()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	form := loadedProfile()
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(w, req, &form))
+	assert.Equal(t, []formparser.FieldChange{
+		{Field: "email", Old: "john@example.com", New: "new@example.com"},
+		{Field: "tags", Old: []string{"a", "b"}, New: []string{"a", "c"}},
+	}, cfg.Result.Changes)
+}
+
+func TestMergeNoChanges(t *testing.T) {
+	cfg := setupParser()
+	cfg.Merge = true
+	// JSON decoding reuses the loaded slice, which must not alter the snapshot.
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"name":"John","tags":["x","y"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	form := loadedProfile()
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(w, req, &form))
+	assert.Equal(t, []formparser.FieldChange{
+		{Field: "tags", Old: []string{"a", "b"}, New: []string{"x", "y"}},
+	}, cfg.Result.Changes)
+
+	req = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"name":"John"}`))
+	req.Header.Set("Content-Type", "application/json")
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+	assert.NotNil(t, cfg.Result.Changes)
+	assert.Empty(t, cfg.Result.Changes)
+}
+
+type MergeNode struct {
+	Label string
+	Next  *MergeNode
+}
+
+type MergeGraphForm struct {
+	Name string     `form:"name" json:"name" validate:"required"`
+	Node *MergeNode `form:"-" json:"-" validate:"-"`
+}
+
+func TestMergeCyclicField(t *testing.T) {
+	cfg := setupParser()
+	cfg.Merge = true
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"Jane"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	node := &MergeNode{Label: "a"}
+	node.Next = node
+	form := MergeGraphForm{Name: "John", Node: node}
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+	assert.Equal(t, "Jane", form.Name)
+	assert.Same(t, node, form.Node)
+}
@@ -0,0 +1,116 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type PatchAddress struct {
+	City    string `json:"city"`
+	Country string `json:"country"`
+}
+
+type PatchAccount struct {
+	Name     string            `json:"name" validate:"required"`
+	Nickname *string           `json:"nickname,omitempty"`
+	Address  PatchAddress      `json:"address"`
+	Labels   map[string]string `json:"labels"`
+	Tags     []string          `json:"tags"`
+	Secret   string            `json:"-"`
+}
+
+func mergePatchRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	return req
+}
+
+func loadedAccount() PatchAccount {
+	nick := "Johnny"
+	return PatchAccount{
+		Name:     "John",
+		Nickname: &nick,
+		Address:  PatchAddress{City: "Oslo", Country: "NO"},
+		Labels:   map[string]string{"team": "core", "tier": "gold"},
+		Tags:     []string{"a", "b"},
+		Secret:   "keep",
+	}
+}
+
+func TestParseMergePatch(t *testing.T) {
+	cfg := setupParser()
+	account := loadedAccount()
+	req := mergePatchRequest(`{"nickname":null,"address":{"city":"Bergen"},"labels":{"tier":null,"region":"eu"},"tags":["c"],"secret":"leak"}`)
+
+	err := cfg.ParseMergePatch(httptest.NewRecorder(), req, &account)
+
+	assert.NoError(t, err)
+	assert.Equal(t, PatchAccount{
+		Name:    "John",
+		Address: PatchAddress{City: "Bergen", Country: "NO"},
+		Labels:  map[string]string{"team": "core", "region": "eu"},
+		Tags:    []string{"c"},
+		Secret:  "keep",
+	}, account)
+	assert.Equal(t, map[string]bool{"nickname": true, "address": true, "labels": true, "tags": true}, cfg.Result.Present)
+	assert.Len(t, cfg.Result.Changes, 4)
+	assert.Equal(t, "nickname", cfg.Result.Changes[0].Field)
+}
+
+func TestParseMergePatchValidatesResult(t *testing.T) {
+	cfg := setupParser()
+	account := loadedAccount()
+	w := httptest.NewRecorder()
+
+	err := cfg.ParseMergePatch(w, mergePatchRequest(`{"name":null}`), &account)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Name is required")
+}
+
+func TestParseMergePatchRejects(t *testing.T) {
+	cfg := setupParser()
+	for body, msg := range map[string]string{
+		`["name"]`:       "Merge patch must be a JSON object",
+		`{"name":`:       "Invalid merge patch",
+		`{"address":12}`: "Invalid merge patch",
+	} {
+		account := loadedAccount()
+		w := httptest.NewRecorder()
+		err := cfg.ParseMergePatch(w, mergePatchRequest(body), &account)
+
+		assert.Error(t, err, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Contains(t, w.Body.String(), msg, body)
+	}
+
+	req := mergePatchRequest(`{}`)
+	req.Header.Set("Content-Type", "application/xml")
+	w := httptest.NewRecorder()
+	assert.Error(t, cfg.ParseMergePatch(w, req, &PatchAccount{}))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, int64(1), cfg.Stats().Failures["unsupported_type"])
+}
+
+func TestParseMergePatchNeedsStructPointer(t *testing.T) {
+	cfg := setupParser()
+	w := httptest.NewRecorder()
+	assert.Error(t, cfg.ParseMergePatch(w, mergePatchRequest(`{}`), PatchAccount{}))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestParseMergePatchDebug(t *testing.T) {
+	cfg := setupParser()
+	cfg.Mode = formparser.ModeDebug
+	w := httptest.NewRecorder()
+
+	assert.Error(t, cfg.ParseMergePatch(w, mergePatchRequest(`{"name":`), &PatchAccount{}))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Invalid merge patch: unexpected EOF\n", w.Body.String())
+}
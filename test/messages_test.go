@@ -0,0 +1,49 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageCatalog(t *testing.T) {
+	entries := map[formparser.MessageKey]string{
+		{Field: "email", Tag: "email", Lang: "fr"}: "Adresse e-mail invalide",
+	}
+	catalog := formparser.NewMessageCatalog(func(ctx context.Context) (map[formparser.MessageKey]string, error) {
+		return entries, nil
+	})
+	assert.NoError(t, catalog.Reload(context.Background()))
+
+	cfg := setupParser()
+	cfg.Messages = catalog
+
+	parse := func() validationResponse {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","email":"nope"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", "fr-CH, fr;q=0.9, en;q=0.8")
+		w := httptest.NewRecorder()
+		var form TestForm
+		_ = cfg.ParseFormBasedOnContentType(w, req, &form)
+
+		var response validationResponse
+		_ = json.NewDecoder(w.Body).Decode(&response)
+		return response
+	}
+
+	assert.Equal(t, "Adresse e-mail invalide", parse().Fields["email"])
+
+	entries = map[formparser.MessageKey]string{{Field: "email"}: "Updated message"}
+	assert.NoError(t, catalog.Reload(context.Background()))
+	assert.Equal(t, "Updated message", parse().Fields["email"])
+
+	entries = map[formparser.MessageKey]string{}
+	assert.NoError(t, catalog.Reload(context.Background()))
+	assert.Equal(t, "Invalid email address", parse().Fields["email"])
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type AvatarForm struct {
+	Avatar string `form:"avatar" validate:"required"`
+}
+
+var pngBytes = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func parseAvatar(t *testing.T, policy formparser.MIMEPolicy, file testFile) (*formparser.Config, *httptest.ResponseRecorder, error) {
+	cfg := setupParser()
+	cfg.AllowedMIMETypes = []string{"image/png", "image/jpeg"}
+	cfg.MIMEPolicies = map[string]formparser.MIMEPolicy{"avatar": policy}
+	req := newMultipartRequest(t, nil, file)
+	w := httptest.NewRecorder()
+	var form AvatarForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	return cfg, w, err
+}
+
+func TestMIMEPolicyTrustDeclared(t *testing.T) {
+	cfg, _, err := parseAvatar(t, formparser.MIMETrustDeclared,
+		testFile{Field: "avatar", Filename: "a.jpg", ContentType: "image/jpeg", Content: pngBytes})
+	assert.NoError(t, err)
+	assert.Equal(t, "image/jpeg", cfg.Files["avatar"].ContentType)
+}
+
+func TestMIMEPolicyTrustSniffed(t *testing.T) {
+	cfg, _, err := parseAvatar(t, formparser.MIMETrustSniffed,
+		testFile{Field: "avatar", Filename: "a.jpg", ContentType: "image/jpeg", Content: pngBytes})
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", cfg.Files["avatar"].ContentType)
+	assert.Equal(t, "a.jpg", cfg.Files["avatar"].Filename)
+}
+
+func TestMIMEPolicyRename(t *testing.T) {
+	cfg, _, err := parseAvatar(t, formparser.MIMERename,
+		testFile{Field: "avatar", Filename: "photo.final.jpg", ContentType: "image/jpeg", Content: pngBytes})
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", cfg.Files["avatar"].ContentType)
+	assert.Equal(t, "photo.final.png", cfg.Files["avatar"].Filename)
+}
+
+func TestMIMEPolicyReject(t *testing.T) {
+	// The declared type matches the content but not the extension.
+	cfg, w, err := parseAvatar(t, formparser.MIMEReject,
+		testFile{Field: "avatar", Filename: "a.gif", ContentType: "image/png", Content: pngBytes})
+	assert.Error(t, err)
+	assert.Nil(t, cfg.Files["avatar"])
+	assert.Contains(t, w.Body.String(), "avatar content does not match its file type")
+}
+
+func TestMIMEPolicySniffedNotAllowed(t *testing.T) {
+	_, w, err := parseAvatar(t, formparser.MIMETrustSniffed,
+		testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png", Content: []byte("%PDF-1.4 fake")})
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "avatar has an unsupported file type")
+}
+
+func TestMIMEPolicyAgreeingTypesPass(t *testing.T) {
+	cfg, _, err := parseAvatar(t, formparser.MIMEReject,
+		testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png", Content: pngBytes})
+	assert.NoError(t, err)
+	assert.Equal(t, "a.png", cfg.Files["avatar"].Filename)
+}
+
+func TestMIMEPolicyAliasesAgree(t *testing.T) {
+	cfg := setupParser()
+	cfg.AllowedMIMETypes = []string{"image/jpg"}
+	cfg.MIMEPolicies = map[string]formparser.MIMEPolicy{"avatar": formparser.MIMEReject}
+	req := newMultipartRequest(t, nil, testFile{Field: "avatar", Filename: "a.jpeg",
+		ContentType: "image/jpg; name=a.jpeg", Content: []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")})
+	var form AvatarForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+}
+
+func TestMIMEPolicyPlainTextSniffIsInconclusive(t *testing.T) {
+	cfg := setupParser()
+	cfg.AllowedMIMETypes = []string{"text/csv"}
+	cfg.MIMEPolicies = map[string]formparser.MIMEPolicy{"avatar": formparser.MIMEReject}
+	req := newMultipartRequest(t, nil,
+		testFile{Field: "avatar", Filename: "rows.csv", ContentType: "text/csv", Content: []byte("a,b\n1,2\n")})
+	var form AvatarForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+}
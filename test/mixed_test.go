@@ -0,0 +1,96 @@
+package test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mixedPart struct {
+	ContentType string
+	ContentID   string
+	Content     string
+}
+
+func newMixedRequest(t *testing.T, mediaType, params string, parts ...mixedPart) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, p := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", p.ContentType)
+		if p.ContentID != "" {
+			header.Set("Content-ID", "<"+p.ContentID+">")
+		}
+		pw, err := writer.CreatePart(header)
+		assert.NoError(t, err)
+		_, _ = pw.Write([]byte(p.Content))
+	}
+	assert.NoError(t, writer.Close())
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", mediaType+"; boundary="+writer.Boundary()+params)
+	return req
+}
+
+func TestParseMultipartMixed(t *testing.T) {
+	cfg := setupParser()
+	req := newMixedRequest(t, "multipart/mixed", "",
+		mixedPart{ContentType: "application/json", Content: `{"name":"John","email":"john@example.com"}`},
+		mixedPart{ContentType: "image/png", ContentID: "avatar", Content: "PNG"})
+
+	var form TestForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+	assert.Equal(t, "John", form.Name)
+	if assert.Len(t, cfg.Result.Parts, 2) {
+		assert.True(t, cfg.Result.Parts[0].Root)
+		assert.Equal(t, "avatar", cfg.Result.Parts[1].ContentID)
+		assert.Equal(t, "image/png", cfg.Result.Parts[1].Header.Get("Content-Type"))
+		assert.Equal(t, []byte("PNG"), cfg.Result.Parts[1].Content)
+	}
+}
+
+func TestParseMultipartRelatedStart(t *testing.T) {
+	cfg := setupParser()
+	req := newMixedRequest(t, "multipart/related", `; type="application/json"; start="<meta>"`,
+		mixedPart{ContentType: "image/png", ContentID: "avatar", Content: "PNG"},
+		mixedPart{ContentType: "application/json", ContentID: "meta", Content: `{"name":"John","email":"john@example.com"}`})
+
+	var form TestForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+	assert.Equal(t, "john@example.com", form.Email)
+	assert.False(t, cfg.Result.Parts[0].Root)
+	assert.True(t, cfg.Result.Parts[1].Root)
+}
+
+func TestParseMultipartMixedInvalid(t *testing.T) {
+	cfg := setupParser()
+	meta := mixedPart{ContentType: "application/json", Content: `{"name":"John","email":"john@example.com"}`}
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"disallowed attachment", newMixedRequest(t, "multipart/mixed", "", meta, mixedPart{ContentType: "application/zip", Content: "PK"}), http.StatusBadRequest},
+		{"missing start", newMixedRequest(t, "multipart/related", `; start="<other>"`, meta), http.StatusBadRequest},
+		{"invalid metadata", newMixedRequest(t, "multipart/mixed", "", mixedPart{ContentType: "application/json", Content: `{"email":"nope"}`}), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			assert.Error(t, cfg.ParseFormBasedOnContentType(rec, tt.req, &TestForm{}))
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(nil))
+	req.Header.Set("Content-Type", "multipart/mixed")
+	rec := httptest.NewRecorder()
+	assert.Error(t, cfg.ParseFormBasedOnContentType(rec, req, &TestForm{}))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeDecodeErrors(t *testing.T) {
+	for _, mode := range []formparser.Mode{formparser.ModeRelease, formparser.ModeDebug} {
+		t.Run(mode.String(), func(t *testing.T) {
+			cfg := setupParser()
+			cfg.Mode = mode
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": x}`))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			err := cfg.ParseFormBasedOnContentType(rec, req, &TestForm{})
+			assert.ErrorContains(t, err, "invalid character 'x'")
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			if mode == formparser.ModeDebug {
+				assert.Equal(t, "Invalid JSON body: invalid character 'x' looking for beginning of value (offset 10)\n", rec.Body.String())
+			} else {
+				assert.Equal(t, "Invalid JSON body\n", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestModeValidationDebug(t *testing.T) {
+	cfg := setupParser()
+	cfg.Mode = formparser.ModeDebug
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","email":"nope"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	assert.Error(t, cfg.ParseFormBasedOnContentType(rec, req, &TestForm{}))
+
+	var body struct {
+		Fields map[string]string                `json:"fields"`
+		Debug  map[string]formparser.FieldDebug `json:"debug"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Contains(t, body.Fields, "email")
+	assert.Equal(t, formparser.FieldDebug{Namespace: "TestForm.Email", Rule: "email"}, body.Debug["email"])
+
+	cfg.Mode = formparser.ModeRelease
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","email":"nope"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	assert.Error(t, cfg.ParseFormBasedOnContentType(rec, req, &TestForm{}))
+	assert.NotContains(t, rec.Body.String(), "debug")
+	assert.Equal(t, "release", cfg.Effective().Mode)
+}
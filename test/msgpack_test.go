@@ -0,0 +1,64 @@
+package test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type MsgpackForm struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `msgpack:"mail" json:"email" validate:"required,email"`
+}
+
+func msgpackRequest(t *testing.T, contentType string, payload any) *http.Request {
+	t.Helper()
+	body, err := msgpack.Marshal(payload)
+	assert.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
+
+func TestParseMsgpack(t *testing.T) {
+	for _, contentType := range []string{"application/msgpack", "application/x-msgpack"} {
+		cfg := setupParser()
+		req := msgpackRequest(t, contentType, map[string]any{"name": "John", "mail": "john@example.com"})
+
+		var form MsgpackForm
+		err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form)
+
+		assert.NoError(t, err)
+		assert.Equal(t, MsgpackForm{Name: "John", Email: "john@example.com"}, form)
+	}
+}
+
+func TestParseMsgpackValidation(t *testing.T) {
+	cfg := setupParser()
+	req := msgpackRequest(t, "application/msgpack", map[string]any{"mail": "bad"})
+	w := httptest.NewRecorder()
+
+	var form MsgpackForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "Name is required")
+	assert.Contains(t, w.Body.String(), "Invalid email address")
+}
+
+func TestParseMsgpackInvalid(t *testing.T) {
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte{0xc1}))
+	req.Header.Set("Content-Type", "application/msgpack")
+	w := httptest.NewRecorder()
+
+	var form MsgpackForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "Invalid MessagePack body")
+}
@@ -0,0 +1,40 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type PhotoAlbumForm struct {
+	Title  string                     `form:"title" validate:"required"`
+	Cover  *formparser.UploadedFile   `form:"cover"`
+	Photos []*formparser.UploadedFile `form:"photos" validate:"min=2"`
+}
+
+func TestMultipleFilesPerField(t *testing.T) {
+	cfg := setupParser()
+	png := func(field, name string) testFile {
+		return testFile{Field: field, Filename: name, ContentType: "image/png", Content: []byte(name)}
+	}
+	req := newMultipartRequest(t, map[string]string{"title": "Trip"},
+		png("cover", "cover.png"), png("photos", "a.png"), png("photos", "b.png"), png("photos", "c.png"))
+	var form PhotoAlbumForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+
+	if assert.Len(t, form.Photos, 3) {
+		assert.Equal(t, "a.png", form.Photos[0].Filename)
+		assert.Equal(t, "c.png", form.Photos[2].Filename)
+	}
+	if assert.NotNil(t, form.Cover) {
+		assert.Equal(t, "cover.png", form.Cover.Filename)
+	}
+	assert.Len(t, cfg.Result.AllFiles["photos"], 3)
+	assert.Len(t, cfg.Result.FilesFor("photos"), 3)
+	assert.Equal(t, "c.png", cfg.Files["photos"].Filename) // last file wins
+
+	req = newMultipartRequest(t, map[string]string{"title": "Trip"}, png("photos", "a.png"))
+	assert.Error(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &PhotoAlbumForm{}))
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type ContactRecord struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required,email"`
+}
+
+func ndjsonRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	return req
+}
+
+func TestParseNDJSON(t *testing.T) {
+	cfg := setupParser()
+	body := `{"name":"Alice","email":"alice@example.com"}` + "\n\n" + `{"name":"Bob","email":"bob@example.com"}` + "\n"
+
+	var got []ContactRecord
+	var lines []int
+	err := formparser.ParseNDJSON(cfg, httptest.NewRecorder(), ndjsonRequest(body), func(line int, rec *ContactRecord) error {
+		got = append(got, *rec)
+		lines = append(lines, line)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ContactRecord{{"Alice", "alice@example.com"}, {"Bob", "bob@example.com"}}, got)
+	assert.Equal(t, []int{1, 3}, lines)
+}
+
+func TestParseNDJSONRecordErrors(t *testing.T) {
+	cfg := setupParser()
+	body := strings.Join([]string{
+		`{"name":"Alice","email":"alice@example.com"}`,
+		`{"name":"Bob","email":"not-an-email"}`,
+		`{"name":`,
+		`{"name":"Dup","email":"alice@example.com"}`,
+		`{"name":"Carol","email":"carol@example.com"}`,
+	}, "\n")
+	w := httptest.NewRecorder()
+
+	seen := map[string]bool{}
+	var accepted []string
+	err := formparser.ParseNDJSON(cfg, w, ndjsonRequest(body), func(line int, rec *ContactRecord) error {
+		if seen[rec.Email] {
+			return formparser.FieldErrors{"email": "email already imported"}
+		}
+		seen[rec.Email] = true
+		accepted = append(accepted, rec.Name)
+		return nil
+	})
+
+	var recordErrs formparser.RecordErrors
+	assert.True(t, errors.As(err, &recordErrs))
+	assert.Equal(t, []string{"Alice", "Carol"}, accepted)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp struct {
+		Records formparser.RecordErrors `json:"records"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, formparser.RecordErrors{
+		{Line: 2, Fields: formparser.FieldErrors{"email": "Invalid email address"}},
+		{Line: 3, Message: "Invalid JSON record"},
+		{Line: 4, Fields: formparser.FieldErrors{"email": "email already imported"}},
+	}, resp.Records)
+	assert.Equal(t, resp.Records, recordErrs)
+}
+
+func TestParseNDJSONCallbackFailure(t *testing.T) {
+	cfg := setupParser()
+	w := httptest.NewRecorder()
+
+	err := formparser.ParseNDJSON(cfg, w, ndjsonRequest(`{"name":"Alice","email":"alice@example.com"}`), func(line int, rec *ContactRecord) error {
+		return errors.New("database unavailable")
+	})
+
+	assert.EqualError(t, err, "database unavailable")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestParseNDJSONContentType(t *testing.T) {
+	cfg := setupParser()
+	req := ndjsonRequest("{}")
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	err := formparser.ParseNDJSON(cfg, w, req, func(line int, rec *ContactRecord) error { return nil })
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type NetworkForm struct {
+	Address  netip.Addr       `form:"address" validate:"required,ipv4"`
+	Subnet   netip.Prefix     `form:"subnet"`
+	Endpoint *netip.AddrPort  `form:"endpoint"`
+	MAC      net.HardwareAddr `form:"mac"`
+}
+
+func postNetworkForm(t *testing.T, values url.Values) (*httptest.ResponseRecorder, NetworkForm, error) {
+	t.Helper()
+	cfg := setupParser()
+	formparser.RegisterNetworkValidators(cfg.Validator)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	var form NetworkForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	return w, form, err
+}
+
+func TestNetworkTypes(t *testing.T) {
+	_, form, err := postNetworkForm(t, url.Values{
+		"address":  {"192.168.1.10"},
+		"subnet":   {"10.0.0.0/8"},
+		"endpoint": {"[::1]:8080"},
+		"mac":      {"00:1A:2B:3C:4D:5E"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("192.168.1.10"), form.Address)
+	assert.Equal(t, netip.MustParsePrefix("10.0.0.0/8"), form.Subnet)
+	assert.Equal(t, netip.MustParseAddrPort("[::1]:8080"), *form.Endpoint)
+	assert.Equal(t, "00:1a:2b:3c:4d:5e", form.MAC.String())
+}
+
+func TestNetworkTypeErrors(t *testing.T) {
+	w, _, err := postNetworkForm(t, url.Values{
+		"address":  {"2001:db8::1"},
+		"subnet":   {"10.0.0.0/33"},
+		"endpoint": {"10.0.0.1:99999"},
+		"mac":      {"00:1A:2B"},
+	})
+	assert.Error(t, err)
+
+	var resp validationResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]string{
+		"address":  "address must be a valid IPv4 address",
+		"subnet":   "subnet prefix length must be between 0 and 32",
+		"endpoint": "endpoint port must be between 0 and 65535",
+		"mac":      "mac must be a valid MAC address, e.g. 00:1a:2b:3c:4d:5e",
+	}, resp.Fields)
+}
+
+func TestNetworkTypeMissing(t *testing.T) {
+	w, _, err := postNetworkForm(t, url.Values{"subnet": {"10.0.0.0"}})
+	assert.Error(t, err)
+
+	var resp validationResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "address is required", resp.Fields["address"])
+	assert.Equal(t, "subnet must include a prefix length, e.g. 10.0.0.0/8", resp.Fields["subnet"])
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type PriceForm struct {
+	Price float64 `form:"price" validate:"gt=0"`
+}
+
+func postPrice(cfg *formparser.Config, price, lang string) (*httptest.ResponseRecorder, PriceForm, error) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(url.Values{"price": {price}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept-Language", lang)
+	w := httptest.NewRecorder()
+
+	var form PriceForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	return w, form, err
+}
+
+func TestNumberLocale(t *testing.T) {
+	cfg := setupParser()
+	cfg.NumberLocale = "de"
+
+	_, form, err := postPrice(cfg, "1.234,5", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1234.5, form.Price)
+}
+
+func TestNumberLocaleAuto(t *testing.T) {
+	cfg := setupParser()
+	cfg.NumberLocale = formparser.NumberLocaleAuto
+
+	_, form, err := postPrice(cfg, "12,5", "fr-FR,fr;q=0.9")
+	assert.NoError(t, err)
+	assert.Equal(t, 12.5, form.Price)
+
+	_, form, err = postPrice(cfg, "1,234.5", "en-US")
+	assert.NoError(t, err)
+	assert.Equal(t, 1234.5, form.Price)
+}
+
+func TestNumberLocaleInvalid(t *testing.T) {
+	cfg := setupParser()
+	cfg.NumberLocale = "de"
+
+	w, _, err := postPrice(cfg, "zwölf", "")
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	assert.Contains(t, w.Body.String(), "price must be a number")
+}
+
+func TestNumberLocaleMisplacedGroupSeparator(t *testing.T) {
+	cfg := setupParser()
+	cfg.NumberLocale = "de"
+
+	for _, price := range []string{"1.5", "1,5.000", "12.34,5", "1..000"} {
+		w, _, err := postPrice(cfg, price, "")
+		assert.Error(t, err, price)
+		assert.Contains(t, w.Body.String(), "price must be a number", price)
+	}
+
+	_, form, err := postPrice(cfg, "1 234 567,5", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1234567.5, form.Price)
+}
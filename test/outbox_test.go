@@ -0,0 +1,96 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutbox(t *testing.T) {
+	var saved []*formparser.Submission
+	cfg := setupParser()
+	cfg.Outbox = formparser.OutboxFunc(func(ctx context.Context, sub *formparser.Submission) error {
+		saved = append(saved, sub)
+		return nil
+	})
+
+	req := newMultipartRequest(t, map[string]string{"name": "Ann", "email": "ann@example.com"},
+		testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png", Content: []byte("png")})
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TestForm{}))
+	if assert.Len(t, saved, 1) {
+		sub := saved[0]
+		assert.Equal(t, cfg.Result.SubmissionID, sub.ID)
+		assert.Len(t, sub.ID, 26)
+		assert.Equal(t, "multipart/form-data", sub.MediaType)
+		var data TestForm
+		assert.NoError(t, json.Unmarshal(sub.Data, &data))
+		assert.Equal(t, "ann@example.com", data.Email)
+		if assert.Len(t, sub.Files["avatar"], 1) {
+			assert.Equal(t, "a.png", sub.Files["avatar"][0].Filename)
+		}
+	}
+
+	// Rejected submissions are not saved.
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"Ann"}`))
+	req.Header.Set("Content-Type", "application/json")
+	assert.Error(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TestForm{}))
+	assert.Len(t, saved, 1)
+	assert.Empty(t, cfg.Result.SubmissionID)
+}
+
+func TestOutboxFailure(t *testing.T) {
+	cfg := setupParser()
+	cfg.Outbox = formparser.OutboxFunc(func(ctx context.Context, sub *formparser.Submission) error {
+		return errors.New("database down")
+	})
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"Ann","email":"ann@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	assert.Error(t, cfg.ParseFormBasedOnContentType(rec, req, &TestForm{}))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, cfg.Effective().Hooks, "Outbox")
+}
+
+type OutboxContact struct {
+	Phone string `json:"phone" sensitive:"last4"`
+	Notes string `json:"notes" sensitive:"true"`
+}
+
+type OutboxSignupForm struct {
+	Name     string          `json:"name"`
+	Password string          `json:"password"`
+	Token    string          `json:"token" sensitive:"true"`
+	Card     string          `json:"card" cc:"pan"`
+	Contacts []OutboxContact `json:"contacts"`
+}
+
+func TestOutboxMasksSecrets(t *testing.T) {
+	var saved *formparser.Submission
+	cfg := setupParser()
+	cfg.PasswordPolicies = map[string]formparser.PasswordPolicy{"password": {}}
+	cfg.Outbox = formparser.OutboxFunc(func(ctx context.Context, sub *formparser.Submission) error {
+		saved = sub
+		return nil
+	})
+	payload := `{"name":"Ann","password":"hunter2hunter2","token":"s3cret","card":"4111111111111111",` +
+		`"contacts":[{"phone":"5551234567","notes":"private"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &OutboxSignupForm{}))
+	if assert.NotNil(t, saved) {
+		data := string(saved.Data)
+		assert.Contains(t, data, `"name":"Ann"`)
+		for _, secret := range []string{"hunter2", "s3cret", "41111111", "555123", "private"} {
+			assert.NotContains(t, data, secret)
+		}
+		assert.Contains(t, data, "1111")
+		assert.Contains(t, data, "4567")
+	}
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type SignupForm struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type codedResponse struct {
+	Fields map[string]string   `json:"fields"`
+	Codes  map[string][]string `json:"codes"`
+}
+
+func setupPasswordParser() *formparser.Config {
+	cfg := setupParser()
+	cfg.PasswordPolicies = map[string]formparser.PasswordPolicy{
+		"password": {MinLength: 10, MinEntropy: 50, Personal: []string{"Username"}, Breached: true},
+	}
+	return cfg
+}
+
+func postSignup(t *testing.T, cfg *formparser.Config, username, password string) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+	payload, _ := json.Marshal(map[string]string{"username": username, "password": password})
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(payload)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	var form SignupForm
+	return w, cfg.ParseFormBasedOnContentType(w, req, &form)
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	cfg := setupPasswordParser()
+	_, err := postSignup(t, cfg, "jdoe", "c0rrect-H0rse-battery")
+	assert.NoError(t, err)
+
+	w, err := postSignup(t, cfg, "jdoe", "aaaaaa")
+	assert.Error(t, err)
+	var resp codedResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "password must be at least 10 characters", resp.Fields["password"])
+	assert.Equal(t, []string{formparser.PasswordTooShort, formparser.PasswordLowEntropy}, resp.Codes["password"])
+	assert.Equal(t, resp.Codes, cfg.Result.ErrorCodes)
+
+	w, err = postSignup(t, cfg, "JDoe", "my-jdoe-Passw0rd!")
+	assert.Error(t, err)
+	resp = codedResponse{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{formparser.PasswordPersonalInfo}, resp.Codes["password"])
+}
+
+func TestPasswordCustomMessage(t *testing.T) {
+	cfg := setupPasswordParser()
+	cfg.FieldErrorMessages["password"] = "Please choose a stronger password"
+
+	w, err := postSignup(t, cfg, "jdoe", "abcdefghijk")
+	assert.Error(t, err)
+	var resp codedResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Please choose a stronger password", resp.Fields["password"])
+	assert.Equal(t, []string{formparser.PasswordLowEntropy}, resp.Codes["password"])
+}
+
+func TestPasswordEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, formparser.PasswordEntropy(""))
+	assert.Less(t, formparser.PasswordEntropy("aaaaaaaaaaaa"), formparser.PasswordEntropy("ax"))
+	assert.Less(t, formparser.PasswordEntropy("abcdefgh"), 10.0)
+	assert.Greater(t, formparser.PasswordEntropy("c0rrect-H0rse-battery"), 100.0)
+}
+
+func TestHIBPClient(t *testing.T) {
+	breached := "password123"
+	sum := fmt.Sprintf("%X", sha1.Sum([]byte(breached)))
+
+	var requested string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+		fmt.Fprintf(w, "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n%s:2413945\r\n00D4F6E8FA6EECAD2A3AA415EEC418D38EC:0\r\n", sum[5:])
+	}))
+	defer srv.Close()
+
+	cfg := setupPasswordParser()
+	cfg.PasswordPolicies["password"] = formparser.PasswordPolicy{Breached: true}
+	cfg.BreachChecker = &formparser.HIBPClient{Client: srv.Client(), BaseURL: srv.URL + "/range/"}
+
+	w, err := postSignup(t, cfg, "jdoe", breached)
+	assert.Error(t, err)
+	assert.Equal(t, "/range/"+sum[:5], requested)
+	assert.Contains(t, w.Body.String(), formparser.PasswordBreached)
+
+	_, err = postSignup(t, cfg, "jdoe", "not-in-the-range")
+	assert.NoError(t, err)
+
+	suffixes, err := cfg.BreachChecker.BreachedSuffixes(context.Background(), sum[:5])
+	assert.NoError(t, err)
+	assert.Len(t, suffixes, 2) // the zero-count padding entry is skipped
+}
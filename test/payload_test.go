@@ -0,0 +1,40 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePayload(t *testing.T) {
+	cfg := setupParser()
+	out, err := formparser.ValidatePayload[TestForm](cfg, "application/json", []byte(`{"name":"John","email":"john@example.com"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, out.Status)
+	assert.Equal(t, "John", out.Value.Name)
+	assert.NotNil(t, out.Result)
+
+	out, err = formparser.ValidatePayload[TestForm](cfg, "application/x-www-form-urlencoded", []byte("name=John&email=john@example.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, "john@example.com", out.Value.Email)
+	assert.Equal(t, int64(1), cfg.Stats().Parses["urlencoded"])
+}
+
+func TestValidatePayloadInvalid(t *testing.T) {
+	cfg := setupParser()
+	out, err := formparser.ValidatePayload[TestForm](cfg, "application/json", []byte(`{"name":"John","email":"nope"}`))
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, out.Status)
+	assert.Equal(t, "John", out.Value.Name)
+
+	var resp validationResponse
+	assert.NoError(t, json.Unmarshal(out.Body, &resp))
+	assert.Contains(t, resp.Fields, "email")
+
+	out, err = formparser.ValidatePayload[TestForm](cfg, "text/html", []byte("<p>"))
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, out.Status)
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePDF builds a minimal PDF-shaped document with the given number of pages.
+func fakePDF(pages int, encrypted bool) []byte {
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n")
+	fmt.Fprintf(&b, "1 0 obj << /Type /Pages /Count %d >> endobj\n", pages)
+	for i := 0; i < pages; i++ {
+		fmt.Fprintf(&b, "%d 0 obj << /Type /Page /Parent 1 0 R >> endobj\n", i+2)
+	}
+	b.WriteString("trailer << /Root 1 0 R")
+	if encrypted {
+		b.WriteString(" /Encrypt 99 0 R")
+	}
+	b.WriteString(" >>\n%%EOF")
+	return []byte(b.String())
+}
+
+func setupPDFParser() *formparser.Config {
+	cfg := setupParser()
+	cfg.AllowedMIMETypes = []string{"application/pdf"}
+	cfg.PDFRules = map[string]formparser.PDFRule{
+		"document": {MaxPages: 3, RejectEncrypted: true},
+	}
+	return cfg
+}
+
+func TestPDFRuleAccepts(t *testing.T) {
+	cfg := setupPDFParser()
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "document", Filename: "doc.pdf", ContentType: "application/pdf", Content: fakePDF(3, false)})
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &formparser.PDFInfo{Pages: 3}, cfg.Files["document"].PDF)
+}
+
+func TestPDFRuleRejects(t *testing.T) {
+	tests := map[string][]byte{
+		"too many pages": fakePDF(4, false),
+		"encrypted":      fakePDF(1, true),
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := setupPDFParser()
+			req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+				testFile{Field: "document", Filename: "doc.pdf", ContentType: "application/pdf", Content: content})
+			w := httptest.NewRecorder()
+
+			var form TestForm
+			err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+			assert.IsType(t, formparser.FieldErrors{}, err)
+			assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+
+			var response validationResponse
+			_ = json.NewDecoder(w.Body).Decode(&response)
+			assert.Contains(t, response.Fields, "document")
+		})
+	}
+}
+
+func TestPDFRuleRejectedFileIsNotStored(t *testing.T) {
+	content := fakePDF(4, false)
+	dedup := &memoryDedup{refs: map[string]formparser.FileRef{}}
+	store := &memoryStore{files: map[string]*formparser.UploadedFile{}}
+	cfg := setupPDFParser()
+	cfg.DedupStore = dedup
+	cfg.FileStore = store
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "document", Filename: "doc.pdf", ContentType: "application/pdf", Content: content})
+
+	assert.Error(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TestForm{}))
+	assert.Empty(t, store.files)
+	assert.Empty(t, dedup.refs)
+	assert.Nil(t, cfg.Files["document"])
+}
+
+func TestPDFRuleIgnoresDeclaredType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		content     []byte
+		ok          bool
+	}{
+		{"octet-stream pdf", "application/octet-stream", fakePDF(2, false), true},
+		{"octet-stream too many pages", "application/octet-stream", fakePDF(4, false), false},
+		{"not a pdf", "text/plain", []byte("just some text"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := setupPDFParser()
+			cfg.AllowedMIMETypes = []string{"application/pdf", "application/octet-stream", "text/plain"}
+			req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+				testFile{Field: "document", Filename: "doc.pdf", ContentType: tt.contentType, Content: tt.content})
+			w := httptest.NewRecorder()
+
+			err := cfg.ParseFormBasedOnContentType(w, req, &TestForm{})
+
+			if tt.ok {
+				if assert.NoError(t, err) {
+					assert.Equal(t, &formparser.PDFInfo{Pages: 2}, cfg.Files["document"].PDF)
+				}
+				return
+			}
+			assert.IsType(t, formparser.FieldErrors{}, err)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "document")
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type WebhookText struct {
+	Message string `body:"text" validate:"required"`
+}
+
+func textRequest(body, contentType string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
+
+func TestParseText(t *testing.T) {
+	cfg := setupParser()
+	var hook WebhookText
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), textRequest("build #42 passed\n", "text/plain; charset=utf-8"), &hook)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "build #42 passed\n", hook.Message)
+}
+
+func TestParseTextCharset(t *testing.T) {
+	cfg := setupParser()
+	var hook WebhookText
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), textRequest("caf\xe9", "text/plain; charset=iso-8859-1"), &hook)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "café", hook.Message)
+
+	rr := httptest.NewRecorder()
+	err = cfg.ParseFormBasedOnContentType(rr, textRequest("caf\xe9", "text/plain"), &hook)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestParseTextTooLarge(t *testing.T) {
+	cfg := setupParser()
+	cfg.MaxTextBodySize = 8
+	rr := httptest.NewRecorder()
+	var hook WebhookText
+	err := cfg.ParseFormBasedOnContentType(rr, textRequest("way more than eight bytes", "text/plain"), &hook)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	assert.Empty(t, hook.Message)
+}
+
+func TestParseTextValidation(t *testing.T) {
+	cfg := setupParser()
+	rr := httptest.NewRecorder()
+	var hook WebhookText
+	err := cfg.ParseFormBasedOnContentType(rr, textRequest("", "text/plain"), &hook)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"fields":{"message":`)
+}
+
+func TestParseTextWithoutField(t *testing.T) {
+	cfg := setupParser()
+	rr := httptest.NewRecorder()
+	err := cfg.ParseFormBasedOnContentType(rr, textRequest("hello", "text/plain"), &TestForm{})
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+}
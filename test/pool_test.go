@@ -0,0 +1,40 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDstPool(t *testing.T) {
+	cfg := setupParser()
+	resets := 0
+	formparser.RegisterDstPool(cfg, func(f *TestForm) {
+		resets++
+		*f = TestForm{}
+	})
+
+	form := formparser.AcquireDst[TestForm](cfg)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","email":"john@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, form))
+	assert.Equal(t, "John", form.Name)
+
+	formparser.ReleaseDst(cfg, form)
+	assert.Equal(t, 1, resets)
+	assert.Equal(t, TestForm{}, *form)
+}
+
+func TestDstPoolUnregistered(t *testing.T) {
+	cfg := setupParser()
+
+	form := formparser.AcquireDst[TestForm](cfg)
+	assert.NotNil(t, form)
+	form.Name = "kept"
+	formparser.ReleaseDst(cfg, form)
+	assert.Equal(t, "kept", form.Name)
+}
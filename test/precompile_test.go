@@ -0,0 +1,30 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type AddressForm struct {
+	Zip string `validate:"required,postcodez"`
+}
+
+type OrderForm struct {
+	Email     string         `validate:"required,email"`
+	Items     []string       `validate:"min=1,dive,required"`
+	Note      string         `validate:"omitempty,max=10|notarule"`
+	Addresses []*AddressForm `validate:"dive"`
+}
+
+func TestPrecompileUnknownTags(t *testing.T) {
+	cfg := setupParser()
+
+	assert.NoError(t, cfg.Precompile(&TestForm{}))
+
+	err := cfg.Precompile(&OrderForm{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Note: notarule")
+	assert.Contains(t, err.Error(), "Addresses.Zip: postcodez")
+	assert.NotContains(t, err.Error(), "Email")
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func protobufRequest(t *testing.T, msg proto.Message) *http.Request {
+	t.Helper()
+	body, err := proto.Marshal(msg)
+	assert.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	return req
+}
+
+func TestParseProtobuf(t *testing.T) {
+	cfg := setupParser()
+	// Stands in for the validate tags a generated struct would carry.
+	cfg.Validator.RegisterStructValidation(func(sl validator.StructLevel) {
+		if value := sl.Current().FieldByName("Value"); value.String() == "" {
+			sl.ReportError(value.Interface(), "Value", "Value", "required", "")
+		}
+	}, &wrapperspb.StringValue{})
+
+	var msg wrapperspb.StringValue
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), protobufRequest(t, wrapperspb.String("hello")), &msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", msg.GetValue())
+
+	w := httptest.NewRecorder()
+	err = cfg.ParseFormBasedOnContentType(w, protobufRequest(t, wrapperspb.String("")), &wrapperspb.StringValue{})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "value is required")
+}
+
+func TestParseProtobufInvalid(t *testing.T) {
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte{0x0a, 0x05, 'h'}))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	w := httptest.NewRecorder()
+
+	err := cfg.ParseFormBasedOnContentType(w, req, &wrapperspb.StringValue{})
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "Invalid protobuf body")
+}
+
+func TestParseProtobufRequiresMessage(t *testing.T) {
+	cfg := setupParser()
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, protobufRequest(t, wrapperspb.String("hello")), &form)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
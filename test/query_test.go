@@ -0,0 +1,77 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type CountingQuery struct {
+	Page  int    `form:"page" validate:"gte=1"`
+	Query string `form:"q" validate:"required"`
+}
+
+func TestParseQuery(t *testing.T) {
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodGet, "/?page=2&q=go", nil)
+	w := httptest.NewRecorder()
+
+	var query CountingQuery
+	err := cfg.ParseQuery(w, req, &query)
+
+	assert.NoError(t, err)
+	assert.Equal(t, CountingQuery{Page: 2, Query: "go"}, query)
+}
+
+func TestParseQueryCache(t *testing.T) {
+	cfg := setupParser()
+	cfg.QueryCacheSize = 1
+
+	var first CountingQuery
+	assert.NoError(t, cfg.ParseQuery(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?page=2&q=go", nil), &first))
+
+	// Replacing the decoder proves the second call is served from the cache.
+	cfg.Decoder = nil
+	var second CountingQuery
+	assert.NoError(t, cfg.ParseQuery(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?page=2&q=go", nil), &second))
+	assert.Equal(t, first, second)
+}
+
+func TestParseQueryValidationError(t *testing.T) {
+	cfg := setupParser()
+	cfg.QueryCacheSize = 8
+	w := httptest.NewRecorder()
+
+	var query CountingQuery
+	err := cfg.ParseQuery(w, httptest.NewRequest(http.MethodGet, "/?page=0", nil), &query)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+}
+
+type OwnedQuery struct {
+	Query  string `form:"q" validate:"required"`
+	UserID string `form:"user_id" ctx:"user_id"`
+}
+
+func TestParseQueryCacheSkipsRequestDependentResults(t *testing.T) {
+	cfg := setupParser()
+	cfg.QueryCacheSize = 8
+	cfg.Enricher = formparser.EnricherFunc(func(ctx context.Context, key string) (any, bool) {
+		v := ctx.Value(ctxKey(key))
+		return v, v != nil
+	})
+	parseAs := func(user string) OwnedQuery {
+		req := httptest.NewRequest(http.MethodGet, "/?q=go", nil)
+		req = req.WithContext(context.WithValue(req.Context(), ctxKey("user_id"), user))
+		var query OwnedQuery
+		assert.NoError(t, cfg.ParseQuery(httptest.NewRecorder(), req, &query))
+		return query
+	}
+	assert.Equal(t, "alice", parseAs("alice").UserID)
+	assert.Equal(t, "bob", parseAs("bob").UserID)
+}
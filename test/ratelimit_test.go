@@ -0,0 +1,49 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitHeaders(t *testing.T) {
+	h := http.Header{}
+	formparser.RateLimit{Limit: 100, Remaining: -3, Reset: 1500 * time.Millisecond}.SetHeaders(h)
+
+	assert.Equal(t, "2", h.Get("Retry-After"))
+	assert.Equal(t, "100", h.Get("RateLimit-Limit"))
+	assert.Equal(t, "0", h.Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", h.Get("RateLimit-Reset"))
+
+	h = http.Header{}
+	formparser.RateLimit{RetryAfter: 30 * time.Second}.SetHeaders(h)
+	assert.Equal(t, "30", h.Get("Retry-After"))
+	assert.Empty(t, h.Get("RateLimit-Limit"))
+	assert.Empty(t, h.Get("RateLimit-Reset"))
+}
+
+func TestBeforeBodyRateLimit(t *testing.T) {
+	cfg := setupParser()
+	cfg.BeforeBody = func(r *http.Request) error {
+		return &formparser.RejectError{
+			Status:    http.StatusTooManyRequests,
+			Message:   "Too many uploads",
+			RateLimit: &formparser.RateLimit{Limit: 10, Remaining: 0, Reset: 42 * time.Second},
+		}
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	assert.Error(t, cfg.ParseFormBasedOnContentType(w, req, &TestForm{}))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "42", w.Header().Get("Retry-After"))
+	assert.Equal(t, "10", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "42", w.Header().Get("RateLimit-Reset"))
+}
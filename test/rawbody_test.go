@@ -0,0 +1,99 @@
+package test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type FirmwareUpload struct {
+	Image []byte `body:"raw" validate:"required"`
+}
+
+type StreamUpload struct {
+	Data io.Reader `body:"raw"`
+}
+
+// signedBlob implements RawBody, rejecting bodies without its magic prefix.
+type signedBlob struct {
+	Payload []byte
+}
+
+func (b *signedBlob) SetRawBody(body *formparser.UploadedFile) error {
+	if !bytes.HasPrefix(body.Content, []byte("SIG")) {
+		return formparser.FieldErrors{"body": "body must be signed"}
+	}
+	b.Payload = body.Content[3:]
+	return nil
+}
+
+func octetRequest(content []byte) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(content))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	return req
+}
+
+func TestParseOctetStreamBytes(t *testing.T) {
+	cfg := setupParser()
+	content := []byte{0xde, 0xad, 0xbe, 0xef}
+	req := octetRequest(content)
+	req.Header.Set("Content-Disposition", `attachment; filename="fw.bin"`)
+
+	var upload FirmwareUpload
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &upload)
+
+	assert.NoError(t, err)
+	assert.Equal(t, content, upload.Image)
+	assert.Equal(t, "fw.bin", cfg.Result.Body.Filename)
+	assert.Equal(t, int64(4), cfg.Result.Body.Size)
+	assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(content)), cfg.Result.Body.Hash)
+}
+
+func TestParseOctetStreamReader(t *testing.T) {
+	cfg := setupParser()
+
+	var upload StreamUpload
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), octetRequest([]byte("hello")), &upload)
+
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(upload.Data)
+	assert.Equal(t, "hello", string(data))
+}
+
+func TestParseOctetStreamRawBody(t *testing.T) {
+	cfg := setupParser()
+
+	var blob signedBlob
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), octetRequest([]byte("SIGdata")), &blob)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), blob.Payload)
+
+	w := httptest.NewRecorder()
+	err = cfg.ParseFormBasedOnContentType(w, octetRequest([]byte("data")), &signedBlob{})
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "body must be signed")
+}
+
+func TestParseOctetStreamLimits(t *testing.T) {
+	cfg := setupParser()
+	cfg.MaxFileSize = 4
+	w := httptest.NewRecorder()
+
+	var upload FirmwareUpload
+	err := cfg.ParseFormBasedOnContentType(w, octetRequest([]byte("12345")), &upload)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = httptest.NewRecorder()
+	var form TestForm
+	err = cfg.ParseFormBasedOnContentType(w, octetRequest([]byte("1")), &form)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
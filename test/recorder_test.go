@@ -0,0 +1,40 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	cfg := setupParser()
+	rec := &formtest.Recorder{Dir: t.TempDir()}
+	handler := rec.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var form TestForm
+		_ = cfg.ParseFormBasedOnContentType(w, r, &form)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"name":"","email":"secret@example.com"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.NoError(t, rec.Err())
+	examples, err := formtest.LoadExamples(rec.Dir)
+	assert.NoError(t, err)
+	assert.Len(t, examples, 1)
+
+	want := formtest.Example{
+		Method:      http.MethodPost,
+		Path:        "/register",
+		ContentType: "application/json",
+		Fields:      []string{"email", "name"},
+		Status:      http.StatusBadRequest,
+		Message:     "Validation failed",
+		ErrorFields: []string{"name"},
+	}
+	assert.Empty(t, formtest.Diff(want, examples[0]))
+}
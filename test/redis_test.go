@@ -0,0 +1,44 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jinn091/go-form-parser/formparser/redisstore"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	kv := redisstore.Store{Client: client, Prefix: "fp:"}
+
+	_, found, err := kv.Get(ctx, "missing")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, kv.Set(ctx, "key", []byte("value"), time.Minute))
+	assert.True(t, mr.Exists("fp:key"))
+	value, found, err := kv.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("value"), value)
+
+	mr.FastForward(time.Minute)
+	_, found, _ = kv.Get(ctx, "key")
+	assert.False(t, found)
+
+	assert.NoError(t, kv.Set(ctx, "key", []byte("value"), 0))
+	assert.NoError(t, kv.Delete(ctx, "key"))
+	_, found, _ = kv.Get(ctx, "key")
+	assert.False(t, found)
+
+	mr.Close()
+	_, _, err = kv.Get(ctx, "key")
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+// parseKeyValueLines decodes "key: value" lines into a TestForm.
+func parseKeyValueLines(body io.Reader, dst interface{}) error {
+	form := dst.(*TestForm)
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			return errors.New("missing colon")
+		}
+		switch strings.TrimSpace(key) {
+		case "name":
+			form.Name = strings.TrimSpace(value)
+		case "email":
+			form.Email = strings.TrimSpace(value)
+		default:
+			return formparser.FieldErrors{strings.TrimSpace(key): "unknown field " + strings.TrimSpace(key)}
+		}
+	}
+	return scanner.Err()
+}
+
+func TestRegisterParser(t *testing.T) {
+	cfg := setupParser()
+	cfg.RegisterParser("Application/X-KV", parseKeyValueLines)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name: John\nemail: john@example.com\n"))
+	req.Header.Set("Content-Type", "application/x-kv; version=1")
+	var form TestForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+	assert.Equal(t, "john@example.com", form.Email)
+	assert.Equal(t, int64(1), cfg.Stats().Parses["custom"])
+	assert.Contains(t, cfg.Debug().ContentTypes, "application/x-kv")
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"decode error", "name John", "Invalid request body\n"},
+		{"validation", "name: John\nemail: nope", `"email"`},
+		{"field error", "name: John\nemail: john@example.com\nage: 3", `"age":"unknown field age"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-kv")
+			rec := httptest.NewRecorder()
+			assert.Error(t, cfg.ParseFormBasedOnContentType(rec, req, &TestForm{}))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.want)
+		})
+	}
+
+	cfg.RegisterParser("application/x-kv", nil)
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name: John"))
+	req.Header.Set("Content-Type", "application/x-kv")
+	rec := httptest.NewRecorder()
+	assert.Error(t, cfg.ParseFormBasedOnContentType(rec, req, &TestForm{}))
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
+
+func TestRegisterParserOverridesBuiltin(t *testing.T) {
+	cfg := setupParser()
+	cfg.RegisterParser("text/plain", parseKeyValueLines)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name: John\nemail: john@example.com"))
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	var form TestForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+	assert.Equal(t, "John", form.Name)
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type BrandForm struct {
+	Name    string `form:"name" validate:"required"`
+	HasLogo bool   `form:"has_logo"`
+	Logo    string `form:"logo" file_required_if:"has_logo true"`
+}
+
+func TestFileRequiredIf(t *testing.T) {
+	pngFile := testFile{Field: "logo", Filename: "logo.png", ContentType: "image/png", Content: []byte("\x89PNG\r\n\x1a\n")}
+	tests := map[string]struct {
+		fields  map[string]string
+		files   []testFile
+		wantErr bool
+	}{
+		"condition off":          {fields: map[string]string{"name": "Acme", "has_logo": "false"}},
+		"condition on with file": {fields: map[string]string{"name": "Acme", "has_logo": "true"}, files: []testFile{pngFile}},
+		"condition on no file":   {fields: map[string]string{"name": "Acme", "has_logo": "true"}, wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := setupParser()
+			w := httptest.NewRecorder()
+
+			var form BrandForm
+			err := cfg.ParseFormBasedOnContentType(w, newMultipartRequest(t, tt.fields, tt.files...), &form)
+
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			var resp validationResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, "logo is required", resp.Fields["logo"])
+		})
+	}
+}
+
+func TestFileRequiredIfCustomMessage(t *testing.T) {
+	cfg := setupParser()
+	cfg.FieldErrorMessages["logo"] = "Please upload your logo"
+	w := httptest.NewRecorder()
+
+	var form BrandForm
+	err := cfg.ParseFormBasedOnContentType(w, newMultipartRequest(t, map[string]string{"name": "Acme", "has_logo": "true"}), &form)
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "Please upload your logo")
+}
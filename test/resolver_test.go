@@ -0,0 +1,55 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+type TenantProductForm struct {
+	SKU string `form:"sku" validate:"required,sku"`
+}
+
+func TestValidatorResolver(t *testing.T) {
+	prefixed := func(prefix string) *validator.Validate {
+		v := validator.New()
+		_ = v.RegisterValidation("sku", func(fl validator.FieldLevel) bool {
+			return strings.HasPrefix(fl.Field().String(), prefix)
+		})
+		return v
+	}
+	tenants := map[string]*validator.Validate{"acme": prefixed("ACME-"), "globex": prefixed("GLX-")}
+
+	cfg := setupParser()
+	cfg.Validator = prefixed("STD-")
+	cfg.ValidatorResolver = func(r *http.Request) *validator.Validate {
+		return tenants[r.Header.Get("X-Tenant")]
+	}
+
+	tests := []struct {
+		tenant, sku string
+		ok          bool
+	}{
+		{"acme", "ACME-1", true},
+		{"acme", "GLX-1", false},
+		{"globex", "GLX-1", true},
+		{"", "STD-1", true}, // falls back to Validator
+		{"", "ACME-1", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("sku="+tt.sku))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Tenant", tt.tenant)
+		err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TenantProductForm{})
+		if tt.ok {
+			assert.NoError(t, err, tt.tenant+" "+tt.sku)
+		} else {
+			assert.Error(t, err, tt.tenant+" "+tt.sku)
+		}
+	}
+	assert.Contains(t, cfg.Effective().Hooks, "ValidatorResolver")
+}
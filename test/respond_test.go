@@ -0,0 +1,83 @@
+package test
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRespondCreated(t *testing.T) {
+	cfg := setupParser()
+	cfg.FileStore = &memoryStore{files: map[string]*formparser.UploadedFile{}}
+	cfg.FileURL = func(key string) string { return "https://cdn.example.com/" + key }
+
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "avatar", Filename: "avatar.png", ContentType: "image/png", Content: []byte("PNG")})
+	var form TestForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+
+	w := httptest.NewRecorder()
+	assert.NoError(t, cfg.RespondCreated(w))
+
+	file := cfg.Files["avatar"]
+	assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+	assert.JSONEq(t, `{"message": "Upload successful", "files": [{
+		"field": "avatar", "filename": "avatar.png", "content_type": "image/png", "size": 3,
+		"hash": "`+file.Hash+`", "storage_key": "`+file.StorageKey+`",
+		"url": "https://cdn.example.com/`+file.StorageKey+`"
+	}]}`, w.Body.String())
+}
+
+func TestErrorResponseGzip(t *testing.T) {
+	var lines []string
+	for i := 0; i < 200; i++ {
+		lines = append(lines, fmt.Sprintf(`{"name":"user%d","email":"not-an-email"}`, i))
+	}
+	body := strings.Join(lines, "\n")
+	post := func(cfg *formparser.Config, acceptEncoding string) *httptest.ResponseRecorder {
+		req := ndjsonRequest(body)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		_ = formparser.ParseNDJSON(cfg, w, req, func(line int, rec *ContactRecord) error { return nil })
+		return w
+	}
+
+	cfg := setupParser()
+	cfg.ErrorGzipThreshold = 1024
+
+	w := post(cfg, "br, gzip;q=0.8")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	gz, err := gzip.NewReader(w.Body)
+	assert.NoError(t, err)
+	var resp struct {
+		Records formparser.RecordErrors `json:"records"`
+	}
+	assert.NoError(t, json.NewDecoder(gz).Decode(&resp))
+	assert.Len(t, resp.Records, 200)
+
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+	for name, acceptEncoding := range map[string]string{"not accepted": "br", "refused": "gzip;q=0", "refused over wildcard": "gzip;q=0, *"} {
+		w = post(cfg, acceptEncoding)
+		assert.Empty(t, w.Header().Get("Content-Encoding"), name)
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"), name)
+	}
+	assert.Equal(t, "gzip", post(cfg, "*;q=0.5").Header().Get("Content-Encoding"))
+
+	// Small error bodies stay uncompressed.
+	req := newMultipartRequest(t, map[string]string{"name": "Alice"})
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	var form TestForm
+	assert.Error(t, cfg.ParseFormBasedOnContentType(w, req, &form))
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Body.String(), "Validation failed")
+}
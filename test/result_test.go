@@ -0,0 +1,42 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeOnly(t *testing.T) {
+	cfg := setupParser()
+	cfg.Validator = nil
+	cfg.DecodeOnly = true
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"","email":"not-an-email"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "not-an-email", form.Email)
+	assert.True(t, cfg.Result.ValidationSkipped)
+}
+
+func TestNilValidatorWithoutDecodeOnly(t *testing.T) {
+	cfg := setupParser()
+	cfg.Validator = nil
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","email":"john@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+	assert.False(t, cfg.Result.ValidationSkipped)
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type SignupSchemaForm struct {
+	Email    string    `json:"email" validate:"required,email"`
+	Username string    `json:"username" validate:"required,alphanum,min=3,max=20"`
+	Age      int       `json:"age" validate:"gte=18,lt=130"`
+	Plan     string    `json:"plan" validate:"oneof=free pro"`
+	Tags     []string  `json:"tags" validate:"max=5,dive,min=2"`
+	Born     time.Time `json:"born"`
+	Referrer *struct {
+		Code string `json:"code" validate:"required,len=8"`
+	} `json:"referrer"`
+	Password string `json:"password" validate:"required"`
+	Confirm  string `json:"confirm" validate:"eqfield=Password"`
+}
+
+func TestSchema(t *testing.T) {
+	s := formparser.Schema(&SignupSchemaForm{})
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", s.Schema)
+	assert.Equal(t, "SignupSchemaForm", s.Title)
+	assert.Equal(t, []string{"email", "username", "password"}, s.Required)
+
+	assert.Equal(t, "email", s.Properties["email"].Format)
+	username := s.Properties["username"]
+	assert.Equal(t, `^[a-zA-Z0-9]+$`, username.Pattern)
+	assert.Equal(t, 3, *username.MinLength)
+	assert.Equal(t, 20, *username.MaxLength)
+
+	age := s.Properties["age"]
+	assert.Equal(t, "integer", age.Type)
+	assert.Equal(t, 18.0, *age.Minimum)
+	assert.Equal(t, 130.0, *age.ExclusiveMaximum)
+	assert.Equal(t, []any{"free", "pro"}, s.Properties["plan"].Enum)
+
+	tags := s.Properties["tags"]
+	assert.Equal(t, 5, *tags.MaxItems)
+	assert.Equal(t, 2, *tags.Items.MinLength)
+	assert.Equal(t, "date-time", s.Properties["born"].Format)
+
+	referrer := s.Properties["referrer"]
+	assert.Equal(t, []string{"code"}, referrer.Required)
+	assert.Equal(t, 8, *referrer.Properties["code"].MaxLength)
+
+	assert.Equal(t, []formparser.FieldDependency{
+		{Field: "confirm", On: "password", Rule: "eqfield", Effect: formparser.EffectCompare},
+	}, s.Dependencies)
+}
+
+func TestSchemaHandler(t *testing.T) {
+	h := formparser.SchemaHandler(&SignupSchemaForm{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schemas/signup", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/schema+json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Contains(t, body["properties"], "email")
+
+	req := httptest.NewRequest(http.MethodGet, "/schemas/signup", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/schemas/signup", nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/schemas/signup", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	other := httptest.NewRecorder()
+	formparser.SchemaHandler(&TestForm{}).ServeHTTP(other, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NotEqual(t, etag, other.Header().Get("ETag"))
+}
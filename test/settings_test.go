@@ -0,0 +1,91 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSize(t *testing.T) {
+	for input, want := range map[string]int64{
+		"512":    512,
+		"64KB":   64 << 10,
+		"5 mb":   5 << 20,
+		"1.5MB":  3 << 19,
+		"2GiB":   2 << 30,
+		"100B":   100,
+		" 10k  ": 10 << 10,
+	} {
+		got, err := formparser.ParseSize(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+	for _, input := range []string{"", "MB", "-1KB", "ten", "NaN", "1e30GB"} {
+		_, err := formparser.ParseSize(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("FORMPARSER_MAX_FILE_SIZE", "10MB")
+	t.Setenv("FORMPARSER_ALLOWED_MIME_TYPES", "image/png, application/pdf")
+	t.Setenv("FORMPARSER_PART_IDLE_TIMEOUT", "30s")
+	t.Setenv("FORMPARSER_URL_ENCODING", "strict")
+	t.Setenv("FORMPARSER_MAX_DECODE_DEPTH", "16")
+	t.Setenv("FORMPARSER_EMPTY_FILE_REQUIRED", "true")
+
+	cfg, err := formparser.ConfigFromEnv()
+	assert.NoError(t, err)
+	assert.NotNil(t, cfg.Decoder)
+	assert.NotNil(t, cfg.Validator)
+	assert.Equal(t, int64(10<<20), cfg.MaxFileSize)
+	assert.Equal(t, []string{"image/png", "application/pdf"}, cfg.AllowedMIMETypes)
+	assert.Equal(t, 30*time.Second, cfg.PartIdleTimeout)
+	assert.Equal(t, formparser.URLEncodingStrict, cfg.URLEncoding)
+	assert.Equal(t, 16, cfg.MaxDecodeDepth)
+	assert.True(t, cfg.EmptyFileRequired)
+
+	t.Setenv("FORMPARSER_MAX_FILE_SIZE", "lots")
+	_, err = formparser.ConfigFromEnv()
+	assert.ErrorContains(t, err, "FORMPARSER_MAX_FILE_SIZE")
+}
+
+func TestConfigFromFile(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "formparser.json")
+	assert.NoError(t, os.WriteFile(jsonPath, []byte(`{
+		"max_file_size": "2MB",
+		"error_gzip_threshold": 1024,
+		"tag_mode": "prefer_json",
+		"field_error_messages": {"name": "Name is required"}
+	}`), 0o600))
+
+	cfg, err := formparser.ConfigFromFile(jsonPath)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2<<20), cfg.MaxFileSize)
+	assert.Equal(t, 1024, cfg.ErrorGzipThreshold)
+	assert.Equal(t, formparser.TagModePreferJSON, cfg.TagMode)
+	assert.Equal(t, "Name is required", cfg.FieldErrorMessages["name"])
+
+	yamlPath := filepath.Join(dir, "formparser.yaml")
+	assert.NoError(t, os.WriteFile(yamlPath, []byte("max_file_size: 512KB\nmax_text_body_size: 4096\nallowed_mime_types: [image/png]\nmessage_templates:\n  required: \"{field} is missing\"\n"), 0o600))
+
+	cfg, err = formparser.ConfigFromFile(yamlPath)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(512<<10), cfg.MaxFileSize)
+	assert.Equal(t, int64(4096), cfg.MaxTextBodySize)
+	assert.Equal(t, []string{"image/png"}, cfg.AllowedMIMETypes)
+	assert.Equal(t, "{field} is missing", cfg.MessageTemplates["required"])
+
+	badPath := filepath.Join(dir, "bad.yml")
+	assert.NoError(t, os.WriteFile(badPath, []byte("tag_mode: loose\n"), 0o600))
+	_, err = formparser.ConfigFromFile(badPath)
+	assert.ErrorContains(t, err, "tag_mode")
+
+	_, err = formparser.ConfigFromFile(filepath.Join(dir, "formparser.ini"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TaggedForm struct {
+	Title string   `form:"title" validate:"required"`
+	Tags  []string `form:"tags" split:"," validate:"max=3"`
+}
+
+func TestSplitTag(t *testing.T) {
+	want := TaggedForm{Title: "Post", Tags: []string{"a", "b", "c"}}
+
+	t.Run("query", func(t *testing.T) {
+		cfg := setupParser()
+		req := httptest.NewRequest(http.MethodGet, "/?title=Post&tags="+url.QueryEscape(" a, b,,c "), nil)
+
+		var form TaggedForm
+		assert.NoError(t, cfg.ParseQuery(httptest.NewRecorder(), req, &form))
+		assert.Equal(t, want, form)
+	})
+
+	t.Run("urlencoded", func(t *testing.T) {
+		cfg := setupParser()
+		data := url.Values{"title": {"Post"}, "tags": {"a,b", "c,"}}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(data.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var form TaggedForm
+		assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+		assert.Equal(t, want, form)
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		cfg := setupParser()
+		req := newMultipartRequest(t, map[string]string{"title": "Post", "tags": "a , b , c"})
+
+		var form TaggedForm
+		assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+		assert.Equal(t, want, form)
+	})
+}
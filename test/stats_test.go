@@ -0,0 +1,69 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	cfg := setupParser()
+	cfg.FileStore = &memoryStore{files: map[string]*formparser.UploadedFile{}}
+
+	payload := `{"name":"John","email":"john@example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	var form TestForm
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form))
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	assert.Error(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TestForm{}))
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("x"))
+	req.Header.Set("Content-Type", "text/html")
+	assert.Error(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TestForm{}))
+
+	req = newMultipartRequest(t, map[string]string{"name": "John", "email": "john@example.com"},
+		testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png", Content: []byte("PNG")})
+	multipartLen := req.ContentLength
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TestForm{}))
+
+	req = httptest.NewRequest(http.MethodGet, "/?name=John", nil)
+	assert.Error(t, cfg.ParseQuery(httptest.NewRecorder(), req, &TestForm{}))
+
+	stats := cfg.Stats()
+	assert.Equal(t, map[string]int64{"json": 2, "xml": 0, "yaml": 0, "msgpack": 0, "toml": 0, "cbor": 0, "protobuf": 0, "ndjson": 0, "csv": 0, "octet-stream": 0, "text": 0, "urlencoded": 0, "multipart": 1, "email": 0, "query": 1, "custom": 0, "unsupported": 1}, stats.Parses)
+	assert.Equal(t, int64(2), stats.Failures["validation"])
+	assert.Equal(t, int64(1), stats.Failures["unsupported_type"])
+	assert.Equal(t, int64(1), stats.FilesStored)
+	assert.Equal(t, int64(len(payload)+len(`{}`))+multipartLen, stats.BytesRead)
+}
+
+func TestStatsConcurrent(t *testing.T) {
+	cfg := setupParser()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","email":"john@example.com"}`))
+			req.Header.Set("Content-Type", "application/json")
+			var form TestForm
+			job, err := cfg.ParseAsync(httptest.NewRecorder(), req, &form)
+			if err == nil {
+				_, _ = job.Wait()
+			}
+			_ = cfg.Stats()
+		}()
+	}
+	wg.Wait()
+
+	stats := cfg.Stats()
+	assert.Equal(t, int64(20), stats.Parses["json"]+stats.Failures["queue_full"])
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStream(t *testing.T) {
+	cfg := setupParser()
+	header := http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+	var form TestForm
+	out, err := cfg.ParseStream(context.Background(), header, strings.NewReader("name=Ann&email=ann@example.com"), &form)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, out.Status)
+	assert.Equal(t, "ann@example.com", form.Email)
+	assert.Equal(t, "application/x-www-form-urlencoded", out.Result.MediaType)
+	assert.Equal(t, int64(1), cfg.Stats().Parses["urlencoded"])
+
+	out, err = cfg.ParseStream(context.Background(), http.Header{"Content-Type": {"application/json"}}, strings.NewReader(`{"name":"Ann"}`), &TestForm{})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, out.Status)
+	assert.Contains(t, string(out.Body), "email")
+
+	out, err = cfg.ParseStream(context.Background(), http.Header{"Content-Type": {"application/json"}, "Content-Encoding": {"compress"}}, strings.NewReader(`{}`), &TestForm{})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, out.Status)
+	assert.NotEmpty(t, out.Header.Get("Accept-Encoding"))
+}
+
+func TestParseRawRequest(t *testing.T) {
+	stream := "POST /signup HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"Content-Type: application/json\r\n" +
+		"Transfer-Encoding: chunked\r\n" +
+		"\r\n" +
+		"e\r\n{\"name\":\"Ann\",\r\n" +
+		"1b\r\n\"email\":\"ann@example.com\"} \r\n" +
+		"0\r\n\r\n" +
+		"POST /signup HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"Content-Type: application/json\r\n" +
+		"Content-Length: 40\r\n" +
+		"\r\n" +
+		`{"name":"Bo","email":"not-an-email"}    ` +
+		"GET /next HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	br := bufio.NewReader(strings.NewReader(stream))
+	cfg := setupParser()
+
+	var first TestForm
+	out, err := cfg.ParseRawRequest(context.Background(), br, &first)
+	assert.NoError(t, err)
+	assert.Equal(t, "/signup", out.Request.URL.Path)
+	assert.Equal(t, TestForm{Name: "Ann", Email: "ann@example.com"}, first)
+
+	out, err = cfg.ParseRawRequest(context.Background(), br, &TestForm{})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, out.Status)
+
+	next, err := http.ReadRequest(br)
+	assert.NoError(t, err)
+	assert.Equal(t, "/next", next.URL.Path)
+}
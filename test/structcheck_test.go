@@ -0,0 +1,59 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type SloppyForm struct {
+	Email    string `json:"email" form:"email" valdiate:"required,email"`
+	Name     string `json:"name" form:"full_name" validate:"required,alpah"`
+	Phone    string `from:"phone"`
+	secret   string `form:"secret"`
+	Resume   string `form:"resume" file_required_if:"Applyng true"`
+	Applying bool   `form:"applying"`
+	Token    string `form:"token" state:"offer"`
+	UserID   string `ctx:"user_id"`
+	Address  struct {
+		Zip string `form:"zip" validate:"required,postcodez"`
+	} `form:"address"`
+}
+
+func TestCheckStruct(t *testing.T) {
+	cfg := setupParser()
+	cfg.AllowedMIMETypes = nil
+	findings := formparser.CheckStruct(&SloppyForm{}, cfg)
+
+	got := map[string][]string{}
+	for _, f := range findings {
+		got[f.Kind] = append(got[f.Kind], f.Field+" "+f.Tag)
+	}
+	assert.Equal(t, map[string][]string{
+		formparser.FindingUnknownTag:    {"Email valdiate", "Phone from"},
+		formparser.FindingUnknownRule:   {"Name validate", "Address.Zip validate"},
+		formparser.FindingTagMismatch:   {"Name form"},
+		formparser.FindingUnexportedTag: {"secret form"},
+		formparser.FindingInvalidTag:    {"Resume file_required_if"},
+		formparser.FindingMissingConfig: {"Resume file_required_if", "Token state", "UserID ctx"},
+	}, got)
+	assert.Equal(t, `Email: unknown tag "valdiate", did you mean "validate"?`, findings[0].String())
+}
+
+func TestCheckStructClean(t *testing.T) {
+	assert.Empty(t, formparser.CheckStruct(&TestForm{}, setupParser()))
+	assert.Empty(t, formparser.CheckStruct(&TestForm{}, nil))
+	assert.Nil(t, formparser.CheckStruct("not a struct", nil))
+}
+
+func TestCheckStructCustomValidation(t *testing.T) {
+	type PostcodeForm struct {
+		Zip string `validate:"postcodez"`
+	}
+	cfg := setupParser()
+	assert.Len(t, formparser.CheckStruct(&PostcodeForm{}, cfg), 1)
+	assert.NoError(t, cfg.RegisterValidation("postcodez", func(fl validator.FieldLevel) bool { return true }))
+	assert.Empty(t, formparser.CheckStruct(&PostcodeForm{}, cfg))
+}
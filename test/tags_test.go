@@ -0,0 +1,69 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type MismatchedForm struct {
+	Name  string `form:"full_name" json:"name" validate:"required"`
+	Email string `form:"email" json:"email" validate:"required,email"`
+}
+
+func TestTagMismatches(t *testing.T) {
+	mismatches := formparser.TagMismatches(&MismatchedForm{})
+
+	assert.Equal(t, []formparser.TagMismatch{{Field: "Name", JSON: "name", Form: "full_name"}}, mismatches)
+	assert.Empty(t, formparser.TagMismatches(&TestForm{}))
+}
+
+func TestPrecompileStrictTagMode(t *testing.T) {
+	cfg := setupParser()
+	assert.NoError(t, cfg.Precompile(&MismatchedForm{}))
+
+	cfg.TagMode = formparser.TagModeStrict
+	err := cfg.Precompile(&MismatchedForm{}, &TestForm{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "full_name")
+}
+
+func TestTagModePreferJSON(t *testing.T) {
+	cfg := setupParser()
+	cfg.TagMode = formparser.TagModePreferJSON
+
+	data := url.Values{}
+	data.Set("name", "Jane")
+	data.Set("email", "jane@example.com")
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	var form MismatchedForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Jane", form.Name)
+}
+
+func TestTagModePreferForm(t *testing.T) {
+	cfg := setupParser()
+	cfg.TagMode = formparser.TagModePreferForm
+
+	payload := `{"full_name":"John","email":"john@example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	var form MismatchedForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "John", form.Name)
+}
@@ -0,0 +1,37 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type UploadLimitsForm struct {
+	Size  int    `json:"size" validate:"lt=5" unit:"MB"`
+	Title string `json:"title" validate:"required"`
+	Code  string `json:"code" validate:"alpha"`
+}
+
+func TestMessageTemplates(t *testing.T) {
+	cfg := setupParser()
+	cfg.MessageTemplates = map[string]string{"alpha": "{field} may only contain letters"}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"size":7,"code":"a1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	var form UploadLimitsForm
+	_ = cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	var response validationResponse
+	_ = json.NewDecoder(w.Body).Decode(&response)
+	assert.Equal(t, map[string]string{
+		"size":  "size must be smaller than 5 MB",
+		"title": "title is required",
+		"code":  "code may only contain letters",
+	}, response.Fields)
+}
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTextParser() *formparser.Config {
+	cfg := setupParser()
+	cfg.AllowedMIMETypes = []string{"text/csv", "text/csv; charset=shift_jis"}
+	cfg.TextRules = map[string]formparser.TextRule{"document": {}}
+	return cfg
+}
+
+func TestTextRuleNormalizesCharset(t *testing.T) {
+	tests := map[string]struct {
+		contentType string
+		content     []byte
+		charset     string
+		want        string
+	}{
+		"utf-8 bom":      {"text/csv", []byte("\xEF\xBB\xBFname,city\nJosé,Zürich\n"), "utf-8", "name,city\nJosé,Zürich\n"},
+		"utf-16le bom":   {"text/csv", []byte("\xFF\xFEn\x00,\x00\xE9\x00\n\x00"), "utf-16le", "n,é\n"},
+		"declared":       {"text/csv; charset=shift_jis", []byte("name\n\x93\xfa\x96\x7b\n"), "shift_jis", "name\n日本\n"},
+		"latin fallback": {"text/csv", []byte("name,city\nJos\xE9,Z\xFCrich\n"), "windows-1252", "name,city\nJosé,Zürich\n"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := setupTextParser()
+			req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+				testFile{Field: "document", Filename: "people.csv", ContentType: tt.contentType, Content: tt.content})
+
+			var form TestForm
+			err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form)
+
+			assert.NoError(t, err)
+			file := cfg.Files["document"]
+			assert.Equal(t, tt.want, string(file.Content))
+			assert.Equal(t, tt.charset, file.Charset)
+			assert.Equal(t, tt.content, file.Original.Content)
+		})
+	}
+}
+
+func TestTextRuleKeepsUTF8(t *testing.T) {
+	cfg := setupTextParser()
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "document", Filename: "people.csv", ContentType: "text/csv", Content: []byte("name\nJosé\n")})
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form)
+
+	assert.NoError(t, err)
+	assert.Nil(t, cfg.Files["document"].Original)
+	assert.Equal(t, "utf-8", cfg.Files["document"].Charset)
+}
+
+func TestTextRuleUnsupportedCharset(t *testing.T) {
+	cfg := setupTextParser()
+	cfg.TextRules["document"] = formparser.TextRule{Fallback: "klingon"}
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "document", Filename: "people.csv", ContentType: "text/csv", Content: []byte("Jos\xE9")})
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.IsType(t, formparser.FieldErrors{}, err)
+	assert.Contains(t, w.Body.String(), "document has an unsupported charset")
+}
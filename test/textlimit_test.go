@@ -0,0 +1,52 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type ReviewForm struct {
+	Title   string  `form:"title" maxbytes:"10"`
+	Body    string  `form:"body" maxbytes:"5,runes,truncate"`
+	Summary *string `form:"summary" maxbytes:"4,truncate"`
+}
+
+func postReview(t *testing.T, cfg *formparser.Config, values url.Values) (*ReviewForm, *httptest.ResponseRecorder, error) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	var form ReviewForm
+	err := cfg.ParseFormBasedOnContentType(rec, req, &form)
+	return &form, rec, err
+}
+
+func TestMaxBytesTruncate(t *testing.T) {
+	cfg := setupParser()
+	form, _, err := postReview(t, cfg, url.Values{"title": {"Great"}, "body": {"héllo wörld"}, "summary": {"cafés"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "héllo", form.Body)
+	assert.Equal(t, "caf", *form.Summary) // never cut inside "é"
+	assert.Equal(t, "body was truncated to 5 characters", cfg.Result.Warnings["body"])
+	assert.Equal(t, "summary was truncated to 4 bytes", cfg.Result.Warnings["summary"])
+}
+
+func TestMaxBytesReject(t *testing.T) {
+	cfg := setupParser()
+	_, rec, err := postReview(t, cfg, url.Values{"title": {"Far too long a title"}})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "title must be at most 10 bytes")
+
+	cfg.TextLimitPolicy = formparser.TextLimitTruncate
+	form, _, err := postReview(t, cfg, url.Values{"title": {"Far too long a title"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Far too lo", form.Title)
+	assert.Equal(t, "truncate", cfg.Effective().TextLimitPolicy)
+}
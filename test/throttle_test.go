@@ -0,0 +1,28 @@
+package test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxUploadBytesPerSecond(t *testing.T) {
+	cfg := setupParser()
+	cfg.MaxUploadBytesPerSecond = 40 << 10 // 40KB/s
+	content := bytes.Repeat([]byte("x"), 16<<10)
+	req := newMultipartRequest(t, map[string]string{"name": "John", "email": "john@example.com"},
+		testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png", Content: content})
+
+	start := time.Now()
+	assert.NoError(t, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TestForm{}))
+	elapsed := time.Since(start)
+
+	// 16KB at 40KB/s, less the initial 4KB burst, takes at least 0.3s.
+	assert.GreaterOrEqual(t, elapsed, 300*time.Millisecond)
+	assert.Less(t, elapsed, 2*time.Second)
+	assert.Equal(t, content, cfg.Files["avatar"].Content)
+	assert.Equal(t, int64(40<<10), cfg.Effective().MaxUploadBytesPerSecond)
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryStore struct {
+	files map[string]*formparser.UploadedFile
+}
+
+func (s *memoryStore) Store(ctx context.Context, key string, file *formparser.UploadedFile) error {
+	s.files[key] = file
+	return nil
+}
+
+func TestThumbnails(t *testing.T) {
+	store := &memoryStore{files: map[string]*formparser.UploadedFile{}}
+	cfg := setupParser()
+	cfg.FileStore = store
+	cfg.Thumbnails = map[string][]formparser.ThumbnailSize{
+		"avatar": {{Width: 50, Height: 50}, {Width: 10, Height: 20}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 200, 100))))
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "avatar", Filename: "avatar.png", ContentType: "image/png", Content: buf.Bytes()})
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	assert.NoError(t, err)
+
+	file := cfg.Files["avatar"]
+	assert.Len(t, file.Variants, 2)
+	assert.Equal(t, "avatar_50x50.png", file.Variants[0].Filename)
+
+	thumb, _, err := image.Decode(bytes.NewReader(file.Variants[0].Content))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 50, 25), thumb.Bounds())
+
+	assert.Len(t, store.files, 3)
+	assert.Equal(t, file.Variants[1], store.files[file.Variants[1].StorageKey])
+}
+
+func TestThumbnailsMaxImagePixels(t *testing.T) {
+	cfg := setupParser()
+	cfg.MaxImagePixels = 10_000
+	cfg.Thumbnails = map[string][]formparser.ThumbnailSize{"avatar": {{Width: 50, Height: 50}}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 200, 100))))
+	req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com"},
+		testFile{Field: "avatar", Filename: "avatar.png", ContentType: "image/png", Content: buf.Bytes()})
+	w := httptest.NewRecorder()
+
+	assert.Error(t, cfg.ParseFormBasedOnContentType(w, req, &TestForm{}))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "avatar has too many pixels (max 10000)")
+	assert.Equal(t, 10_000, cfg.Effective().MaxImagePixels)
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+// trickleReader returns data one byte at a time, sleeping before each byte
+// and stalling forever once stallAt bytes have been read (-1 = never).
+type trickleReader struct {
+	data    []byte
+	delay   time.Duration
+	stallAt int
+	read    int
+	stop    chan struct{}
+}
+
+func (r *trickleReader) Read(p []byte) (int, error) {
+	if r.read == len(r.data) {
+		return 0, io.EOF
+	}
+	if r.read == r.stallAt {
+		<-r.stop
+		return 0, io.ErrUnexpectedEOF
+	}
+	time.Sleep(r.delay)
+	p[0] = r.data[r.read]
+	r.read++
+	return 1, nil
+}
+
+func slowMultipartRequest(t *testing.T, delay time.Duration, stallAt int) (*http.Request, func()) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	assert.NoError(t, writer.WriteField("name", "Alice"))
+	assert.NoError(t, writer.WriteField("email", "alice@example.com"))
+	assert.NoError(t, writer.Close())
+
+	stop := make(chan struct{})
+	req := httptest.NewRequest(http.MethodPost, "/", &trickleReader{data: body.Bytes(), delay: delay, stallAt: stallAt, stop: stop})
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req, func() { close(stop) }
+}
+
+func TestPartIdleTimeout(t *testing.T) {
+	cfg := setupParser()
+	cfg.PartIdleTimeout = 50 * time.Millisecond
+	req, release := slowMultipartRequest(t, 0, 80)
+	defer release()
+	w := httptest.NewRecorder()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.True(t, errors.Is(err, formparser.ErrReadTimeout))
+	assert.Equal(t, http.StatusRequestTimeout, w.Code)
+	assert.Equal(t, "close", w.Header().Get("Connection"))
+}
+
+func TestMinReadRate(t *testing.T) {
+	cfg := setupParser()
+	cfg.MinReadRate = 1000
+	req, release := slowMultipartRequest(t, 20*time.Millisecond, -1)
+	defer release()
+	w := httptest.NewRecorder()
+
+	start := time.Now()
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.True(t, errors.Is(err, formparser.ErrReadTimeout))
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Equal(t, http.StatusRequestTimeout, w.Code)
+}
+
+func TestReadLimitsAllowSteadyBody(t *testing.T) {
+	cfg := setupParser()
+	cfg.PartIdleTimeout = time.Second
+	cfg.MinReadRate = 10
+	req, release := slowMultipartRequest(t, 0, -1)
+	defer release()
+
+	var form TestForm
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &form)
+
+	assert.NoError(t, err)
+	assert.Equal(t, TestForm{Name: "Alice", Email: "alice@example.com"}, form)
+}
+
+func TestReadLimitsApplyToCompressedBody(t *testing.T) {
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	_, _ = zw.Write([]byte(`{"name":"Alice","email":"alice@example.com"}`))
+	assert.NoError(t, zw.Close())
+
+	cfg := setupParser()
+	cfg.PartIdleTimeout = 50 * time.Millisecond
+	stop := make(chan struct{})
+	defer close(stop)
+	req := httptest.NewRequest(http.MethodPost, "/", &trickleReader{data: body.Bytes(), stallAt: 20, stop: stop})
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	err := cfg.ParseFormBasedOnContentType(w, req, &TestForm{})
+	assert.True(t, errors.Is(err, formparser.ErrReadTimeout))
+	assert.Equal(t, http.StatusRequestTimeout, w.Code)
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ServiceConfig struct {
+	Name    string   `toml:"name" validate:"required"`
+	Port    int      `toml:"port" validate:"gte=1,lte=65535"`
+	Regions []string `toml:"regions" validate:"min=1"`
+}
+
+func tomlRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/toml")
+	return req
+}
+
+func TestParseTOML(t *testing.T) {
+	cfg := setupParser()
+	body := "name = \"billing\"\nport = 8080\nregions = [\"eu-west-1\", \"us-east-1\"]\n"
+
+	var svc ServiceConfig
+	err := cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), tomlRequest(body), &svc)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ServiceConfig{Name: "billing", Port: 8080, Regions: []string{"eu-west-1", "us-east-1"}}, svc)
+}
+
+func TestParseTOMLValidation(t *testing.T) {
+	cfg := setupParser()
+	w := httptest.NewRecorder()
+
+	var svc ServiceConfig
+	err := cfg.ParseFormBasedOnContentType(w, tomlRequest("port = 70000\nregions = [\"eu-west-1\"]\n"), &svc)
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "Name is required")
+	assert.Contains(t, w.Body.String(), "port must be at most 65535")
+}
+
+func TestParseTOMLInvalid(t *testing.T) {
+	cfg := setupParser()
+	w := httptest.NewRecorder()
+
+	var svc ServiceConfig
+	err := cfg.ParseFormBasedOnContentType(w, tomlRequest("name = "), &svc)
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "Invalid TOML body")
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+// trailerBody sets the request trailers once the body has been fully read,
+// mimicking how net/http exposes trailers of chunked requests.
+type trailerBody struct {
+	io.Reader
+	req     *http.Request
+	trailer http.Header
+}
+
+func (b *trailerBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err == io.EOF {
+		b.req.Trailer = b.trailer
+	}
+	return n, err
+}
+
+func (b *trailerBody) Close() error { return nil }
+
+func newTrailerRequest(payload string, trailer http.Header) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Body = &trailerBody{Reader: strings.NewReader(payload), req: req, trailer: trailer}
+	return req
+}
+
+func TestTrailerChecksum(t *testing.T) {
+	payload := `{"name":"John","email":"john@example.com"}`
+	sum := sha256.Sum256([]byte(payload))
+
+	tests := []struct {
+		name    string
+		trailer http.Header
+		wantErr bool
+	}{
+		{"content digest", http.Header{"Content-Digest": {"sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"}}, false},
+		{"hex", http.Header{"X-Content-Sha256": {fmt.Sprintf("%x", sum)}}, false},
+		{"absent", http.Header{}, false},
+		{"unsupported algorithm", http.Header{"Content-Digest": {"sha-512=:AAAA:"}}, false},
+		{"mismatch", http.Header{"X-Content-Sha256": {strings.Repeat("0", 64)}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := setupParser()
+			cfg.VerifyTrailerChecksum = true
+			w := httptest.NewRecorder()
+
+			var form TestForm
+			err := cfg.ParseFormBasedOnContentType(w, newTrailerRequest(payload, tt.trailer), &form)
+
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var mismatch *formparser.ChecksumMismatchError
+			assert.ErrorAs(t, err, &mismatch)
+			assert.Equal(t, fmt.Sprintf("%x", sum), mismatch.Actual)
+			assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestTrailerChecksumMismatchStoresNothing(t *testing.T) {
+	store := &memoryStore{files: map[string]*formparser.UploadedFile{}}
+	upload := func(trailer http.Header) (*formparser.Config, error) {
+		cfg := setupParser()
+		cfg.VerifyTrailerChecksum = true
+		cfg.FileStore = store
+		req := newMultipartRequest(t, map[string]string{"name": "John", "email": "john@example.com"},
+			testFile{Field: "avatar", Filename: "a.png", ContentType: "image/png", Content: []byte("PNG")})
+		body, _ := io.ReadAll(req.Body)
+		req.Body = &trailerBody{Reader: bytes.NewReader(body), req: req, trailer: trailer}
+		return cfg, cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), req, &TestForm{})
+	}
+
+	_, err := upload(http.Header{"X-Content-Sha256": {strings.Repeat("0", 64)}})
+	assert.Error(t, err)
+	assert.Empty(t, store.files)
+
+	cfg, err := upload(http.Header{})
+	assert.NoError(t, err)
+	assert.Len(t, store.files, 1)
+	assert.NotEmpty(t, cfg.Files["avatar"].StorageKey)
+}
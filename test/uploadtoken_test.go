@@ -0,0 +1,92 @@
+package test
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTokenParser() *formparser.Config {
+	cfg := setupParser()
+	cfg.UploadTokenSecret = []byte("test-secret")
+	cfg.UploadTokenFields = map[string]string{"avatar": "avatar_token"}
+	return cfg
+}
+
+func TestUploadToken(t *testing.T) {
+	content := []byte("PNG IMAGE CONTENT")
+	grant := formparser.UploadGrant{
+		Field: "avatar", Filename: "avatar.png", Size: int64(len(content)),
+		ContentType: "image/png", Expires: time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name    string
+		token   func(cfg *formparser.Config) string
+		content []byte
+		wantErr bool
+	}{
+		{"valid", func(cfg *formparser.Config) string { tok, _ := cfg.SignUploadToken(grant); return tok }, content, false},
+		{"missing", func(cfg *formparser.Config) string { return "" }, content, true},
+		{"tampered", func(cfg *formparser.Config) string { tok, _ := cfg.SignUploadToken(grant); return "x" + tok }, content, true},
+		{"wrong size", func(cfg *formparser.Config) string { tok, _ := cfg.SignUploadToken(grant); return tok }, append(content, '!'), true},
+		{"expired", func(cfg *formparser.Config) string {
+			expired := grant
+			expired.Expires = time.Now().Add(-time.Minute)
+			tok, _ := cfg.SignUploadToken(expired)
+			return tok
+		}, content, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := setupTokenParser()
+			req := newMultipartRequest(t, map[string]string{"name": "Alice", "email": "alice@example.com", "avatar_token": tt.token(cfg)},
+				testFile{Field: "avatar", Filename: "avatar.png", ContentType: "image/png", Content: tt.content})
+			w := httptest.NewRecorder()
+
+			var form TestForm
+			err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+			if tt.wantErr {
+				assert.IsType(t, formparser.FieldErrors{}, err)
+				assert.Nil(t, cfg.Files["avatar"])
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, content, cfg.Files["avatar"].Content)
+		})
+	}
+}
+
+type TokenAvatarForm struct {
+	Avatar      *formparser.UploadedFile `json:"avatar"`
+	AvatarToken string                   `json:"avatar_token"`
+}
+
+func TestUploadTokenDataURI(t *testing.T) {
+	content := []byte("PNG IMAGE CONTENT")
+	cfg := setupTokenParser()
+	token, err := cfg.SignUploadToken(formparser.UploadGrant{
+		Field: "avatar", Filename: "avatar.png", Size: int64(len(content)),
+		ContentType: "image/png", Expires: time.Now().Add(time.Hour),
+	})
+	assert.NoError(t, err)
+	uri := "data:image/png;name=avatar.png;base64," + base64.StdEncoding.EncodeToString(content)
+
+	// Without a token the JSON upload is refused like a multipart one.
+	w := httptest.NewRecorder()
+	err = cfg.ParseFormBasedOnContentType(w, newJSONRequest(`{"avatar":"`+uri+`"}`), &TokenAvatarForm{})
+	assert.IsType(t, formparser.FieldErrors{}, err)
+	assert.Nil(t, cfg.Files["avatar"])
+
+	var form TokenAvatarForm
+	err = cfg.ParseFormBasedOnContentType(httptest.NewRecorder(), newJSONRequest(`{"avatar":"`+uri+`","avatar_token":"`+token+`"}`), &form)
+	assert.NoError(t, err)
+	if assert.NotNil(t, form.Avatar) {
+		assert.Equal(t, content, form.Avatar.Content)
+	}
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type SearchForm struct {
+	Name  string   `form:"name" validate:"required"`
+	Email string   `form:"email"`
+	Tags  []string `form:"tag"`
+}
+
+func postURLEncoded(cfg *formparser.Config, body string) (*httptest.ResponseRecorder, SearchForm, error) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	var form SearchForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+	return w, form, err
+}
+
+func TestURLEncodingDefaultRejectsSemicolons(t *testing.T) {
+	cfg := setupParser()
+	w, _, err := postURLEncoded(cfg, "name=John;tag=a")
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+}
+
+func TestURLEncodingStrict(t *testing.T) {
+	cfg := setupParser()
+	cfg.URLEncoding = formparser.URLEncodingStrict
+
+	_, form, err := postURLEncoded(cfg, "name=John+Doe&email=a%2Bb%40example.com&tag=&tag=x")
+	assert.NoError(t, err)
+	assert.Equal(t, SearchForm{Name: "John Doe", Email: "a+b@example.com", Tags: []string{"", "x"}}, form)
+
+	for body, reason := range map[string]string{
+		"name=John;tag=a": "semicolon separator",
+		"name=John&=x":    "empty key",
+		"name=100%&tag=a": "malformed escape in value",
+		"na%zzme=John":    "malformed escape in key",
+	} {
+		w, _, err := postURLEncoded(cfg, body)
+		var encErr *formparser.URLEncodingError
+		if assert.ErrorAs(t, err, &encErr, body) {
+			assert.Equal(t, reason, encErr.Reason)
+		}
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	}
+}
+
+func TestURLEncodingLenient(t *testing.T) {
+	var logs bytes.Buffer
+	cfg := setupParser()
+	cfg.URLEncoding = formparser.URLEncodingLenient
+	cfg.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	_, form, err := postURLEncoded(cfg, "name=John+Doe;tag=100%&=dropped&&tag=b")
+	assert.NoError(t, err)
+	assert.Equal(t, SearchForm{Name: "John Doe", Tags: []string{"100%", "b"}}, form)
+
+	out := logs.String()
+	assert.Contains(t, out, "treated semicolon as separator")
+	assert.Contains(t, out, "kept malformed escape literally")
+	assert.Contains(t, out, "dropped pair with empty key")
+	assert.NotContains(t, out, "100%")
+	assert.NotContains(t, out, "=dropped")
+}
+
+func TestURLEncodingQuery(t *testing.T) {
+	cfg := setupParser()
+	cfg.URLEncoding = formparser.URLEncodingLenient
+	cfg.Logger = slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/?name=John;tag=a;tag=b", nil)
+	var form SearchForm
+	assert.NoError(t, cfg.ParseQuery(httptest.NewRecorder(), req, &form))
+	assert.Equal(t, []string{"a", "b"}, form.Tags)
+
+	cfg.URLEncoding = formparser.URLEncodingStrict
+	w := httptest.NewRecorder()
+	assert.Error(t, cfg.ParseQuery(w, req, &SearchForm{}))
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jinn091/go-form-parser/formparser"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ProfileUpdate mirrors a protoc-gen-go message with wrapper fields.
+type ProfileUpdate struct {
+	Nickname  *wrapperspb.StringValue `form:"nickname" validate:"omitnil,max=10"`
+	Age       *wrapperspb.Int32Value  `form:"age" validate:"omitnil,gte=13"`
+	Score     *wrapperspb.DoubleValue `form:"score"`
+	Verified  *wrapperspb.BoolValue   `form:"verified"`
+	Avatar    *wrapperspb.BytesValue  `form:"avatar"`
+	Birthday  *timestamppb.Timestamp  `form:"birthday"`
+	Bio       *wrapperspb.StringValue `form:"bio"`
+	Followers *wrapperspb.UInt64Value `form:"followers"`
+}
+
+func parseProfile(t *testing.T, body string) (*ProfileUpdate, *httptest.ResponseRecorder, error) {
+	t.Helper()
+	cfg := setupParser()
+	formparser.RegisterProtoValidators(cfg.Validator)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	var p ProfileUpdate
+	err := cfg.ParseFormBasedOnContentType(rec, req, &p)
+	return &p, rec, err
+}
+
+func TestWrapperValues(t *testing.T) {
+	p, _, err := parseProfile(t, "nickname=jo&age=30&score=4.5&verified=true&avatar=UE5H&birthday=1990-05-01T00:00:00Z&bio=&followers=")
+	assert.NoError(t, err)
+	assert.Equal(t, "jo", p.Nickname.GetValue())
+	assert.Equal(t, int32(30), p.Age.GetValue())
+	assert.Equal(t, 4.5, p.Score.GetValue())
+	assert.True(t, p.Verified.GetValue())
+	assert.Equal(t, []byte("PNG"), p.Avatar.GetValue())
+	assert.Equal(t, time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC), p.Birthday.AsTime())
+	if assert.NotNil(t, p.Bio, "submitted empty string keeps presence") {
+		assert.Equal(t, "", p.Bio.GetValue())
+	}
+	assert.Nil(t, p.Followers)
+
+	p, _, err = parseProfile(t, "nickname=jo")
+	assert.NoError(t, err)
+	assert.Nil(t, p.Age)
+	assert.Nil(t, p.Bio)
+}
+
+func TestWrapperValuesInvalid(t *testing.T) {
+	_, rec, err := parseProfile(t, "age=old&birthday=yesterday&nickname=averyverylongname")
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "age must be a 32-bit integer")
+	assert.Contains(t, body, "birthday must be an RFC 3339 timestamp")
+	assert.Contains(t, body, "nickname")
+
+	_, rec, err = parseProfile(t, "age=12")
+	assert.Error(t, err)
+	assert.Contains(t, rec.Body.String(), "age")
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type XMLForm struct {
+	Name  string `xml:"name" validate:"required"`
+	Email string `xml:"email" validate:"required,email"`
+}
+
+func TestParseXML(t *testing.T) {
+	for _, contentType := range []string{"application/xml", "text/xml; charset=utf-8", "application/vnd.user+xml"} {
+		cfg := setupParser()
+		payload := `<user><name>John</name><email>john@example.com</email></user>`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+
+		var form XMLForm
+		err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+		assert.NoError(t, err)
+		assert.Equal(t, XMLForm{Name: "John", Email: "john@example.com"}, form)
+	}
+}
+
+func TestParseXMLValidation(t *testing.T) {
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`<user><name>John</name><email>bad</email></user>`))
+	req.Header.Set("Content-Type", "application/xml")
+	w := httptest.NewRecorder()
+
+	var form XMLForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	assert.Contains(t, w.Body.String(), "Invalid email address")
+}
+
+func TestParseXMLInvalid(t *testing.T) {
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`<user><name>John</user>`))
+	req.Header.Set("Content-Type", "application/xml")
+	w := httptest.NewRecorder()
+
+	var form XMLForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "Invalid XML body")
+}
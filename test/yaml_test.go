@@ -0,0 +1,58 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ServiceConfigForm struct {
+	Name     string   `yaml:"name" validate:"required"`
+	Replicas int      `yaml:"replicas" validate:"gte=1,lte=10"`
+	Hosts    []string `yaml:"hosts" validate:"dive,fqdn"`
+}
+
+func TestParseYAML(t *testing.T) {
+	for _, contentType := range []string{"application/yaml", "application/x-yaml"} {
+		cfg := setupParser()
+		payload := "name: api\nreplicas: 3\nhosts:\n  - a.example.com\n  - b.example.com\n"
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+
+		var form ServiceConfigForm
+		err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ServiceConfigForm{Name: "api", Replicas: 3, Hosts: []string{"a.example.com", "b.example.com"}}, form)
+	}
+}
+
+func TestParseYAMLValidation(t *testing.T) {
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name: api\nreplicas: 20\n"))
+	req.Header.Set("Content-Type", "application/yaml")
+	w := httptest.NewRecorder()
+
+	var form ServiceConfigForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "replicas must be at most 10")
+}
+
+func TestParseYAMLInvalid(t *testing.T) {
+	cfg := setupParser()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name: [unclosed\n"))
+	req.Header.Set("Content-Type", "application/yaml")
+	w := httptest.NewRecorder()
+
+	var form ServiceConfigForm
+	err := cfg.ParseFormBasedOnContentType(w, req, &form)
+
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), "Invalid YAML body")
+}